@@ -0,0 +1,430 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// Reasons reported by the "iban", "bic_swift", "ca_transit" and "aba_routing" validations
+const (
+	BankAccountReasonFormat   = "format"   // Invalid characters or structure
+	BankAccountReasonLength   = "length"   // Invalid number of characters or digits
+	BankAccountReasonCountry  = "country"  // Unknown country, or a country that is not accepted
+	BankAccountReasonChecksum = "checksum" // The check digits do not match, usually a typo
+	BankAccountReasonPrefix   = "prefix"   // The routing number is not in an assigned range
+)
+
+// BankAccountError is returned by the Parse* functions of bank account numbers that are not valid
+type BankAccountError struct {
+	Constraint string // Tag of the validation, e.g. "iban"
+	Reason     string // One of the BankAccountReason* values
+	Value      string // The value as given
+}
+
+// Error implements the error interface
+func (e *BankAccountError) Error() string {
+	return fmt.Sprintf("validations: invalid %s %q: %s", e.Constraint, e.Value, e.Reason)
+}
+
+// ibanLengths is the length of the IBANs of each country of the SWIFT IBAN registry
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// countryCodes are the ISO 3166-1 alpha-2 codes, plus "XK" which SWIFT uses for Kosovo
+var countryCodes = func() map[string]bool {
+	codes := make(map[string]bool)
+	for _, code := range strings.Fields(`
+		AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV
+		BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES
+		ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE
+		IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY
+		MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU
+		NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM
+		SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE
+		VG VI VN VU WF WS YE YT ZA ZM ZW XK`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// compactAccountNumber removes the spaces of an account number and converts it to upper case
+func compactAccountNumber(value string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(value), ""))
+}
+
+// isAlphanumeric reports whether s only contains ASCII upper case letters and digits
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits reports whether s is not empty and only contains ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// bankAccountCountries returns the countries of a tag param, in upper case
+func bankAccountCountries(param string) []string {
+	countries := strings.Fields(strings.ToUpper(param))
+	for _, country := range countries {
+		if !countryCodes[country] {
+			panic(fmt.Sprintf("validations: unknown country %q", country))
+		}
+	}
+	return countries
+}
+
+// containsCountry reports whether country is accepted, an empty list accepting every country
+func containsCountry(countries []string, country string) bool {
+	if len(countries) == 0 {
+		return true
+	}
+	for _, c := range countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIBAN validates an International Bank Account Number and returns it in its electronic format,
+// without spaces and in upper case. The IBAN must have the length registered for its country and
+// valid mod-97 check digits (ISO 13616). When countries are given, IBANs of other countries are rejected.
+// Invalid IBANs return a *BankAccountError with the reason.
+func ParseIBAN(iban string, countries ...string) (string, error) {
+	countries = strings.Fields(strings.ToUpper(strings.Join(countries, " ")))
+
+	compact := compactAccountNumber(iban)
+	if reason := checkIBAN(compact, countries); reason != "" {
+		return "", &BankAccountError{Constraint: "iban", Reason: reason, Value: iban}
+	}
+	return compact, nil
+}
+
+// checkIBAN returns the reason a compact IBAN is not valid, or an empty string
+func checkIBAN(iban string, countries []string) string {
+	if len(iban) < 5 || !isAlphanumeric(iban) || !isDigits(iban[2:4]) {
+		return BankAccountReasonFormat
+	}
+
+	country := iban[:2]
+	length, ok := ibanLengths[country]
+	if !ok || !containsCountry(countries, country) {
+		return BankAccountReasonCountry
+	}
+	if len(iban) != length {
+		return BankAccountReasonLength
+	}
+
+	// Move the country and check digits to the end, replace letters with 10 to 35 and compute
+	// the remainder digit by digit, as the number is too large for an integer
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' {
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	if remainder != 1 {
+		return BankAccountReasonChecksum
+	}
+
+	return ""
+}
+
+// ParseBIC validates a Business Identifier Code, also known as SWIFT code (ISO 9362), and returns it
+// without spaces and in upper case. A BIC has 8 or 11 characters: a 4-letter institution code, the
+// country, a 2-character location code and an optional 3-character branch code. When countries are
+// given, BICs of other countries are rejected. Invalid BICs return a *BankAccountError with the reason.
+func ParseBIC(bic string, countries ...string) (string, error) {
+	countries = strings.Fields(strings.ToUpper(strings.Join(countries, " ")))
+
+	compact := compactAccountNumber(bic)
+	if reason := checkBIC(compact, countries); reason != "" {
+		return "", &BankAccountError{Constraint: "bic_swift", Reason: reason, Value: bic}
+	}
+	return compact, nil
+}
+
+// checkBIC returns the reason a compact BIC is not valid, or an empty string
+func checkBIC(bic string, countries []string) string {
+	if !isAlphanumeric(bic) {
+		return BankAccountReasonFormat
+	}
+	if len(bic) != 8 && len(bic) != 11 {
+		return BankAccountReasonLength
+	}
+	for _, r := range bic[:6] {
+		if r < 'A' {
+			return BankAccountReasonFormat
+		}
+	}
+	if country := bic[4:6]; !countryCodes[country] || !containsCountry(countries, country) {
+		return BankAccountReasonCountry
+	}
+	return ""
+}
+
+// CanadianTransit is a Canadian bank routing number
+type CanadianTransit struct {
+	Transit     string // 5-digit branch transit number
+	Institution string // 3-digit financial institution number
+}
+
+// String returns the number in the paper format used on cheques, e.g. "12345-678"
+func (t CanadianTransit) String() string {
+	return t.Transit + "-" + t.Institution
+}
+
+// Electronic returns the number in the electronic format used for direct deposits, e.g. "067812345"
+func (t CanadianTransit) Electronic() string {
+	return "0" + t.Institution + t.Transit
+}
+
+// ParseCanadianTransit validates a Canadian routing number, made of a 5-digit branch transit number
+// and a 3-digit institution number. It accepts the paper format "12345-678" or "12345678", and the
+// electronic format "067812345". Canadian routing numbers have no check digit. Invalid numbers return
+// a *BankAccountError with the reason.
+func ParseCanadianTransit(number string) (CanadianTransit, error) {
+	transit, reason := checkCanadianTransit(number)
+	if reason != "" {
+		return CanadianTransit{}, &BankAccountError{Constraint: "ca_transit", Reason: reason, Value: number}
+	}
+	return transit, nil
+}
+
+// checkCanadianTransit returns the parts of a Canadian routing number, or the reason it is not valid
+func checkCanadianTransit(number string) (CanadianTransit, string) {
+	compact := compactAccountNumber(number)
+
+	if transit, institution, ok := strings.Cut(compact, "-"); ok {
+		if !isDigits(transit) || !isDigits(institution) {
+			return CanadianTransit{}, BankAccountReasonFormat
+		}
+		if len(transit) != 5 || len(institution) != 3 {
+			return CanadianTransit{}, BankAccountReasonLength
+		}
+		return CanadianTransit{Transit: transit, Institution: institution}, ""
+	}
+
+	if !isDigits(compact) {
+		return CanadianTransit{}, BankAccountReasonFormat
+	}
+	switch len(compact) {
+	case 8:
+		return CanadianTransit{Transit: compact[:5], Institution: compact[5:]}, ""
+	case 9:
+		if compact[0] != '0' {
+			return CanadianTransit{}, BankAccountReasonFormat
+		}
+		return CanadianTransit{Transit: compact[4:], Institution: compact[1:4]}, ""
+	default:
+		return CanadianTransit{}, BankAccountReasonLength
+	}
+}
+
+// ParseABARoutingNumber validates a US ABA routing transit number and returns its 9 digits. The first two
+// digits must be in a range assigned by the Federal Reserve and the last digit is a weighted checksum.
+// Invalid numbers return a *BankAccountError with the reason.
+func ParseABARoutingNumber(number string) (string, error) {
+	compact := compactAccountNumber(number)
+	if reason := checkABARouting(compact); reason != "" {
+		return "", &BankAccountError{Constraint: "aba_routing", Reason: reason, Value: number}
+	}
+	return compact, nil
+}
+
+// checkABARouting returns the reason a compact ABA routing number is not valid, or an empty string
+func checkABARouting(number string) string {
+	if !isDigits(number) {
+		return BankAccountReasonFormat
+	}
+	if len(number) != 9 {
+		return BankAccountReasonLength
+	}
+
+	// 00 is used by the government, 01-12 are the Federal Reserve districts, 21-32 thrift institutions,
+	// 61-72 electronic transactions and 80 traveler's cheques
+	switch prefix := int(number[0]-'0')*10 + int(number[1]-'0'); {
+	case prefix <= 12, prefix >= 21 && prefix <= 32, prefix >= 61 && prefix <= 72, prefix == 80:
+	default:
+		return BankAccountReasonPrefix
+	}
+
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i, r := range number {
+		sum += int(r-'0') * weights[i%3]
+	}
+	if sum%10 != 0 {
+		return BankAccountReasonChecksum
+	}
+
+	return ""
+}
+
+// bankAccountValue returns the value of a string field, or an empty string
+func bankAccountValue(fl govalidator.FieldLevel) string {
+	if fl.Field().Kind() != reflect.String {
+		return ""
+	}
+	return fl.Field().String()
+}
+
+// AddIBANValidation registers the "iban" tag, which validates International Bank Account Numbers:
+// the length registered for the country and the mod-97 check digits. Spaces are ignored and letters
+// can be in any case. The param optionally lists the accepted countries, separated by spaces:
+//
+//	type Payout struct {
+//	    IBAN string `validate:"required,iban"`
+//	    SEPA string `validate:"omitempty,iban=DE FR NL"`
+//	}
+//
+// Invalid IBANs are reported with the constraint "iban" and the "format", "country", "length" or "checksum"
+// reason. Messages can use these params:
+//   - {country}: the country of the IBAN, when it could be read
+//   - {length}: the expected length for the country
+//   - {countries}: the accepted countries, separated by spaces, or empty
+func AddIBANValidation(v *validator.Validator) error {
+	return v.RegisterViolationValidation("iban", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		countries := bankAccountCountries(fl.Param())
+		iban := compactAccountNumber(bankAccountValue(fl))
+
+		reason := checkIBAN(iban, countries)
+		if reason == "" {
+			return nil
+		}
+
+		params := map[string]interface{}{"countries": strings.Join(countries, " ")}
+		if len(iban) >= 2 {
+			params["country"] = iban[:2]
+			params["length"] = ibanLengths[iban[:2]]
+		}
+
+		message := "{field} must be a valid IBAN"
+		switch reason {
+		case BankAccountReasonCountry:
+			message = "{field} has an unknown IBAN country code"
+			if len(countries) > 0 {
+				message = "{field} must be an IBAN from {countries}"
+			}
+		case BankAccountReasonLength:
+			message = "{field} must have {length} characters for an IBAN from {country}"
+		case BankAccountReasonChecksum:
+			message = "{field} is not a valid IBAN, check for typos"
+		}
+
+		return []validator.Violation{{Reason: reason, Message: message, Params: params}}
+	})
+}
+
+// AddBICValidation registers the "bic_swift" tag, which validates Business Identifier Codes, also known as
+// SWIFT codes. Unlike the "bic" tag of go-playground/validator, which is left as is and only checks the format,
+// it also checks the country code. The param optionally lists the accepted countries, separated by spaces:
+//
+//	type Payout struct {
+//	    BIC string `validate:"required,bic_swift"`
+//	}
+//
+// Invalid BICs are reported with the constraint "bic_swift" and the "format", "length" or "country" reason.
+// Messages can use the {country} and {countries} params.
+func AddBICValidation(v *validator.Validator) error {
+	return v.RegisterViolationValidation("bic_swift", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		countries := bankAccountCountries(fl.Param())
+		bic := compactAccountNumber(bankAccountValue(fl))
+
+		reason := checkBIC(bic, countries)
+		if reason == "" {
+			return nil
+		}
+
+		params := map[string]interface{}{"countries": strings.Join(countries, " ")}
+		if len(bic) >= 6 {
+			params["country"] = bic[4:6]
+		}
+
+		message := "{field} must be a valid BIC"
+		switch reason {
+		case BankAccountReasonLength:
+			message = "{field} must have 8 or 11 characters"
+		case BankAccountReasonCountry:
+			message = "{field} has an unknown BIC country code"
+			if len(countries) > 0 {
+				message = "{field} must be a BIC from {countries}"
+			}
+		}
+
+		return []validator.Violation{{Reason: reason, Message: message, Params: params}}
+	})
+}
+
+// AddCanadianTransitValidation registers the "ca_transit" tag, which validates Canadian bank routing numbers
+// made of a 5-digit branch transit number and a 3-digit institution number, such as "12345-678" or the
+// electronic format "067812345".
+//
+// Invalid numbers are reported with the constraint "ca_transit" and the "format" or "length" reason.
+func AddCanadianTransitValidation(v *validator.Validator) error {
+	return v.RegisterViolationValidation("ca_transit", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		_, reason := checkCanadianTransit(bankAccountValue(fl))
+		if reason == "" {
+			return nil
+		}
+
+		message := "{field} must be a transit number like 12345-678"
+		if reason == BankAccountReasonLength {
+			message = "{field} must have a 5-digit transit number and a 3-digit institution number"
+		}
+
+		return []validator.Violation{{Reason: reason, Message: message}}
+	})
+}
+
+// AddABARoutingValidation registers the "aba_routing" tag, which validates US ABA routing transit numbers:
+// 9 digits, a prefix assigned by the Federal Reserve and a checksum.
+//
+// Invalid numbers are reported with the constraint "aba_routing" and the "format", "length", "prefix" or
+// "checksum" reason.
+func AddABARoutingValidation(v *validator.Validator) error {
+	return v.RegisterViolationValidation("aba_routing", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		reason := checkABARouting(compactAccountNumber(bankAccountValue(fl)))
+		if reason == "" {
+			return nil
+		}
+
+		message := "{field} must be a valid routing number"
+		switch reason {
+		case BankAccountReasonFormat:
+			message = "{field} must only contain digits"
+		case BankAccountReasonLength:
+			message = "{field} must have 9 digits"
+		case BankAccountReasonChecksum:
+			message = "{field} is not a valid routing number, check for typos"
+		}
+
+		return []validator.Violation{{Reason: reason, Message: message}}
+	})
+}
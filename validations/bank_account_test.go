@@ -0,0 +1,226 @@
+package validations
+
+import (
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertBankAccountReason asserts that err is a *BankAccountError with the given reason
+func assertBankAccountReason(t *testing.T, reason string, err error, value string) {
+	t.Helper()
+	if e, ok := err.(*BankAccountError); assert.True(t, ok, value) {
+		assert.Equal(t, reason, e.Reason, value)
+	}
+}
+
+func TestParseIBAN(t *testing.T) {
+	t.Run("Valid IBANs", func(t *testing.T) {
+		for _, iban := range []string{
+			"DE89370400440532013000",
+			"GB82 WEST 1234 5698 7654 32",
+			"fr14 2004 1010 0505 0001 3m02 606",
+			"NL91ABNA0417164300",
+			"BE68539007547034",
+			"NO9386011117947",
+		} {
+			_, err := ParseIBAN(iban)
+			assert.NoError(t, err, iban)
+		}
+
+		iban, err := ParseIBAN("gb82 west 1234 5698 7654 32")
+		assert.NoError(t, err)
+		assert.Equal(t, "GB82WEST12345698765432", iban)
+	})
+
+	t.Run("Invalid IBANs", func(t *testing.T) {
+		tests := []struct {
+			iban   string
+			reason string
+		}{
+			{"", BankAccountReasonFormat},
+			{"DE89-3704-0044", BankAccountReasonFormat},
+			{"DEXX370400440532013000", BankAccountReasonFormat},
+			{"US89370400440532013000", BankAccountReasonCountry},
+			{"DE8937040044053201300", BankAccountReasonLength},
+			{"DE88370400440532013000", BankAccountReasonChecksum},
+			{"DE89370400440532013001", BankAccountReasonChecksum},
+		}
+
+		for _, tt := range tests {
+			_, err := ParseIBAN(tt.iban)
+			assertBankAccountReason(t, tt.reason, err, tt.iban)
+		}
+	})
+
+	t.Run("Accepted countries", func(t *testing.T) {
+		_, err := ParseIBAN("DE89370400440532013000", "de", "FR")
+		assert.NoError(t, err)
+
+		_, err = ParseIBAN("GB82WEST12345698765432", "DE", "FR")
+		assertBankAccountReason(t, BankAccountReasonCountry, err, "GB")
+	})
+}
+
+func TestParseBIC(t *testing.T) {
+	for _, bic := range []string{"DEUTDEFF", "DEUTDEFF500", "nedszajjxxx", "BNPA FR PP"} {
+		_, err := ParseBIC(bic)
+		assert.NoError(t, err, bic)
+	}
+
+	tests := []struct {
+		bic    string
+		reason string
+	}{
+		{"DEUT-DEFF", BankAccountReasonFormat},
+		{"DEU1DEFF", BankAccountReasonFormat},
+		{"DEUTDEFF5", BankAccountReasonLength},
+		{"DEUTZZFF", BankAccountReasonCountry},
+	}
+	for _, tt := range tests {
+		_, err := ParseBIC(tt.bic)
+		assertBankAccountReason(t, tt.reason, err, tt.bic)
+	}
+
+	_, err := ParseBIC("DEUTDEFF", "FR")
+	assertBankAccountReason(t, BankAccountReasonCountry, err, "DEUTDEFF")
+}
+
+func TestParseCanadianTransit(t *testing.T) {
+	for _, number := range []string{"12345-678", "12345678", "067812345"} {
+		transit, err := ParseCanadianTransit(number)
+		assert.NoError(t, err, number)
+		assert.Equal(t, CanadianTransit{Transit: "12345", Institution: "678"}, transit, number)
+		assert.Equal(t, "12345-678", transit.String())
+		assert.Equal(t, "067812345", transit.Electronic())
+	}
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"1234A-678", BankAccountReasonFormat},
+		{"167812345", BankAccountReasonFormat},
+		{"1234-678", BankAccountReasonLength},
+		{"1234567", BankAccountReasonLength},
+	}
+	for _, tt := range tests {
+		_, err := ParseCanadianTransit(tt.number)
+		assertBankAccountReason(t, tt.reason, err, tt.number)
+	}
+}
+
+func TestParseABARoutingNumber(t *testing.T) {
+	for _, number := range []string{"021000021", "011000015", "322271627"} {
+		_, err := ParseABARoutingNumber(number)
+		assert.NoError(t, err, number)
+	}
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"02100002A", BankAccountReasonFormat},
+		{"02100002", BankAccountReasonLength},
+		{"131000021", BankAccountReasonPrefix},
+		{"021000022", BankAccountReasonChecksum},
+	}
+	for _, tt := range tests {
+		_, err := ParseABARoutingNumber(tt.number)
+		assertBankAccountReason(t, tt.reason, err, tt.number)
+	}
+}
+
+func TestBankAccountValidation(t *testing.T) {
+	type Payout struct {
+		IBAN    string `json:"iban" validate:"omitempty,iban"`
+		SEPA    string `json:"sepa" validate:"omitempty,iban=DE FR"`
+		BIC     string `json:"bic" validate:"omitempty,bic_swift"`
+		Transit string `json:"transit" validate:"omitempty,ca_transit"`
+		Routing string `json:"routing" validate:"omitempty,aba_routing"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddIBANValidation(v))
+	assert.NoError(t, AddBICValidation(v))
+	assert.NoError(t, AddCanadianTransitValidation(v))
+	assert.NoError(t, AddABARoutingValidation(v))
+
+	t.Run("Valid numbers", func(t *testing.T) {
+		assert.NoError(t, v.Validate(Payout{
+			IBAN:    "GB82 WEST 1234 5698 7654 32",
+			SEPA:    "DE89370400440532013000",
+			BIC:     "DEUTDEFF",
+			Transit: "12345-678",
+			Routing: "021000021",
+		}))
+	})
+
+	t.Run("Reasons and messages", func(t *testing.T) {
+		errs := v.Validate(Payout{
+			IBAN:    "DE88370400440532013000",
+			SEPA:    "GB82WEST12345698765432",
+			BIC:     "DEUTZZFF",
+			Transit: "1234-678",
+			Routing: "021000022",
+		}).(validator.ValidationErrors)
+
+		assert.Len(t, errs, 5)
+		assert.Equal(t, "iban", errs[0].Constraint)
+		assert.Equal(t, BankAccountReasonChecksum, errs[0].Reason)
+		assert.Equal(t, "iban is not a valid IBAN, check for typos", errs[0].Message)
+
+		assert.Equal(t, BankAccountReasonCountry, errs[1].Reason)
+		assert.Equal(t, "GB", errs[1].Params["country"])
+		assert.Equal(t, "sepa must be an IBAN from DE FR", errs[1].Message)
+
+		assert.Equal(t, "bic_swift", errs[2].Constraint)
+		assert.Equal(t, BankAccountReasonCountry, errs[2].Reason)
+
+		assert.Equal(t, "ca_transit", errs[3].Constraint)
+		assert.Equal(t, BankAccountReasonLength, errs[3].Reason)
+
+		assert.Equal(t, "aba_routing", errs[4].Constraint)
+		assert.Equal(t, BankAccountReasonChecksum, errs[4].Reason)
+	})
+
+	t.Run("IBAN length", func(t *testing.T) {
+		errs := v.Validate(Payout{IBAN: "DE8937040044053201300"}).(validator.ValidationErrors)
+		assert.Equal(t, BankAccountReasonLength, errs[0].Reason)
+		assert.Equal(t, 22, errs[0].Params["length"])
+		assert.Equal(t, "iban must have 22 characters for an IBAN from DE", errs[0].Message)
+	})
+
+	t.Run("Message per reason", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddIBANValidation(v))
+		v.SetDefaultTagMessage("iban.checksum", "Please double-check your IBAN")
+
+		type Account struct {
+			IBAN string `validate:"iban"`
+		}
+		errs := v.Validate(Account{IBAN: "DE88370400440532013000"}).(validator.ValidationErrors)
+		assert.Equal(t, "Please double-check your IBAN", errs[0].Message)
+	})
+
+	t.Run("Built-in bic tag is unchanged", func(t *testing.T) {
+		type Legacy struct {
+			BIC string `validate:"bic"`
+		}
+		// The built-in tag only checks the format, so the unknown country passes
+		assert.NoError(t, v.Validate(Legacy{BIC: "DEUTZZFF"}))
+
+		errs := v.Validate(Legacy{BIC: "DEUT"}).(validator.ValidationErrors)
+		assert.Equal(t, "bic", errs[0].Constraint)
+		assert.Empty(t, errs[0].Reason)
+	})
+
+	t.Run("Unknown country panics", func(t *testing.T) {
+		type Invalid struct {
+			IBAN string `validate:"iban=ZZ"`
+		}
+		assert.Panics(t, func() { _ = v.Validate(Invalid{IBAN: "DE89370400440532013000"}) })
+	})
+}
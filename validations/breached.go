@@ -0,0 +1,268 @@
+package validations
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPwnedPasswordsURL is the range endpoint of the Have I Been Pwned Pwned Passwords API
+const DefaultPwnedPasswordsURL = "https://api.pwnedpasswords.com/range/"
+
+// DefaultBreachCheckTimeout is the time limit of a breach check when BreachCheck.Timeout is not set
+const DefaultBreachCheckTimeout = 2 * time.Second
+
+// DefaultPwnedPasswordsCacheTTL is how long CachedPwnedPasswordsClient keeps responses when its ttl is not positive
+const DefaultPwnedPasswordsCacheTTL = time.Hour
+
+// maxPwnedRangeBytes is the maximum size of a range response. Responses of the API are about 40 KB, or twice
+// that with padding.
+const maxPwnedRangeBytes = 1 << 20
+
+// PwnedPasswordsClient looks up breached passwords using k-anonymity: only the first 5 characters
+// of the uppercase hex SHA-1 hash of a password are sent, and the client returns the remaining
+// 35 characters of every breached hash with that prefix, mapped to the number of times it was seen.
+type PwnedPasswordsClient interface {
+	Range(ctx context.Context, prefix string) (map[string]int, error)
+}
+
+// BreachCheck configures the check of passwords against a breached passwords API
+type BreachCheck struct {
+	Client     PwnedPasswordsClient // Client used to look up hash prefixes
+	Timeout    time.Duration        // Time limit of a check, defaults to DefaultBreachCheckTimeout
+	FailClosed bool                 // Rejects the password when the check fails, instead of accepting it
+	MinCount   int                  // Minimum number of breaches for a password to be rejected, defaults to 1
+}
+
+// PwnedPasswordCount returns the number of times password appears in breaches according to client
+func PwnedPasswordCount(ctx context.Context, client PwnedPasswordsClient, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := client.Range(ctx, hash[:5])
+	if err != nil {
+		return 0, err
+	}
+
+	return suffixes[hash[5:]], nil
+}
+
+// check returns the number of breaches of password, applying the timeout of the check
+func (b *BreachCheck) check(ctx context.Context, password string) (int, error) {
+	if b.Client == nil {
+		return 0, fmt.Errorf("validations: breach check has no client")
+	}
+
+	timeout := b.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBreachCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return PwnedPasswordCount(ctx, b.Client, password)
+}
+
+// minCount returns the minimum number of breaches for a password to be rejected
+func (b *BreachCheck) minCount() int {
+	if b.MinCount <= 0 {
+		return 1
+	}
+	return b.MinCount
+}
+
+// HTTPPwnedPasswordsClient queries a Pwned Passwords compatible range API over HTTP
+type HTTPPwnedPasswordsClient struct {
+	BaseURL    string       // URL the prefix is appended to, defaults to DefaultPwnedPasswordsURL
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+	UserAgent  string       // Sent with every request when set
+	AddPadding bool         // Asks the API to pad responses, so their size does not reveal the prefix
+}
+
+// NewHTTPPwnedPasswordsClient creates a client for the Have I Been Pwned Pwned Passwords API
+func NewHTTPPwnedPasswordsClient() *HTTPPwnedPasswordsClient {
+	return &HTTPPwnedPasswordsClient{
+		BaseURL:    DefaultPwnedPasswordsURL,
+		HTTPClient: http.DefaultClient,
+		AddPadding: true,
+	}
+}
+
+// Range returns the breached hash suffixes for a 5 character hash prefix
+func (c *HTTPPwnedPasswordsClient) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultPwnedPasswordsURL
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.AddPadding {
+		req.Header.Set("Add-Padding", "true")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("validations: pwned passwords range request failed with status %d", resp.StatusCode)
+	}
+
+	// Larger responses are rejected rather than truncated, as missing suffixes would accept breached passwords
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPwnedRangeBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxPwnedRangeBytes {
+		return nil, fmt.Errorf("validations: pwned passwords range response exceeds %d bytes", maxPwnedRangeBytes)
+	}
+
+	suffixes := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		suffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("validations: invalid pwned passwords range line %q", scanner.Text())
+		}
+		// Padding entries have a count of 0
+		if n > 0 {
+			suffixes[strings.ToUpper(suffix)] = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return suffixes, nil
+}
+
+// CachedPwnedPasswordsClient caches the range responses of another client in memory.
+// Failed lookups are not cached.
+type CachedPwnedPasswordsClient struct {
+	client     PwnedPasswordsClient
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]pwnedRangeEntry
+	order   []string // prefixes from the oldest to the newest response, used to evict entries
+	now     func() time.Time
+}
+
+// pwnedRangeEntry is a cached range response
+type pwnedRangeEntry struct {
+	suffixes map[string]int
+	expires  time.Time
+}
+
+// NewCachedPwnedPasswordsClient wraps client with a cache keeping responses for ttl, or for
+// DefaultPwnedPasswordsCacheTTL if ttl is not positive.
+// When the cache holds maxEntries prefixes, the oldest one is evicted; 0 means no limit.
+// Expired responses are looked up again on their next use, and dropped when newer ones are stored.
+func NewCachedPwnedPasswordsClient(client PwnedPasswordsClient, ttl time.Duration, maxEntries int) *CachedPwnedPasswordsClient {
+	if ttl <= 0 {
+		ttl = DefaultPwnedPasswordsCacheTTL
+	}
+
+	return &CachedPwnedPasswordsClient{
+		client:     client,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]pwnedRangeEntry),
+		now:        time.Now,
+	}
+}
+
+// Range returns the cached response for prefix, or looks it up with the wrapped client when it is
+// missing or expired. The returned map is a copy, so callers may modify it.
+func (c *CachedPwnedPasswordsClient) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[prefix]
+	c.mu.Unlock()
+
+	if ok && c.now().Before(entry.expires) {
+		return copyPwnedRange(entry.suffixes), nil
+	}
+
+	return c.Refresh(ctx, prefix)
+}
+
+// Refresh looks prefix up with the wrapped client and caches the response, even if the cached one has
+// not expired. When the lookup fails, the cached response is dropped.
+func (c *CachedPwnedPasswordsClient) Refresh(ctx context.Context, prefix string) (map[string]int, error) {
+	suffixes, err := c.client.Range(ctx, prefix)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	c.entries[prefix] = pwnedRangeEntry{suffixes: copyPwnedRange(suffixes), expires: now.Add(c.ttl)}
+	c.order = append(c.order, prefix)
+
+	// Entries are ordered by expiry, so the expired ones and the oldest ones are at the front
+	for len(c.order) > 0 {
+		oldest := c.order[0]
+		if now.Before(c.entries[oldest].expires) && (c.maxEntries <= 0 || len(c.entries) <= c.maxEntries) {
+			break
+		}
+		c.order = c.order[1:]
+		delete(c.entries, oldest)
+	}
+
+	return suffixes, nil
+}
+
+// remove drops the cached response for prefix. c.mu must be held.
+func (c *CachedPwnedPasswordsClient) remove(prefix string) {
+	if _, ok := c.entries[prefix]; !ok {
+		return
+	}
+	delete(c.entries, prefix)
+
+	for i, p := range c.order {
+		if p == prefix {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// copyPwnedRange returns a copy of a range response
+func copyPwnedRange(suffixes map[string]int) map[string]int {
+	copied := make(map[string]int, len(suffixes))
+	for suffix, count := range suffixes {
+		copied[suffix] = count
+	}
+	return copied
+}
@@ -0,0 +1,288 @@
+package validations
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPwnedPasswordsServer starts a range API stand-in that knows the given breached passwords
+func newPwnedPasswordsServer(t *testing.T, breached map[string]int) (*httptest.Server, *int32) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+
+		for password, count := range breached {
+			sum := sha1.Sum([]byte(password))
+			hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+			if hash[:5] == prefix {
+				fmt.Fprintf(w, "%s:%d\r\n", hash[5:], count)
+			}
+		}
+		if r.Header.Get("Add-Padding") == "true" {
+			fmt.Fprint(w, "0000000000000000000000000000000000A:0\r\n")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// failingPwnedPasswordsClient is a client whose lookups always fail
+type failingPwnedPasswordsClient struct{}
+
+func (failingPwnedPasswordsClient) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestHTTPPwnedPasswordsClient(t *testing.T) {
+	server, _ := newPwnedPasswordsServer(t, map[string]int{"P@ssw0rd123!": 42})
+
+	client := NewHTTPPwnedPasswordsClient()
+	client.BaseURL = server.URL + "/range/"
+
+	count, err := PwnedPasswordCount(context.Background(), client, "P@ssw0rd123!")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, count)
+
+	count, err = PwnedPasswordCount(context.Background(), client, "Xq7!mVz#42pL")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	t.Run("Error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := &HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}
+		_, err := client.Range(context.Background(), "ABCDE")
+		assert.EqualError(t, err, "validations: pwned passwords range request failed with status 429")
+	})
+
+	t.Run("Oversized response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			line := "0000000000000000000000000000000000A:1\r\n"
+			for written := 0; written <= maxPwnedRangeBytes; written += len(line) {
+				fmt.Fprint(w, line)
+			}
+		}))
+		defer server.Close()
+
+		client := &HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}
+		_, err := client.Range(context.Background(), "ABCDE")
+		assert.EqualError(t, err, "validations: pwned passwords range response exceeds 1048576 bytes")
+	})
+}
+
+func TestCachedPwnedPasswordsClient(t *testing.T) {
+	server, requests := newPwnedPasswordsServer(t, map[string]int{"P@ssw0rd123!": 42})
+	client := NewCachedPwnedPasswordsClient(&HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}, time.Minute, 1)
+
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		count, err := PwnedPasswordCount(context.Background(), client, "P@ssw0rd123!")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, count)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	// Expired entries are looked up again
+	now = now.Add(2 * time.Minute)
+	_, _ = PwnedPasswordCount(context.Background(), client, "P@ssw0rd123!")
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+
+	// The oldest entry is evicted when the cache is full
+	_, _ = PwnedPasswordCount(context.Background(), client, "another password")
+	_, _ = PwnedPasswordCount(context.Background(), client, "P@ssw0rd123!")
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+	assert.Len(t, client.entries, 1)
+
+	t.Run("Default TTL", func(t *testing.T) {
+		server, requests := newPwnedPasswordsServer(t, nil)
+		client := NewCachedPwnedPasswordsClient(&HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}, 0, 0)
+		assert.Equal(t, DefaultPwnedPasswordsCacheTTL, client.ttl)
+
+		_, _ = client.Range(context.Background(), "AAAAA")
+		_, _ = client.Range(context.Background(), "AAAAA")
+		assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	})
+
+	t.Run("Responses are copies", func(t *testing.T) {
+		server, _ := newPwnedPasswordsServer(t, map[string]int{"P@ssw0rd123!": 42})
+		client := NewCachedPwnedPasswordsClient(&HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}, time.Minute, 0)
+
+		sum := sha1.Sum([]byte("P@ssw0rd123!"))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+		suffixes, err := client.Range(context.Background(), hash[:5])
+		assert.NoError(t, err)
+		delete(suffixes, hash[5:])
+
+		suffixes, err = client.Range(context.Background(), hash[:5])
+		assert.NoError(t, err)
+		assert.Equal(t, 42, suffixes[hash[5:]])
+	})
+
+	t.Run("Refreshed entries are the newest", func(t *testing.T) {
+		server, requests := newPwnedPasswordsServer(t, nil)
+		client := NewCachedPwnedPasswordsClient(&HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}, time.Minute, 2)
+
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		_, _ = client.Range(context.Background(), "AAAAA")
+		now = now.Add(30 * time.Second)
+		_, _ = client.Range(context.Background(), "BBBBB")
+
+		// AAAAA expires and is looked up again, so BBBBB is now the oldest entry
+		now = now.Add(45 * time.Second)
+		_, _ = client.Range(context.Background(), "AAAAA")
+		_, _ = client.Range(context.Background(), "CCCCC")
+		assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+		assert.Contains(t, client.entries, "AAAAA")
+		assert.NotContains(t, client.entries, "BBBBB")
+
+		_, _ = client.Range(context.Background(), "AAAAA")
+		assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+
+		// Refresh looks a prefix up even if it has not expired
+		_, err := client.Refresh(context.Background(), "AAAAA")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(5), atomic.LoadInt32(requests))
+	})
+
+	t.Run("Expired entries are dropped", func(t *testing.T) {
+		server, _ := newPwnedPasswordsServer(t, nil)
+		client := NewCachedPwnedPasswordsClient(&HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}, time.Minute, 0)
+
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		_, _ = client.Range(context.Background(), "AAAAA")
+		now = now.Add(2 * time.Minute)
+		_, _ = client.Range(context.Background(), "BBBBB")
+		assert.Len(t, client.entries, 1)
+		assert.Equal(t, []string{"BBBBB"}, client.order)
+	})
+
+	t.Run("Failed refreshes drop the entry", func(t *testing.T) {
+		server, _ := newPwnedPasswordsServer(t, nil)
+		client := NewCachedPwnedPasswordsClient(&HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}, time.Minute, 0)
+
+		_, _ = client.Range(context.Background(), "AAAAA")
+		client.client = failingPwnedPasswordsClient{}
+
+		_, err := client.Refresh(context.Background(), "AAAAA")
+		assert.EqualError(t, err, "unavailable")
+		assert.Empty(t, client.entries)
+		assert.Empty(t, client.order)
+	})
+}
+
+func TestPasswordBreachCheck(t *testing.T) {
+	type Signup struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	server, requests := newPwnedPasswordsServer(t, map[string]int{"P@ssw0rd123!": 42})
+
+	newValidator := func(check *BreachCheck) *validator.Validator {
+		options := DefaultPasswordOptions()
+		options.BreachCheck = check
+
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPasswordValidation(v, options))
+		return v
+	}
+
+	client := &HTTPPwnedPasswordsClient{BaseURL: server.URL + "/range/"}
+
+	t.Run("Breached password", func(t *testing.T) {
+		v := newValidator(&BreachCheck{Client: client})
+
+		errs := v.ValidateCtx(context.Background(), Signup{Password: "P@ssw0rd123!"}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, PasswordReasonBreached, errs[0].Reason)
+		assert.Equal(t, 42, errs[0].Params["count"])
+		assert.Equal(t, "Password has appeared in a data breach", errs[0].Message)
+
+		assert.NoError(t, v.Validate(Signup{Password: "Xq7!mVz#42pL"}))
+	})
+
+	t.Run("MinCount", func(t *testing.T) {
+		v := newValidator(&BreachCheck{Client: client, MinCount: 100})
+		assert.NoError(t, v.Validate(Signup{Password: "P@ssw0rd123!"}))
+	})
+
+	t.Run("Skipped when other requirements fail", func(t *testing.T) {
+		v := newValidator(&BreachCheck{Client: client})
+		before := atomic.LoadInt32(requests)
+
+		errs := v.Validate(Signup{Password: "short"}).(validator.ValidationErrors)
+		for _, e := range errs {
+			assert.NotEqual(t, PasswordReasonBreached, e.Reason)
+		}
+		assert.Equal(t, before, atomic.LoadInt32(requests))
+	})
+
+	t.Run("Fail open", func(t *testing.T) {
+		v := newValidator(&BreachCheck{Client: failingPwnedPasswordsClient{}})
+		assert.NoError(t, v.Validate(Signup{Password: "P@ssw0rd123!"}))
+	})
+
+	t.Run("Fail closed", func(t *testing.T) {
+		v := newValidator(&BreachCheck{Client: failingPwnedPasswordsClient{}, FailClosed: true})
+
+		errs := v.Validate(Signup{Password: "P@ssw0rd123!"}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, PasswordReasonBreachCheckFailed, errs[0].Reason)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer slow.Close()
+
+		v := newValidator(&BreachCheck{
+			Client:     &HTTPPwnedPasswordsClient{BaseURL: slow.URL + "/range/"},
+			Timeout:    10 * time.Millisecond,
+			FailClosed: true,
+		})
+
+		start := time.Now()
+		errs := v.Validate(Signup{Password: "P@ssw0rd123!"}).(validator.ValidationErrors)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, PasswordReasonBreachCheckFailed, errs[0].Reason)
+	})
+
+	t.Run("Request context is used", func(t *testing.T) {
+		v := newValidator(&BreachCheck{Client: client, FailClosed: true})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		errs := v.ValidateCtx(ctx, Signup{Password: "P@ssw0rd123!"}).(validator.ValidationErrors)
+		assert.Equal(t, PasswordReasonBreachCheckFailed, errs[0].Reason)
+	})
+}
@@ -0,0 +1,499 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/mail"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// disposableDomainsFile is the embedded list of disposable email domains
+const disposableDomainsFile = "data/disposable_domains.txt"
+
+// DefaultEmailLookupTimeout is the maximum duration of the DNS lookups of an email address
+const DefaultEmailLookupTimeout = 2 * time.Second
+
+// CommonEmailDomains are the domains suggested for typos when EmailOptions.SuggestDomains is nil
+var CommonEmailDomains = []string{
+	"gmail.com", "googlemail.com", "yahoo.com", "yahoo.co.uk", "hotmail.com", "hotmail.co.uk", "outlook.com",
+	"live.com", "msn.com", "icloud.com", "me.com", "mac.com", "aol.com", "proton.me", "protonmail.com",
+	"gmx.com", "gmx.de", "web.de", "yandex.com", "mail.com", "zoho.com", "comcast.net", "verizon.net",
+}
+
+// Reasons reported by the "email_deliverable" validation
+const (
+	EmailReasonFormat         = "format"           // Not an email address
+	EmailReasonFQDN           = "fqdn"             // The domain is not a fully qualified domain name, e.g. "localhost"
+	EmailReasonDisposable     = "disposable"       // The domain provides disposable addresses
+	EmailReasonTypo           = "typo"             // The domain looks like a typo of a common domain
+	EmailReasonNoMX           = "no_mx"            // The domain cannot receive email
+	EmailReasonDNSCheckFailed = "dns_check_failed" // The DNS lookup failed and FailClosed is set
+)
+
+// Checks that can be selected with the param of the "email_deliverable" tag
+const (
+	EmailCheckFQDN       = "fqdn"
+	EmailCheckDisposable = "disposable"
+	EmailCheckTypo       = "typo"
+	EmailCheckDNS        = "dns"
+)
+
+// EmailResolver looks up the DNS records of email domains. *net.Resolver implements it.
+type EmailResolver interface {
+	// LookupMX returns the MX records of a domain
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	// LookupHost returns the addresses of a host, from its A and AAAA records
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// EmailOptions configures the "email_deliverable" validation
+type EmailOptions struct {
+	RequireFQDN    bool          // Reject domains without a dot, such as "localhost", and IP address literals
+	Resolver       EmailResolver // Checks that the domain has MX, A or AAAA records; nil skips the DNS check
+	LookupTimeout  time.Duration // Maximum duration of the DNS lookups, defaults to DefaultEmailLookupTimeout
+	FailClosed     bool          // Reject addresses when the DNS lookup fails instead of accepting them
+	Disposable     *DomainList   // Domains providing disposable addresses, with their subdomains; nil allows all
+	SuggestDomains []string      // Domains suggested for typos, defaults to CommonEmailDomains
+	RejectTypos    bool          // Reject domains that look like a typo of a suggested domain
+	Async          bool          // Check addresses concurrently with validator.RunAsync, for slices of addresses
+}
+
+// DefaultEmailOptions returns options requiring a fully qualified domain that can receive email, looked up
+// with net.DefaultResolver, and blocking the embedded list of disposable domains
+func DefaultEmailOptions() EmailOptions {
+	return EmailOptions{
+		RequireFQDN: true,
+		Resolver:    net.DefaultResolver,
+		Disposable:  DisposableEmailDomains(),
+	}
+}
+
+// DomainList is a set of domains that also contains their subdomains, so a list containing
+// "mailinator.com" contains "eu.mailinator.com". It is safe for concurrent use and can be updated
+// while validations use it, e.g. from a periodically refreshed file.
+type DomainList struct {
+	mu      sync.RWMutex
+	domains map[string]struct{}
+}
+
+// NewDomainList creates a DomainList from the given domains
+func NewDomainList(domains ...string) *DomainList {
+	list := &DomainList{domains: make(map[string]struct{}, len(domains))}
+	list.Add(domains...)
+	return list
+}
+
+// ReadDomainList reads a DomainList from r, with one domain per line. Empty lines and lines starting
+// with "#" are ignored.
+func ReadDomainList(r io.Reader) (*DomainList, error) {
+	domains, err := scanWords(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewDomainList(domains...), nil
+}
+
+// LoadDomainList reads a DomainList from a file of fsys, see ReadDomainList.
+// Files ending in ".gz" are decompressed.
+func LoadDomainList(fsys fs.FS, name string) (*DomainList, error) {
+	domains, err := readWordList(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewDomainList(domains...), nil
+}
+
+var (
+	disposableDomains     []string
+	disposableDomainsOnce sync.Once
+)
+
+// DisposableEmailDomains returns a new DomainList with the disposable email domains embedded in the package.
+// Each call returns a separate list, so adding domains to it does not affect other lists.
+func DisposableEmailDomains() *DomainList {
+	disposableDomainsOnce.Do(func() {
+		domains, err := readWordList(dataFiles, disposableDomainsFile)
+		if err != nil {
+			panic("validations: cannot read embedded disposable domains: " + err.Error())
+		}
+		disposableDomains = domains
+	})
+	return NewDomainList(disposableDomains...)
+}
+
+// Add adds domains to the list
+func (l *DomainList) Add(domains ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, domain := range domains {
+		if domain = normalizeEmailDomain(domain); domain != "" && !strings.HasPrefix(domain, "#") {
+			l.domains[domain] = struct{}{}
+		}
+	}
+}
+
+// Remove removes domains from the list
+func (l *DomainList) Remove(domains ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, domain := range domains {
+		delete(l.domains, normalizeEmailDomain(domain))
+	}
+}
+
+// Contains reports whether domain or one of its parent domains is in the list, ignoring case.
+// It returns false for a nil list.
+func (l *DomainList) Contains(domain string) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for domain = normalizeEmailDomain(domain); domain != ""; {
+		if _, ok := l.domains[domain]; ok {
+			return true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return false
+}
+
+// Len returns the number of domains of the list. It returns 0 for a nil list.
+func (l *DomainList) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.domains)
+}
+
+// normalizeEmailDomain converts a domain to lower case, without surrounding spaces and trailing dot
+func normalizeEmailDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// SuggestEmailDomain returns the domain of domains that domain is most likely a typo of, e.g. "gmail.com"
+// for "gmial.com". Domains that are one of domains, or too different from all of them, have no suggestion.
+// Without domains, CommonEmailDomains are used.
+//
+// Like mailcheck, the name of a domain, its first label, and the rest of the domain, its TLD, are compared
+// separately. A domain with the name of a suggested domain and a country code TLD, such as "yahoo.ca" or
+// "live.co.uk", is a real domain, and has no suggestion.
+func SuggestEmailDomain(domain string, domains ...string) (string, bool) {
+	if len(domains) == 0 {
+		domains = CommonEmailDomains
+	}
+
+	domain = normalizeEmailDomain(domain)
+	name, tld, ok := strings.Cut(domain, ".")
+	if !ok || name == "" || tld == "" {
+		return "", false
+	}
+
+	best, bestDistance := "", 0
+	for _, candidate := range domains {
+		candidate = normalizeEmailDomain(candidate)
+		if candidate == domain {
+			return "", false
+		}
+		candidateName, candidateTLD, _ := strings.Cut(candidate, ".")
+
+		// A TLD that differs from the one of the candidate must not be a real TLD
+		tldDistance := 0
+		if tld != candidateTLD {
+			if isCountryCodeTLD(tld) {
+				continue
+			}
+			if tldDistance = editDistance(tld, candidateTLD); tldDistance > 1 {
+				continue
+			}
+		}
+
+		// Short names tolerate a single edit, so that unrelated short domains are not suggested
+		maxDistance := 2
+		if len(candidateName) < 5 {
+			maxDistance = 1
+		}
+		nameDistance := editDistance(name, candidateName)
+		if nameDistance > maxDistance {
+			continue
+		}
+
+		if distance := nameDistance + tldDistance; distance <= 2 && (best == "" || distance < bestDistance) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, best != ""
+}
+
+// isCountryCodeTLD reports whether the last label of tld is a country code TLD, e.g. "ca" or "co.uk"
+func isCountryCodeTLD(tld string) bool {
+	if i := strings.LastIndexByte(tld, '.'); i >= 0 {
+		tld = tld[i+1:]
+	}
+	return len(tld) == 2 && countryCodes[strings.ToUpper(tld)]
+}
+
+// editDistance returns the optimal string alignment distance between a and b: the number of insertions,
+// deletions, substitutions and transpositions of adjacent characters needed to turn a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+// isFQDN reports whether domain is a fully qualified domain name that can be used in email addresses
+func isFQDN(domain string) bool {
+	if strings.HasPrefix(domain, "[") || net.ParseIP(domain) != nil || len(domain) > 253 {
+		return false
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+	}
+
+	// Top-level domains are never numeric
+	tld := labels[len(labels)-1]
+	return strings.Trim(tld, "0123456789") != ""
+}
+
+// checkEmailDNS returns the reason the domain cannot receive email according to resolver, or an empty string. Domains without MX
+// records can still receive email on their A or AAAA records, unless they publish a null MX (RFC 7505).
+func checkEmailDNS(ctx context.Context, resolver EmailResolver, options EmailOptions, domain string) string {
+	timeout := options.LookupTimeout
+	if timeout <= 0 {
+		timeout = DefaultEmailLookupTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	failed := func() string {
+		if options.FailClosed {
+			return EmailReasonDNSCheckFailed
+		}
+		return ""
+	}
+
+	records, err := resolver.LookupMX(ctx, domain)
+	switch {
+	case err == nil && len(records) > 0:
+		if len(records) == 1 && (records[0].Host == "." || records[0].Host == "") {
+			return EmailReasonNoMX
+		}
+		return ""
+	case err != nil && !isDNSNotFound(err):
+		return failed()
+	}
+
+	addrs, err := resolver.LookupHost(ctx, domain)
+	switch {
+	case err == nil && len(addrs) > 0:
+		return ""
+	case err != nil && !isDNSNotFound(err):
+		return failed()
+	}
+	return EmailReasonNoMX
+}
+
+// isDNSNotFound reports whether err means that a domain or its records do not exist
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// AddEmailDeliverableValidation registers the "email_deliverable" tag, which rejects email addresses that
+// are valid but cannot or should not receive email: domains that are not fully qualified such as
+// "user@localhost", disposable domains, typos of common domains and domains without mail servers.
+//
+// Without a param, every check enabled in options runs. The param selects checks, separated by spaces,
+// e.g. to skip the DNS lookup when importing addresses in bulk:
+//
+//	type Signup struct {
+//	    Email string `validate:"required,email,email_deliverable"`
+//	    Login string `validate:"required,email_deliverable=fqdn disposable"`
+//	}
+//
+// The checks are "fqdn", "disposable", "typo" and "dns". Checks selected in the param run even if they are
+// not enabled in options: "disposable" uses DisposableEmailDomains when options.Disposable is nil, and "dns"
+// uses net.DefaultResolver when options.Resolver is nil. Unknown checks panic.
+//
+// DNS lookups use the context passed to ValidateCtx, limited to options.LookupTimeout, so they end with
+// the deadline of a request. When a lookup fails or times out the address is accepted, unless
+// options.FailClosed is set. With options.Async, addresses are checked concurrently on the worker pool of
+// the validator, see validator.RegisterAsyncValidation, so the lookups of a slice of addresses overlap.
+//
+// Invalid addresses are reported with the constraint "email_deliverable" and one of the EmailReason* values
+// as reason. Messages can use these params:
+//   - {domain}: the domain of the address
+//   - {suggestion}: the domain the address is most likely a typo of, e.g. "gmail.com", or empty
+//   - {suggested_email}: the address with the suggested domain, or empty
+func AddEmailDeliverableValidation(v *validator.Validator, options EmailOptions) error {
+	if len(options.SuggestDomains) == 0 {
+		options.SuggestDomains = CommonEmailDomains
+	}
+
+	// The checks and the address are read from the field before RunAsync, as fl is reused once the struct is traversed
+	if options.Async {
+		return v.BaseValidator.RegisterValidationCtx("email_deliverable", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+			checks := emailChecks(fl, options)
+			email := emailFieldValue(fl)
+			return validator.RunAsync(ctx, fl, func(ctx context.Context, param string, value interface{}) []validator.Violation {
+				return checkEmailDeliverable(ctx, email, checks, options)
+			})
+		})
+	}
+
+	return v.RegisterViolationValidation("email_deliverable", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		return checkEmailDeliverable(ctx, emailFieldValue(fl), emailChecks(fl, options), options)
+	})
+}
+
+// emailFieldValue returns the address of a string field, or an empty string
+func emailFieldValue(fl govalidator.FieldLevel) string {
+	if fl.Field().Kind() != reflect.String {
+		return ""
+	}
+	return strings.TrimSpace(fl.Field().String())
+}
+
+// checkEmailDeliverable runs checks on email and returns the first violation found
+func checkEmailDeliverable(ctx context.Context, email string, checks emailCheckSet, options EmailOptions) []validator.Violation {
+	violation := func(reason, message string, params map[string]interface{}) []validator.Violation {
+		return []validator.Violation{{Reason: reason, Message: message, Params: params}}
+	}
+
+	address, err := mail.ParseAddress(email)
+	at := strings.LastIndex(email, "@")
+	if err != nil || address.Address != email || at < 1 {
+		return violation(EmailReasonFormat, "{field} must be a valid email address", map[string]interface{}{
+			"domain": "", "suggestion": "", "suggested_email": "",
+		})
+	}
+
+	domain := normalizeEmailDomain(email[at+1:])
+	params := map[string]interface{}{"domain": domain, "suggestion": "", "suggested_email": ""}
+	if suggestion, ok := SuggestEmailDomain(domain, options.SuggestDomains...); ok {
+		params["suggestion"] = suggestion
+		params["suggested_email"] = email[:at+1] + suggestion
+	}
+
+	if checks.fqdn && !isFQDN(domain) {
+		return violation(EmailReasonFQDN, "{field} must use a valid domain", params)
+	}
+	if checks.disposable != nil && checks.disposable.Contains(domain) {
+		return violation(EmailReasonDisposable, "{field} cannot use a disposable email address", params)
+	}
+	if checks.typo && params["suggestion"] != "" {
+		return violation(EmailReasonTypo, "{field} looks like a typo, did you mean {suggested_email}?", params)
+	}
+	if checks.resolver != nil {
+		switch checkEmailDNS(ctx, checks.resolver, options, domain) {
+		case EmailReasonNoMX:
+			return violation(EmailReasonNoMX, "{field} must use a domain that can receive email", params)
+		case EmailReasonDNSCheckFailed:
+			return violation(EmailReasonDNSCheckFailed, "{field} could not be verified, please try again", params)
+		}
+	}
+
+	return nil
+}
+
+// emailCheckSet are the checks run on a field
+type emailCheckSet struct {
+	fqdn       bool
+	disposable *DomainList
+	typo       bool
+	resolver   EmailResolver
+}
+
+// emailChecks returns the checks selected by the tag param of a field, or enabled in options without param
+func emailChecks(fl govalidator.FieldLevel, options EmailOptions) emailCheckSet {
+	names := strings.Fields(fl.Param())
+	if len(names) == 0 {
+		return emailCheckSet{
+			fqdn:       options.RequireFQDN,
+			disposable: options.Disposable,
+			typo:       options.RejectTypos,
+			resolver:   options.Resolver,
+		}
+	}
+
+	var checks emailCheckSet
+	for _, name := range names {
+		switch name {
+		case EmailCheckFQDN:
+			checks.fqdn = true
+		case EmailCheckDisposable:
+			checks.disposable = options.Disposable
+			if checks.disposable == nil {
+				checks.disposable = defaultDisposableList()
+			}
+		case EmailCheckTypo:
+			checks.typo = true
+		case EmailCheckDNS:
+			checks.resolver = options.Resolver
+			if checks.resolver == nil {
+				checks.resolver = net.DefaultResolver
+			}
+		default:
+			panic(fmt.Sprintf("validations: email_deliverable on field '%s' references unknown check '%s'", fl.FieldName(), name))
+		}
+	}
+	return checks
+}
+
+var (
+	defaultDisposable     *DomainList
+	defaultDisposableOnce sync.Once
+)
+
+// defaultDisposableList returns a shared list of the embedded disposable domains, for tags selecting the
+// "disposable" check without a list in the options
+func defaultDisposableList() *DomainList {
+	defaultDisposableOnce.Do(func() {
+		defaultDisposable = DisposableEmailDomains()
+	})
+	return defaultDisposable
+}
@@ -0,0 +1,331 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolver is an EmailResolver answering from maps, like a fake DNS server
+type fakeResolver struct {
+	mx      map[string][]*net.MX
+	hosts   map[string][]string
+	err     error         // Returned by every lookup when set
+	delay   time.Duration // Delay of every lookup, interrupted by the context
+	lookups atomic.Int32
+}
+
+func (r *fakeResolver) wait(ctx context.Context) error {
+	r.lookups.Add(1)
+	if r.delay > 0 {
+		select {
+		case <-time.After(r.delay):
+		case <-ctx.Done():
+			return &net.DNSError{Err: ctx.Err().Error(), IsTimeout: true}
+		}
+	}
+	return r.err
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+	if records, ok := r.mx[name]; ok {
+		return records, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func newFakeResolver() *fakeResolver {
+	return &fakeResolver{
+		mx: map[string][]*net.MX{
+			"gmail.com":   {{Host: "gmail-smtp-in.l.google.com.", Pref: 5}},
+			"example.org": {{Host: ".", Pref: 0}}, // Null MX
+		},
+		hosts: map[string][]string{
+			"example.com": {"93.184.215.14"},
+		},
+	}
+}
+
+func TestSuggestEmailDomain(t *testing.T) {
+	tests := []struct {
+		domain     string
+		suggestion string
+	}{
+		{"gmial.com", "gmail.com"},
+		{"gmail.con", "gmail.com"},
+		{"GMAIL.CMO", "gmail.com"},
+		{"hotmial.com", "hotmail.com"},
+		{"hotmial.con", "hotmail.com"},
+		{"yaho.com", "yahoo.com"},
+		{"outlok.com", "outlook.com"},
+		{"gmail.com", ""},
+		{"mail.com", ""},
+		{"example.com", ""},
+		{"acme.io", ""},
+		{"gmail", ""},
+
+		// Country code TLDs are real domains
+		{"yahoo.ca", ""},
+		{"hotmail.ca", ""},
+		{"live.ca", ""},
+		{"gmail.co", ""},
+		{"hotmail.fr", ""},
+		{"yahoo.com.br", ""},
+		{"live.co.za", ""},
+		{"gmial.ca", ""},
+	}
+
+	for _, tt := range tests {
+		suggestion, ok := SuggestEmailDomain(tt.domain)
+		assert.Equal(t, tt.suggestion, suggestion, tt.domain)
+		assert.Equal(t, tt.suggestion != "", ok, tt.domain)
+	}
+
+	suggestion, ok := SuggestEmailDomain("acme.cmo", "acme.com")
+	assert.True(t, ok)
+	assert.Equal(t, "acme.com", suggestion)
+}
+
+func TestDomainList(t *testing.T) {
+	list := NewDomainList("Mailinator.com", "trashmail.de.")
+	assert.Equal(t, 2, list.Len())
+	assert.True(t, list.Contains("mailinator.com"))
+	assert.True(t, list.Contains("EU.Mailinator.com"))
+	assert.True(t, list.Contains("trashmail.de"))
+	assert.False(t, list.Contains("notmailinator.com"))
+	assert.False(t, list.Contains("com"))
+
+	list.Add("spam.example")
+	assert.True(t, list.Contains("spam.example"))
+	list.Remove("spam.example")
+	assert.False(t, list.Contains("spam.example"))
+
+	list, err := ReadDomainList(strings.NewReader("# Disposable domains\nexample.net\n\n  example.info  \n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, list.Len())
+	assert.True(t, list.Contains("example.info"))
+
+	var nilList *DomainList
+	assert.False(t, nilList.Contains("mailinator.com"))
+	assert.Equal(t, 0, nilList.Len())
+
+	t.Run("Embedded list", func(t *testing.T) {
+		list := DisposableEmailDomains()
+		assert.Greater(t, list.Len(), 50)
+		assert.True(t, list.Contains("mailinator.com"))
+		assert.True(t, list.Contains("guerrillamail.com"))
+		assert.False(t, list.Contains("gmail.com"))
+
+		// Lists are separate
+		list.Add("custom.example")
+		assert.False(t, DisposableEmailDomains().Contains("custom.example"))
+	})
+}
+
+func TestEmailDeliverableValidation(t *testing.T) {
+	type Signup struct {
+		Email string `json:"email" validate:"email_deliverable"`
+	}
+
+	newValidator := func(options EmailOptions) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddEmailDeliverableValidation(v, options))
+		return v
+	}
+
+	defaultOptions := func(resolver EmailResolver) EmailOptions {
+		options := DefaultEmailOptions()
+		options.Resolver = resolver
+		return options
+	}
+
+	reason := func(err error) string {
+		if errs, ok := err.(validator.ValidationErrors); ok {
+			return errs[0].Reason
+		}
+		return ""
+	}
+
+	t.Run("Deliverable addresses", func(t *testing.T) {
+		v := newValidator(defaultOptions(newFakeResolver()))
+		assert.NoError(t, v.Validate(Signup{Email: "jane@gmail.com"}))
+		assert.NoError(t, v.Validate(Signup{Email: "jane@Example.com"})) // A record without MX
+	})
+
+	t.Run("Reasons", func(t *testing.T) {
+		v := newValidator(defaultOptions(newFakeResolver()))
+
+		tests := []struct {
+			email  string
+			reason string
+		}{
+			{"not an email", EmailReasonFormat},
+			{"Jane <jane@gmail.com>", EmailReasonFormat},
+			{"user@localhost", EmailReasonFQDN},
+			{"user@[127.0.0.1]", EmailReasonFQDN},
+			{"user@10.0.0.1", EmailReasonFQDN},
+			{"user@example.123", EmailReasonFQDN},
+			{"user@mailinator.com", EmailReasonDisposable},
+			{"user@eu.mailinator.com", EmailReasonDisposable},
+			{"user@example.org", EmailReasonNoMX},
+			{"user@does-not-exist.example", EmailReasonNoMX},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.reason, reason(v.Validate(Signup{Email: tt.email})), tt.email)
+		}
+	})
+
+	t.Run("Typo suggestion", func(t *testing.T) {
+		v := newValidator(defaultOptions(newFakeResolver()))
+
+		errs := v.Validate(Signup{Email: "jane@gmial.com"}).(validator.ValidationErrors)
+		assert.Equal(t, EmailReasonNoMX, errs[0].Reason)
+		assert.Equal(t, "gmial.com", errs[0].Params["domain"])
+		assert.Equal(t, "gmail.com", errs[0].Params["suggestion"])
+		assert.Equal(t, "jane@gmail.com", errs[0].Params["suggested_email"])
+
+		options := defaultOptions(newFakeResolver())
+		options.RejectTypos = true
+		v = newValidator(options)
+		v.SetDefaultTagMessage("email_deliverable.typo", "Did you mean {suggested_email}?")
+
+		errs = v.Validate(Signup{Email: "jane@gmial.com"}).(validator.ValidationErrors)
+		assert.Equal(t, EmailReasonTypo, errs[0].Reason)
+		assert.Equal(t, "Did you mean jane@gmail.com?", errs[0].Message)
+
+		// Canadian domains of common providers are not typos
+		resolver := newFakeResolver()
+		for _, domain := range []string{"yahoo.ca", "hotmail.ca", "live.ca"} {
+			resolver.mx[domain] = []*net.MX{{Host: "mx." + domain + ".", Pref: 10}}
+		}
+		options = defaultOptions(resolver)
+		options.RejectTypos = true
+		v = newValidator(options)
+		assert.NoError(t, v.Validate(Signup{Email: "jane@yahoo.ca"}))
+		assert.NoError(t, v.Validate(Signup{Email: "jane@hotmail.ca"}))
+		assert.NoError(t, v.Validate(Signup{Email: "jane@live.ca"}))
+	})
+
+	t.Run("Lookup failures", func(t *testing.T) {
+		resolver := newFakeResolver()
+		resolver.err = errors.New("connection refused")
+
+		v := newValidator(defaultOptions(resolver))
+		assert.NoError(t, v.Validate(Signup{Email: "jane@gmail.com"}))
+
+		options := defaultOptions(resolver)
+		options.FailClosed = true
+		v = newValidator(options)
+		assert.Equal(t, EmailReasonDNSCheckFailed, reason(v.Validate(Signup{Email: "jane@gmail.com"})))
+	})
+
+	t.Run("Deadlines", func(t *testing.T) {
+		resolver := newFakeResolver()
+		resolver.delay = time.Second
+
+		options := defaultOptions(resolver)
+		options.FailClosed = true
+		v := newValidator(options)
+
+		// The deadline of ValidateCtx ends the lookup
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		assert.Equal(t, EmailReasonDNSCheckFailed, reason(v.ValidateCtx(ctx, Signup{Email: "jane@gmail.com"})))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+		// So does the lookup timeout
+		options.LookupTimeout = 20 * time.Millisecond
+		v = newValidator(options)
+		start = time.Now()
+		assert.Equal(t, EmailReasonDNSCheckFailed, reason(v.Validate(Signup{Email: "jane@gmail.com"})))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Checks selected by the param", func(t *testing.T) {
+		type Import struct {
+			Email string `json:"email" validate:"email_deliverable=fqdn disposable"`
+		}
+
+		resolver := newFakeResolver()
+		v := newValidator(EmailOptions{Resolver: resolver})
+
+		assert.NoError(t, v.Validate(Import{Email: "user@does-not-exist.example"}))
+		assert.Equal(t, int32(0), resolver.lookups.Load())
+		assert.Equal(t, EmailReasonFQDN, reason(v.Validate(Import{Email: "user@localhost"})))
+		assert.Equal(t, EmailReasonDisposable, reason(v.Validate(Import{Email: "user@yopmail.com"})))
+
+		// Without param, only the checks enabled in the options run
+		resolver.mx["yopmail.com"] = []*net.MX{{Host: "mx.yopmail.com.", Pref: 1}}
+		assert.NoError(t, v.Validate(Signup{Email: "user@yopmail.com"}))
+		assert.Equal(t, EmailReasonNoMX, reason(v.Validate(Signup{Email: "user@does-not-exist.example"})))
+	})
+
+	t.Run("Updated disposable list", func(t *testing.T) {
+		options := defaultOptions(newFakeResolver())
+		v := newValidator(options)
+
+		assert.NoError(t, v.Validate(Signup{Email: "jane@gmail.com"}))
+		options.Disposable.Add("gmail.com")
+		assert.Equal(t, EmailReasonDisposable, reason(v.Validate(Signup{Email: "jane@gmail.com"})))
+	})
+
+	t.Run("Async", func(t *testing.T) {
+		type Invite struct {
+			Emails []string `json:"emails" validate:"dive,email_deliverable"`
+		}
+
+		resolver := newFakeResolver()
+		resolver.delay = 50 * time.Millisecond
+		options := defaultOptions(resolver)
+		options.Async = true
+		v := newValidator(options)
+
+		emails := []string{"a@gmail.com", "b@example.org", "c@gmail.com", "d@mailinator.com", "e@gmail.com", "f@example.org"}
+		start := time.Now()
+		errs := v.Validate(Invite{Emails: emails}).(validator.ValidationErrors)
+		assert.Less(t, time.Since(start), 200*time.Millisecond) // 250ms when run serially
+
+		assert.Len(t, errs, 3)
+		assert.Equal(t, "emails[1]", errs[0].Path)
+		assert.Equal(t, EmailReasonNoMX, errs[0].Reason)
+		assert.Equal(t, "emails[3]", errs[1].Path)
+		assert.Equal(t, EmailReasonDisposable, errs[1].Reason)
+		assert.Equal(t, "emails[5]", errs[2].Path)
+
+		// Params are still checked before the lookups
+		type Invalid struct {
+			Email string `validate:"email_deliverable=smtp"`
+		}
+		assert.Panics(t, func() { _ = v.Validate(Invalid{Email: "jane@gmail.com"}) })
+	})
+
+	t.Run("Unknown check panics", func(t *testing.T) {
+		type Invalid struct {
+			Email string `validate:"email_deliverable=smtp"`
+		}
+		v := newValidator(EmailOptions{})
+		assert.Panics(t, func() { _ = v.Validate(Invalid{Email: "jane@gmail.com"}) })
+	})
+}
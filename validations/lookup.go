@@ -0,0 +1,135 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// Reasons reported by the "unique_in" and "exists" validations
+const (
+	LookupReasonTaken    = "taken"     // A record with the value already exists
+	LookupReasonNotFound = "not_found" // No record with the value exists
+)
+
+// LookupRepository looks up values in the data store of an application for the "unique_in" and "exists"
+// validations, e.g. with a SQL query such as "SELECT column FROM table WHERE column IN (...)".
+type LookupRepository interface {
+	// Exists reports, for each value, whether a record of table has the value in column. The result must be
+	// in the order of values. ctx is the context passed to ValidateCtx.
+	Exists(ctx context.Context, table, column string, values []interface{}) ([]bool, error)
+}
+
+// LookupFunc is a function implementing LookupRepository
+type LookupFunc func(ctx context.Context, table, column string, values []interface{}) ([]bool, error)
+
+// Exists implements LookupRepository
+func (f LookupFunc) Exists(ctx context.Context, table, column string, values []interface{}) ([]bool, error) {
+	return f(ctx, table, column, values)
+}
+
+// AddLookupValidations registers the "unique_in" and "exists" tags, which check values against the records
+// of repo. The param is the table and the column to look up, separated by a dot:
+//
+//	type SignupRequest struct {
+//	    Username string `validate:"required,min=3,max=32,unique_in=users.username"`
+//	    TeamID   int64  `validate:"required,exists=teams.id"`
+//	}
+//
+//	type OrderRequest struct {
+//	    Items []struct {
+//	        ProductID int64 `validate:"required,exists=products.id"`
+//	    } `validate:"required,dive"`
+//	}
+//
+// The lookups are deferred with validator.DeferValidation: they run once every other validation of the
+// struct is done, and only for the values that passed the tags before them, so these tags must be last.
+// The values of all the fields with the same param are looked up in a single call of repo, without
+// duplicates, e.g. every product ID of an order. repo gets the context passed to ValidateCtx, and its
+// errors are returned by ValidateCtx instead of ValidationErrors. In an OR group, e.g.
+// `validate:"exists=teams.id|eq=0"`, the lookup only runs when the other tags of the group fail.
+//
+// Failing values are reported with the constraint "unique_in" or "exists" and the reason "taken" or
+// "not_found". Messages can use the {table} and {column} params. A param that is not a table and a column
+// panics.
+//
+// The "unique" tag of go-playground, which checks that the elements of a slice, an array or a map are
+// unique, is left as is.
+func AddLookupValidations(v *validator.Validator, repo LookupRepository) error {
+	unique := lookupDeferredFunc(repo, "unique_in", false)
+	err := v.BaseValidator.RegisterValidationCtx("unique_in", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+		checkLookupParam(fl)
+		return validator.DeferValidation(ctx, fl, unique)
+	})
+	if err != nil {
+		return err
+	}
+
+	exists := lookupDeferredFunc(repo, "exists", true)
+	return v.BaseValidator.RegisterValidationCtx("exists", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+		checkLookupParam(fl)
+		return validator.DeferValidation(ctx, fl, exists)
+	})
+}
+
+// lookupDeferredFunc returns the DeferredFunc of a lookup tag, which fails values that exist, or values
+// that do not exist if want is true
+func lookupDeferredFunc(repo LookupRepository, tag string, want bool) validator.DeferredFunc {
+	return func(ctx context.Context, param string, values []interface{}) ([][]validator.Violation, error) {
+		table, column, _ := strings.Cut(param, ".")
+
+		// Each distinct value is looked up once
+		distinct := make([]interface{}, 0, len(values))
+		positions := make([]int, len(values))
+		seen := make(map[interface{}]int)
+		for i, value := range values {
+			if value != nil && reflect.TypeOf(value).Comparable() {
+				if j, ok := seen[value]; ok {
+					positions[i] = j
+					continue
+				}
+				seen[value] = len(distinct)
+			}
+			positions[i] = len(distinct)
+			distinct = append(distinct, value)
+		}
+
+		found, err := repo.Exists(ctx, table, column, distinct)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) != len(distinct) {
+			return nil, fmt.Errorf("validations: %s lookup of %s returned %d results for %d values", tag, param, len(found), len(distinct))
+		}
+
+		results := make([][]validator.Violation, len(values))
+		for i := range values {
+			if found[positions[i]] == want {
+				continue
+			}
+			violation := validator.Violation{
+				Reason:  LookupReasonTaken,
+				Message: "{field} is already taken",
+				Params:  map[string]interface{}{"table": table, "column": column},
+			}
+			if want {
+				violation.Reason = LookupReasonNotFound
+				violation.Message = "{field} must reference an existing record"
+			}
+			results[i] = []validator.Violation{violation}
+		}
+		return results, nil
+	}
+}
+
+// checkLookupParam panics if the param of a lookup tag is not a table and a column
+func checkLookupParam(fl govalidator.FieldLevel) {
+	table, column, ok := strings.Cut(fl.Param(), ".")
+	if !ok || table == "" || column == "" {
+		panic(fmt.Sprintf("validations: %s on field '%s' must reference a table and a column, e.g. %s=users.id, got '%s'", fl.GetTag(), fl.FieldName(), fl.GetTag(), fl.Param()))
+	}
+}
@@ -0,0 +1,177 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepository is a LookupRepository storing the values of each "table.column"
+type fakeRepository struct {
+	records map[string][]interface{}
+	calls   []string
+}
+
+func (r *fakeRepository) Exists(ctx context.Context, table, column string, values []interface{}) ([]bool, error) {
+	r.calls = append(r.calls, table+"."+column)
+	found := make([]bool, len(values))
+	for i, value := range values {
+		for _, record := range r.records[table+"."+column] {
+			found[i] = found[i] || record == value
+		}
+	}
+	return found, nil
+}
+
+func TestLookupValidations(t *testing.T) {
+	type Item struct {
+		ProductID int64 `json:"product_id" validate:"required,exists=products.id"`
+	}
+	type Signup struct {
+		Username string   `json:"username" validate:"required,max=16,unique_in=users.username"`
+		TeamID   int64    `json:"team_id" validate:"required,exists=teams.id"`
+		Items    []Item   `json:"items" validate:"dive"`
+		Tags     []string `json:"tags" validate:"unique"`
+	}
+
+	newRepository := func() *fakeRepository {
+		return &fakeRepository{records: map[string][]interface{}{
+			"users.username": {"jane", "john"},
+			"teams.id":       {int64(1), int64(2)},
+			"products.id":    {int64(10), int64(11), int64(12)},
+		}}
+	}
+
+	newValidator := func(repo LookupRepository) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddLookupValidations(v, repo))
+		return v
+	}
+
+	t.Run("Valid values", func(t *testing.T) {
+		repo := newRepository()
+		v := newValidator(repo)
+
+		err := v.Validate(Signup{
+			Username: "alice",
+			TeamID:   1,
+			Items:    []Item{{ProductID: 10}, {ProductID: 12}},
+			Tags:     []string{"a", "b"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"users.username", "teams.id", "products.id"}, repo.calls)
+	})
+
+	t.Run("Errors with paths", func(t *testing.T) {
+		repo := newRepository()
+		v := newValidator(repo)
+
+		errs := v.Validate(Signup{
+			Username: "jane",
+			TeamID:   3,
+			Items:    []Item{{ProductID: 10}, {ProductID: 99}, {ProductID: 99}},
+		}).(validator.ValidationErrors)
+
+		assert.Len(t, errs, 4)
+		assert.Equal(t, "username", errs[0].Path)
+		assert.Equal(t, "unique_in", errs[0].Constraint)
+		assert.Equal(t, LookupReasonTaken, errs[0].Reason)
+		assert.Equal(t, "username is already taken", errs[0].Message)
+
+		assert.Equal(t, "team_id", errs[1].Path)
+		assert.Equal(t, "exists", errs[1].Constraint)
+		assert.Equal(t, LookupReasonNotFound, errs[1].Reason)
+		assert.Equal(t, "teams", errs[1].Params["table"])
+		assert.Equal(t, "id", errs[1].Params["column"])
+
+		assert.Equal(t, "items[1].product_id", errs[2].Path)
+		assert.Equal(t, "items[2].product_id", errs[3].Path)
+	})
+
+	t.Run("Batched lookups", func(t *testing.T) {
+		var batches [][]interface{}
+		repo := LookupFunc(func(ctx context.Context, table, column string, values []interface{}) ([]bool, error) {
+			batches = append(batches, values)
+			return make([]bool, len(values)), nil
+		})
+		v := newValidator(repo)
+
+		type Order struct {
+			Items []Item `json:"items" validate:"dive"`
+			Extra int64  `json:"extra" validate:"omitempty,exists=products.id"`
+		}
+		errs := v.Validate(Order{Items: []Item{{ProductID: 5}, {ProductID: 6}, {ProductID: 5}}, Extra: 7}).(validator.ValidationErrors)
+
+		// Every product ID in one call, without duplicates
+		assert.Equal(t, [][]interface{}{{int64(5), int64(6), int64(7)}}, batches)
+		assert.Len(t, errs, 4)
+	})
+
+	t.Run("Lookups run after cheap checks", func(t *testing.T) {
+		repo := newRepository()
+		v := newValidator(repo)
+
+		errs := v.Validate(Signup{Username: "a-very-long-username", TeamID: 0}).(validator.ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "max", errs[0].Constraint)
+		assert.Equal(t, "required", errs[1].Constraint)
+		assert.Empty(t, repo.calls)
+	})
+
+	t.Run("Context and errors", func(t *testing.T) {
+		type ctxKey struct{}
+		lookupErr := errors.New("database is down")
+
+		v := newValidator(LookupFunc(func(ctx context.Context, table, column string, values []interface{}) ([]bool, error) {
+			assert.Equal(t, "request-1", ctx.Value(ctxKey{}))
+			return nil, lookupErr
+		}))
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+		err := v.ValidateCtx(ctx, Signup{Username: "alice", TeamID: 1})
+		assert.ErrorIs(t, err, lookupErr)
+	})
+
+	t.Run("Built-in unique is left as is", func(t *testing.T) {
+		v := newValidator(newRepository())
+
+		errs := v.Validate(Signup{Username: "alice", TeamID: 1, Tags: []string{"a", "a"}}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "tags", errs[0].Path)
+		assert.Equal(t, "unique", errs[0].Constraint)
+		assert.Empty(t, errs[0].Reason)
+
+		type Team struct {
+			Members []Item `validate:"unique=ProductID"`
+		}
+		assert.NoError(t, v.Validate(Team{Members: []Item{{ProductID: 1}, {ProductID: 2}}}))
+		assert.Error(t, v.Validate(Team{Members: []Item{{ProductID: 1}, {ProductID: 1}}}))
+	})
+
+	t.Run("OR groups", func(t *testing.T) {
+		type Transfer struct {
+			TeamID int64 `json:"team_id" validate:"exists=teams.id|eq=0"`
+		}
+		v := newValidator(newRepository())
+
+		assert.NoError(t, v.Validate(Transfer{TeamID: 1}))
+		assert.NoError(t, v.Validate(Transfer{TeamID: 0}))
+
+		errs := v.Validate(Transfer{TeamID: 3}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "exists", errs[0].Constraint)
+		assert.Equal(t, LookupReasonNotFound, errs[0].Reason)
+	})
+
+	t.Run("Invalid param panics", func(t *testing.T) {
+		type Invalid struct {
+			TeamID int64 `validate:"exists=teams"`
+		}
+		v := newValidator(newRepository())
+		assert.Panics(t, func() { _ = v.Validate(Invalid{TeamID: 1}) })
+	})
+}
@@ -1,22 +1,44 @@
 package validations
 
 import (
+	"context"
 	"fmt"
-	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
-	govalidator "github.com/go-playground/validator/v10"
 	"github.com/juancwu/go-valkit/v2/validator"
+	"golang.org/x/text/unicode/norm"
 )
 
 // PasswordOptions holds configuration for password validation requirements
 type PasswordOptions struct {
-	MinLength          int  // Minimum length of password
-	RequireUppercase   bool // Requires at least one uppercase letter
-	RequireLowercase   bool // Requires at least one lowercase letter
-	RequireDigit       bool // Requires at least one digit
-	RequireSpecialChar bool // Requires at least one special character
+	MinLength          int           // Minimum length of password in characters
+	MaxLength          int           // Maximum length of password in characters, 0 means no limit
+	MaxBytes           int           // Maximum length of password in bytes, e.g. BcryptMaxBytes. 0 means no limit
+	Normalize          bool          // Applies Unicode NFKC normalization before the checks
+	RequireLetter      bool          // Requires at least one letter, of any case
+	RequireUppercase   bool          // Requires at least one uppercase letter
+	RequireLowercase   bool          // Requires at least one lowercase letter
+	RequireDigit       bool          // Requires at least one digit
+	RequireSpecialChar bool          // Requires at least one special character
+	MinLetters         int           // Minimum number of letters, of any case
+	MinUppercase       int           // Minimum number of uppercase letters
+	MinLowercase       int           // Minimum number of lowercase letters
+	MinDigits          int           // Minimum number of digits
+	MinSpecialChars    int           // Minimum number of special characters
+	SpecialChars       string        // Characters counted as special. Empty means any Unicode punctuation or symbol
+	MaxRepeatedChars   int           // Maximum number of identical characters in a row, 0 means no limit
+	MinScore           int           // Minimum strength score from 0 to 4, see EstimatePasswordStrength. 0 disables the check
+	Blocklist          *PasswordList // Rejects passwords in the list, e.g. CommonPasswords(1000). nil disables the check
+	BreachCheck        *BreachCheck  // Rejects passwords found in data breaches by a range API. nil disables the check
 }
 
+// BcryptMaxBytes is the number of bytes of a password that bcrypt uses; longer passwords are
+// silently truncated, so two passwords sharing their first 72 bytes have the same hash.
+// Set PasswordOptions.MaxBytes to it when hashing passwords with bcrypt.
+const BcryptMaxBytes = 72
+
 // PasswordOption is a function that configures PasswordOptions
 type PasswordOption func(*PasswordOptions)
 
@@ -31,25 +53,284 @@ func DefaultPasswordOptions() PasswordOptions {
 	}
 }
 
+// Reasons reported by the password validation, one per failed requirement.
+// Messages for a single requirement can be customized with the "password.{reason}" key,
+// e.g. v.SetDefaultTagMessage("password.digit", "Add a number to your password").
+const (
+	PasswordReasonMinLength = "min_length"
+	PasswordReasonMaxLength = "max_length"
+	PasswordReasonMaxBytes  = "max_bytes"
+	PasswordReasonLetter    = "letter"
+	PasswordReasonUppercase = "uppercase"
+	PasswordReasonLowercase = "lowercase"
+	PasswordReasonDigit     = "digit"
+	PasswordReasonSpecial   = "special"
+	PasswordReasonRepeated  = "repeated"
+	PasswordReasonStrength  = "strength"
+	PasswordReasonCommon    = "common"
+	PasswordReasonBreached  = "breached"
+	// PasswordReasonBreachCheckFailed is reported when the breach check fails and BreachCheck.FailClosed is set
+	PasswordReasonBreachCheckFailed = "breach_check_failed"
+)
+
+// passwordCharClass is a class of characters that a policy can require a minimum number of
+type passwordCharClass struct {
+	reason   string          // Reason reported when the password has too few characters of the class
+	param    string          // Name of the param holding the minimum
+	singular string          // Name of a character of the class, e.g. "uppercase letter"
+	plural   string          // Name of several characters of the class, e.g. "uppercase letters"
+	min      int             // Minimum number of characters of the class
+	matches  func(rune) bool // Reports whether a character belongs to the class
+}
+
+// passwordCharClasses returns the character classes of options, in the order they are checked.
+// Letters, digits and cases follow the Unicode categories, so "É" is an uppercase letter and "٣" a digit.
+func passwordCharClasses(options PasswordOptions) []passwordCharClass {
+	isSpecial := func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) }
+	if options.SpecialChars != "" {
+		isSpecial = func(r rune) bool { return strings.ContainsRune(options.SpecialChars, r) }
+	}
+
+	return []passwordCharClass{
+		{PasswordReasonLetter, "min_letters", "letter", "letters", minCharCount(options.RequireLetter, options.MinLetters), unicode.IsLetter},
+		{PasswordReasonUppercase, "min_uppercase", "uppercase letter", "uppercase letters", minCharCount(options.RequireUppercase, options.MinUppercase), unicode.IsUpper},
+		{PasswordReasonLowercase, "min_lowercase", "lowercase letter", "lowercase letters", minCharCount(options.RequireLowercase, options.MinLowercase), unicode.IsLower},
+		{PasswordReasonDigit, "min_digits", "number", "numbers", minCharCount(options.RequireDigit, options.MinDigits), unicode.IsDigit},
+		{PasswordReasonSpecial, "min_special", "special character", "special characters", minCharCount(options.RequireSpecialChar, options.MinSpecialChars), isSpecial},
+	}
+}
+
+// minCharCount combines a Require* option with its Min* option
+func minCharCount(required bool, min int) int {
+	if required && min < 1 {
+		return 1
+	}
+	return min
+}
+
+// describe returns the requirement of the class, e.g. "at least one number" or "at least 2 numbers"
+func (c passwordCharClass) describe() string {
+	if c.min == 1 {
+		return "at least one " + c.singular
+	}
+	return fmt.Sprintf("at least %d %s", c.min, c.plural)
+}
+
+// count returns the number of characters of password in the class
+func (c passwordCharClass) count(password string) int {
+	n := 0
+	for _, r := range password {
+		if c.matches(r) {
+			n++
+		}
+	}
+	return n
+}
+
 // AddPasswordValidation registers password validation with the validator
 // It adds a custom validation tag "password" that can be used in struct tags
 // Example: `validate:"password"`
+//
+// The options are registered as the DefaultPasswordPolicy; use AddPasswordPolicy to register
+// other named policies selectable with `validate:"password={name}"`.
+//
+// Every requirement the password does not meet is reported as its own ValidationError with
+// the constraint "password" and one of the PasswordReason* values as reason, so a UI can tick
+// off a requirements checklist. Each error also has these params available in messages:
+//   - {min}: the minimum length
+//   - {max}: the maximum length
+//   - {max_bytes}: the maximum length in bytes
+//   - {min_letters}, {min_uppercase}, {min_lowercase}, {min_digits}, {min_special}: the minimum number
+//     of characters of each class
+//   - {max_repeated}: the maximum number of identical characters in a row
+//   - {requirements}: a sentence describing all requirements of the policy
+//   - {policy}: the name of the policy
+//
+// When MinScore is set, the "strength" reason also has these params:
+//   - {score}: the strength score of the password
+//   - {min_score}: the minimum score of the policy
+//   - {warning}: what makes the password easy to guess, may be empty
+//   - {suggestions}: hints to pick a stronger password, in a single sentence
+//
+// When BreachCheck is set, the password is looked up with the context passed to ValidateCtx once it
+// meets every other requirement, and the "breached" reason has the {count} param.
 func AddPasswordValidation(v *validator.Validator, options PasswordOptions) error {
-	// Build error message based on requirements
-	errorMsg := fmt.Sprintf("Password must be at least %d characters", options.MinLength)
-	requirements := []string{}
+	return AddPasswordPolicy(v, DefaultPasswordPolicy, options)
+}
 
-	if options.RequireUppercase {
-		requirements = append(requirements, "at least one uppercase letter")
+// checkPassword returns a violation for every requirement of the policy that password does not meet
+func checkPassword(ctx context.Context, password string, policy string, options PasswordOptions) []validator.Violation {
+	if options.Normalize {
+		password = NormalizePassword(password)
 	}
-	if options.RequireLowercase {
-		requirements = append(requirements, "at least one lowercase letter")
+
+	var violations []validator.Violation
+	var params map[string]interface{}
+	for _, rule := range evaluatePasswordRules(password, policy, options) {
+		params = rule.params
+		if !rule.passed {
+			violations = append(violations, rule.violation())
+		}
 	}
-	if options.RequireDigit {
-		requirements = append(requirements, "at least one number")
+
+	// Check breaches last, and only for passwords that are otherwise valid, to avoid needless requests
+	if options.BreachCheck != nil && len(violations) == 0 {
+		count, err := options.BreachCheck.check(ctx, password)
+		switch {
+		case err != nil && options.BreachCheck.FailClosed:
+			violations = append(violations, validator.Violation{
+				Reason:  PasswordReasonBreachCheckFailed,
+				Message: "Password could not be checked, please try again later",
+				Params:  params,
+			})
+		case err == nil && count >= options.BreachCheck.minCount():
+			violations = append(violations, validator.Violation{
+				Reason:  PasswordReasonBreached,
+				Message: "Password has appeared in a data breach",
+				Params:  extendParams(params, map[string]interface{}{"count": count}),
+			})
+		}
 	}
-	if options.RequireSpecialChar {
-		requirements = append(requirements, "at least one special character")
+
+	return violations
+}
+
+// passwordRule is the result of checking a password against one requirement of a policy
+type passwordRule struct {
+	reason  string                 // One of the PasswordReason* values
+	message string                 // Default message, describing the requirement
+	params  map[string]interface{} // Params available in messages
+	passed  bool                   // Whether the password meets the requirement
+}
+
+// violation returns the violation reported when the rule does not pass
+func (r passwordRule) violation() validator.Violation {
+	return validator.Violation{Reason: r.reason, Message: r.message, Params: r.params}
+}
+
+// evaluatePasswordRules checks an already normalized password against every local requirement of the
+// policy, in the order they are reported. Remote checks such as the BreachCheck are not included.
+func evaluatePasswordRules(password string, policy string, options PasswordOptions) []passwordRule {
+	params := map[string]interface{}{
+		"min":          options.MinLength,
+		"max":          options.MaxLength,
+		"max_bytes":    options.MaxBytes,
+		"max_repeated": options.MaxRepeatedChars,
+		"requirements": passwordRequirements(options),
+		"policy":       policy,
+	}
+	classes := passwordCharClasses(options)
+	for _, class := range classes {
+		params[class.param] = class.min
+	}
+
+	var rules []passwordRule
+	check := func(reason, message string, passed bool) {
+		rules = append(rules, passwordRule{reason: reason, message: message, params: params, passed: passed})
+	}
+
+	// Check length
+	length := utf8.RuneCountInString(password)
+	check(PasswordReasonMinLength, "Password must be at least {min} characters", length >= options.MinLength)
+	if options.MaxLength > 0 {
+		check(PasswordReasonMaxLength, "Password must be at most {max} characters", length <= options.MaxLength)
+	}
+	if options.MaxBytes > 0 {
+		check(PasswordReasonMaxBytes, "Password is too long", len(password) <= options.MaxBytes)
+	}
+
+	// Check for required character types
+	for _, class := range classes {
+		if class.min <= 0 {
+			continue
+		}
+		passed := class.count(password) >= class.min
+		if class.min == 1 {
+			check(class.reason, "Password must contain at least one "+class.singular, passed)
+		} else {
+			check(class.reason, "Password must contain at least {"+class.param+"} "+class.plural, passed)
+		}
+	}
+
+	// Check repeated characters
+	if options.MaxRepeatedChars > 0 {
+		check(PasswordReasonRepeated, "Password cannot contain the same character more than {max_repeated} times in a row",
+			longestRun(password) <= options.MaxRepeatedChars)
+	}
+
+	// Check blocklist
+	if options.Blocklist != nil {
+		check(PasswordReasonCommon, "Password is too common", !options.Blocklist.Contains(password))
+	}
+
+	// Check strength
+	if options.MinScore > 0 {
+		strength := EstimatePasswordStrength(password)
+		rules = append(rules, passwordRule{
+			reason:  PasswordReasonStrength,
+			message: "Password is too easy to guess",
+			params: extendParams(params, map[string]interface{}{
+				"score":       strength.Score,
+				"min_score":   options.MinScore,
+				"warning":     strength.Warning,
+				"suggestions": strings.Join(strength.Suggestions, " "),
+			}),
+			passed: strength.Score >= options.MinScore,
+		})
+	}
+
+	return rules
+}
+
+// NormalizePassword applies Unicode NFKC normalization to password, so that equivalent ways of
+// typing the same characters compare equal. When PasswordOptions.Normalize is set, passwords are
+// validated in this form, and should be hashed in this form too.
+func NormalizePassword(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// longestRun returns the length of the longest run of identical characters in password
+func longestRun(password string) int {
+	longest, run := 0, 0
+	var previous rune
+	for i, r := range []rune(password) {
+		if i > 0 && r == previous {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		previous = r
+	}
+	return longest
+}
+
+// extendParams returns a copy of params with the extra params added
+func extendParams(params map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+	extended := make(map[string]interface{}, len(params)+len(extra))
+	for key, value := range params {
+		extended[key] = value
+	}
+	for key, value := range extra {
+		extended[key] = value
+	}
+	return extended
+}
+
+// passwordRequirements builds a sentence describing all the requirements of options
+func passwordRequirements(options PasswordOptions) string {
+	errorMsg := fmt.Sprintf("Password must be at least %d characters", options.MinLength)
+	if options.MaxLength > 0 {
+		errorMsg = fmt.Sprintf("Password must be at least %d and at most %d characters", options.MinLength, options.MaxLength)
+	}
+	requirements := []string{}
+
+	for _, class := range passwordCharClasses(options) {
+		if class.min > 0 {
+			requirements = append(requirements, class.describe())
+		}
 	}
 
 	// Format the error message with all requirements
@@ -68,29 +349,5 @@ func AddPasswordValidation(v *validator.Validator, options PasswordOptions) erro
 		}
 	}
 
-	// Register the password validation function
-	err := v.RegisterValidation("password", func(fl govalidator.FieldLevel) bool {
-		password := fl.Field().String()
-
-		// Check length
-		if len(password) < options.MinLength {
-			return false
-		}
-
-		// Check for required character types
-		hasUpper := !options.RequireUppercase || regexp.MustCompile(`[A-Z]`).MatchString(password)
-		hasLower := !options.RequireLowercase || regexp.MustCompile(`[a-z]`).MatchString(password)
-		hasDigit := !options.RequireDigit || regexp.MustCompile(`[0-9]`).MatchString(password)
-		hasSpecial := !options.RequireSpecialChar || regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{};':"\\|,.<>\/?]`).MatchString(password)
-
-		return hasUpper && hasLower && hasDigit && hasSpecial
-	})
-	if err != nil {
-		return err
-	}
-
-	// Set default error message for the password validation tag
-	v.SetDefaultTagMessage("password", errorMsg)
-
-	return nil
+	return errorMsg
 }
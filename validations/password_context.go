@@ -0,0 +1,184 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// DefaultPasswordMinOverlap is the minimum number of characters a password must share with another
+// field to be rejected by the "password_notcontains" validation, when no other value is given
+const DefaultPasswordMinOverlap = 4
+
+// maxPasswordContextLength is the number of characters of a password and of the other fields that are compared
+const maxPasswordContextLength = 256
+
+// AddPasswordNotContainsValidation registers the "password_notcontains" tag, which rejects passwords that
+// contain part of other fields of the same struct, such as the username, email or display name.
+// The other fields are referenced like eqfield does, separated by spaces:
+//
+//	type Signup struct {
+//	    Username    string `validate:"required"`
+//	    Email       string `validate:"required,email"`
+//	    DisplayName string
+//	    Password    string `validate:"password,password_notcontains=Username Email DisplayName"`
+//	}
+//
+// A password is rejected when it shares at least minOverlap consecutive characters with a field, ignoring
+// case; 0 uses DefaultPasswordMinOverlap. A "min=" item in the param sets the minimum of a single field,
+// e.g. `validate:"password_notcontains=Username Email min=3"`. Only the local part of email addresses is
+// compared, and empty fields are ignored. When the field also has a "password" tag whose policy sets
+// Normalize, the password and the other fields are normalized like the password check does.
+//
+// Every conflicting field is reported as its own ValidationError with the constraint "password_notcontains"
+// and the name of the field, as written in the tag, as reason. Messages can use these params:
+//   - {other_field}: the name of the conflicting field
+//   - {min_overlap}: the minimum overlap
+//
+// Messages for a single field can be set with the "password_notcontains.{name}" key, e.g.
+// v.SetDefaultTagMessage("password_notcontains.Email", "Password cannot contain your email").
+//
+// A param without fields, with an invalid minimum or referencing a field that does not exist makes ValidateCtx
+// return an error instead of ValidationErrors, like an unknown password policy.
+func AddPasswordNotContainsValidation(v *validator.Validator, minOverlap int) error {
+	if minOverlap <= 0 {
+		minOverlap = DefaultPasswordMinOverlap
+	}
+
+	return v.RegisterViolationValidation("password_notcontains", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		names, overlap, err := parsePasswordNotContainsParam(fl.Param(), minOverlap)
+		if err != nil {
+			validator.AbortValidation(ctx, fmt.Errorf("validations: password_notcontains on field '%s' %w", fl.FieldName(), err))
+			// The field still fails when the underlying go-playground validator is used directly
+			return []validator.Violation{{}}
+		}
+
+		password := fl.Field().String()
+		options, _ := fieldPasswordPolicy(v, fl)
+		if options.Normalize {
+			password = NormalizePassword(password)
+		}
+
+		var violations []validator.Violation
+		for _, name := range names {
+			other, kind, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), name)
+			if !found {
+				validator.AbortValidation(ctx, fmt.Errorf("validations: password_notcontains on field '%s' references unknown field '%s'", fl.FieldName(), name))
+				return []validator.Violation{{}}
+			}
+			if kind != reflect.String {
+				continue
+			}
+
+			value := other.String()
+			if options.Normalize {
+				value = NormalizePassword(value)
+			}
+
+			if passwordOverlap(password, value) >= overlap {
+				violations = append(violations, validator.Violation{
+					Reason:  name,
+					Message: "Password is too similar to {other_field}",
+					Params: map[string]interface{}{
+						"other_field": name,
+						"min_overlap": overlap,
+					},
+				})
+			}
+		}
+
+		return violations
+	})
+}
+
+// parsePasswordNotContainsParam returns the fields referenced by the param of a "password_notcontains" tag,
+// and its minimum overlap, which defaults to minOverlap
+func parsePasswordNotContainsParam(param string, minOverlap int) ([]string, int, error) {
+	var names []string
+	for _, item := range strings.Fields(param) {
+		value, ok := strings.CutPrefix(item, "min=")
+		if !ok {
+			names = append(names, item)
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, 0, fmt.Errorf("has an invalid minimum overlap '%s'", value)
+		}
+		minOverlap = n
+	}
+
+	if len(names) == 0 {
+		return nil, 0, errors.New("has no fields")
+	}
+	return names, minOverlap, nil
+}
+
+// fieldPasswordPolicy returns the options of the password policy selected by the "password" tag of the
+// field of fl, if it has one
+func fieldPasswordPolicy(v *validator.Validator, fl govalidator.FieldLevel) (PasswordOptions, bool) {
+	parent := fl.Parent()
+	for parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return PasswordOptions{}, false
+	}
+
+	// Elements of slices and maps are named after their field, e.g. "Passwords[0]"
+	name, _, _ := strings.Cut(fl.StructFieldName(), "[")
+	field, ok := parent.Type().FieldByName(name)
+	if !ok {
+		return PasswordOptions{}, false
+	}
+
+	policies := passwordPolicyNames(field.Tag.Get("validate"))
+	if len(policies) == 0 {
+		return PasswordOptions{}, false
+	}
+	return GetPasswordPolicy(v, policies[0])
+}
+
+// passwordOverlap returns the length of the longest run of characters that password and value have
+// in common, ignoring case. Email addresses are compared by their local part.
+func passwordOverlap(password, value string) int {
+	if at := strings.LastIndex(value, "@"); at > 0 {
+		value = value[:at]
+	}
+
+	a := []rune(strings.ToLower(password))
+	b := []rune(strings.ToLower(strings.TrimSpace(value)))
+	if len(a) > maxPasswordContextLength {
+		a = a[:maxPasswordContextLength]
+	}
+	if len(b) > maxPasswordContextLength {
+		b = b[:maxPasswordContextLength]
+	}
+
+	// Longest common substring, keeping only the previous row of the table
+	longest := 0
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				current[j] = previous[j-1] + 1
+				if current[j] > longest {
+					longest = current[j]
+				}
+			} else {
+				current[j] = 0
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return longest
+}
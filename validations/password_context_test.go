@@ -0,0 +1,144 @@
+package validations
+
+import (
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordNotContains(t *testing.T) {
+	type Profile struct {
+		DisplayName string `json:"display_name"`
+	}
+	type Signup struct {
+		Username string  `json:"username"`
+		Email    string  `json:"email"`
+		Profile  Profile `json:"profile"`
+		Password string  `json:"password" validate:"password_notcontains=Username Email Profile.DisplayName"`
+	}
+
+	newValidator := func(minOverlap int) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPasswordNotContainsValidation(v, minOverlap))
+		return v
+	}
+
+	signup := func(password string) Signup {
+		return Signup{
+			Username: "jsmith",
+			Email:    "Carlos.Rivera@example.com",
+			Profile:  Profile{DisplayName: "Ana Lucía"},
+			Password: password,
+		}
+	}
+
+	t.Run("Unrelated password", func(t *testing.T) {
+		v := newValidator(0)
+		assert.NoError(t, v.Validate(signup("correct horse battery staple")))
+	})
+
+	t.Run("Contains the username ignoring case", func(t *testing.T) {
+		v := newValidator(0)
+
+		errs := v.Validate(signup("MyJSmith2024!")).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "password", errs[0].Path)
+		assert.Equal(t, "password_notcontains", errs[0].Constraint)
+		assert.Equal(t, "Username", errs[0].Reason)
+		assert.Equal(t, "Username", errs[0].Params["other_field"])
+		assert.Equal(t, "Password is too similar to Username", errs[0].Message)
+	})
+
+	t.Run("Partial overlap", func(t *testing.T) {
+		v := newValidator(0)
+
+		// "smit" is 4 characters of "jsmith"
+		errs := v.Validate(signup("blacksmit!")).(validator.ValidationErrors)
+		assert.Equal(t, "Username", errs[0].Reason)
+
+		assert.NoError(t, newValidator(5).Validate(signup("blacksmit!")))
+	})
+
+	t.Run("Only the local part of emails is compared", func(t *testing.T) {
+		v := newValidator(0)
+
+		errs := v.Validate(signup("rivera-rocks")).(validator.ValidationErrors)
+		assert.Equal(t, "Email", errs[0].Reason)
+
+		assert.NoError(t, v.Validate(signup("my example password")))
+	})
+
+	t.Run("Every conflicting field is reported", func(t *testing.T) {
+		v := newValidator(0)
+		v.SetDefaultTagMessage("password_notcontains.Profile.DisplayName", "Password cannot contain your name")
+
+		errs := v.Validate(signup("jsmith-lucía")).(validator.ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "Username", errs[0].Reason)
+		assert.Equal(t, "Profile.DisplayName", errs[1].Reason)
+		assert.Equal(t, "Password cannot contain your name", errs[1].Message)
+	})
+
+	t.Run("Empty fields are ignored", func(t *testing.T) {
+		v := newValidator(0)
+		assert.NoError(t, v.Validate(Signup{Password: "anything"}))
+	})
+
+	t.Run("Minimum overlap per use", func(t *testing.T) {
+		type Login struct {
+			Username string
+			Password string `validate:"password_notcontains=Username min=6"`
+		}
+
+		v := newValidator(0)
+		assert.NoError(t, v.Validate(Login{Username: "jsmith", Password: "blacksmit!"}))
+
+		errs := v.Validate(Login{Username: "jsmith", Password: "jsmith!"}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, 6, errs[0].Params["min_overlap"])
+	})
+
+	t.Run("Normalized like the password policy", func(t *testing.T) {
+		type Login struct {
+			Username string
+			Password string `validate:"password,password_notcontains=Username"`
+		}
+
+		// "ｊｓｍｉｔｈ" is "jsmith" in fullwidth characters, which NFKC turns into ASCII
+		login := Login{Username: "jsmith", Password: "ｊｓｍｉｔｈ-Pa55word!"}
+
+		v := newValidator(0)
+		options := DefaultPasswordOptions()
+		assert.NoError(t, AddPasswordValidation(v, options))
+		assert.NoError(t, v.Validate(login))
+
+		options.Normalize = true
+		assert.NoError(t, AddPasswordPolicy(v, DefaultPasswordPolicy, options))
+		errs := v.Validate(login).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "password_notcontains", errs[0].Constraint)
+		assert.Equal(t, "Username", errs[0].Reason)
+	})
+
+	t.Run("Invalid params are errors", func(t *testing.T) {
+		type Login struct {
+			Username string `validate:"required"`
+			Password string `validate:"password_notcontains=Missing"`
+		}
+		type Reset struct {
+			Username string
+			Password string `validate:"password_notcontains=Username min=0"`
+		}
+
+		v := newValidator(0)
+		err := v.Validate(Login{Password: "anything"})
+		assert.EqualError(t, err, "validations: password_notcontains on field 'Password' references unknown field 'Missing'")
+		_, isValidationErrors := err.(validator.ValidationErrors)
+		assert.False(t, isValidationErrors)
+
+		err = v.Validate(Reset{Password: "anything"})
+		assert.EqualError(t, err, "validations: password_notcontains on field 'Password' has an invalid minimum overlap '0'")
+	})
+}
@@ -0,0 +1,191 @@
+package validations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// PasswordRuleResult is the result of one requirement of a password policy
+type PasswordRuleResult struct {
+	Rule    string `json:"rule"`    // One of the PasswordReason* values
+	Passed  bool   `json:"passed"`  // Whether the password meets the requirement
+	Message string `json:"message"` // Message of the requirement, resolved like validation messages
+}
+
+// PasswordEvaluation is the feedback on a password returned by EvaluatePassword
+type PasswordEvaluation struct {
+	Policy      string               `json:"policy"`                // Name of the policy
+	Valid       bool                 `json:"valid"`                 // Whether the password meets every requirement
+	Rules       []PasswordRuleResult `json:"rules"`                 // Every requirement of the policy, in the order they are reported
+	Score       int                  `json:"score"`                 // Strength score from 0 to 4, see EstimatePasswordStrength
+	Warning     string               `json:"warning,omitempty"`     // What makes the password easy to guess
+	Suggestions []string             `json:"suggestions,omitempty"` // Hints to pick a stronger password
+}
+
+// PasswordHintTranslator translates a warning or suggestion of the strength estimator, which are in English.
+// The context is the one passed to EvaluatePassword, e.g. carrying the language of the request.
+type PasswordHintTranslator func(ctx context.Context, hint string) string
+
+// DefaultPasswordFieldPath is the path of the password field used by EvaluatePassword
+const DefaultPasswordFieldPath = "password"
+
+// EvaluatePassword checks password against a named policy of v, for live feedback while a user types.
+// The result uses exactly the same rules as the "password" validation, and the message of every rule is
+// resolved like the messages of a field at the path "password", from the messages and message functions
+// set on v for the "password" constraint and "password.{reason}" keys, so they are localized the same way.
+// Use EvaluatePasswordField for a field at another path. The strength warning and suggestions are translated
+// with translate when given.
+//
+// Remote checks such as the BreachCheck are not performed, so that evaluating a password on every
+// keystroke does not send requests.
+func EvaluatePassword(ctx context.Context, v *validator.Validator, policy string, password string, translate ...PasswordHintTranslator) (PasswordEvaluation, error) {
+	return EvaluatePasswordField(ctx, v, policy, DefaultPasswordFieldPath, password, translate...)
+}
+
+// EvaluatePasswordField is EvaluatePassword for the password field at path, e.g. "user.new_password", so the
+// messages set for that path are used, exactly as they are when the field is validated.
+func EvaluatePasswordField(ctx context.Context, v *validator.Validator, policy string, path string, password string, translate ...PasswordHintTranslator) (PasswordEvaluation, error) {
+	options, ok := GetPasswordPolicy(v, policy)
+	if !ok {
+		return PasswordEvaluation{}, fmt.Errorf("validations: unknown password policy %q", policy)
+	}
+
+	if options.Normalize {
+		password = NormalizePassword(password)
+	}
+
+	// The tag param of the policy, as it appears in validation errors
+	param := policy
+	if policy == DefaultPasswordPolicy {
+		param = ""
+	}
+
+	// The field is the last element of the path, as in validation errors
+	field := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		field = path[i+1:]
+	}
+
+	evaluation := PasswordEvaluation{Policy: policy, Valid: true}
+	for _, rule := range evaluatePasswordRules(password, policy, options) {
+		e := v.CompleteError(ctx, validator.ValidationError{
+			Field:      field,
+			Path:       path,
+			Constraint: "password",
+			Param:      param,
+			Reason:     rule.reason,
+			Params:     rule.params,
+		}, rule.message)
+
+		evaluation.Rules = append(evaluation.Rules, PasswordRuleResult{
+			Rule:    rule.reason,
+			Passed:  rule.passed,
+			Message: e.Message,
+		})
+		evaluation.Valid = evaluation.Valid && rule.passed
+	}
+
+	strength := EstimatePasswordStrength(password)
+	evaluation.Score = strength.Score
+	evaluation.Warning = strength.Warning
+	evaluation.Suggestions = strength.Suggestions
+
+	for _, fn := range translate {
+		if fn == nil {
+			continue
+		}
+		if evaluation.Warning != "" {
+			evaluation.Warning = fn(ctx, evaluation.Warning)
+		}
+		suggestions := make([]string, len(evaluation.Suggestions))
+		for i, suggestion := range evaluation.Suggestions {
+			suggestions[i] = fn(ctx, suggestion)
+		}
+		evaluation.Suggestions = suggestions
+	}
+
+	return evaluation, nil
+}
+
+// DefaultPasswordFeedbackMaxBodyBytes is the maximum size of a request to a PasswordFeedbackHandler
+const DefaultPasswordFeedbackMaxBodyBytes = 4096
+
+// PasswordFeedbackHandler is an http.Handler serving EvaluatePassword as JSON for live feedback in a front end.
+// It accepts POST requests with a body like {"password": "..."} and responds with a PasswordEvaluation.
+// Messages are resolved with the context of the request, so a middleware can set the language.
+//
+// Example:
+//
+//	http.Handle("/password/feedback", validations.NewPasswordFeedbackHandler(v, validations.DefaultPasswordPolicy))
+type PasswordFeedbackHandler struct {
+	Validator     *validator.Validator   // Validator the policy is registered on
+	Policy        string                 // Name of the policy
+	Path          string                 // Path of the password field whose messages are used, defaults to DefaultPasswordFieldPath
+	TranslateHint PasswordHintTranslator // Translates the strength warning and suggestions, may be nil
+	MaxBodyBytes  int64                  // Maximum size of a request body, defaults to DefaultPasswordFeedbackMaxBodyBytes
+}
+
+// NewPasswordFeedbackHandler creates a PasswordFeedbackHandler for a named policy of v
+func NewPasswordFeedbackHandler(v *validator.Validator, policy string) *PasswordFeedbackHandler {
+	return &PasswordFeedbackHandler{
+		Validator:    v,
+		Policy:       policy,
+		MaxBodyBytes: DefaultPasswordFeedbackMaxBodyBytes,
+	}
+}
+
+// passwordFeedbackRequest is the body of a request to a PasswordFeedbackHandler
+type passwordFeedbackRequest struct {
+	Password string `json:"password"`
+}
+
+// ServeHTTP evaluates the password of the request body
+func (h *PasswordFeedbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writePasswordFeedbackError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	maxBodyBytes := h.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultPasswordFeedbackMaxBodyBytes
+	}
+
+	var req passwordFeedbackRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		writePasswordFeedbackError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	path := h.Path
+	if path == "" {
+		path = DefaultPasswordFieldPath
+	}
+
+	evaluation, err := EvaluatePasswordField(r.Context(), h.Validator, h.Policy, path, req.Password, h.TranslateHint)
+	if err != nil {
+		writePasswordFeedbackError(w, http.StatusInternalServerError, "password policy is not available")
+		return
+	}
+
+	writePasswordFeedbackJSON(w, http.StatusOK, evaluation)
+}
+
+// writePasswordFeedbackError writes an error response of a PasswordFeedbackHandler
+func writePasswordFeedbackError(w http.ResponseWriter, status int, message string) {
+	writePasswordFeedbackJSON(w, status, map[string]string{"error": message})
+}
+
+// writePasswordFeedbackJSON writes a JSON response that must not be cached, as it describes a password
+func writePasswordFeedbackJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,192 @@
+package validations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatePassword(t *testing.T) {
+	type Signup struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	newValidator := func(t *testing.T) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+
+		options := DefaultPasswordOptions()
+		options.MinScore = 3
+		assert.NoError(t, AddPasswordValidation(v, options))
+		return v
+	}
+
+	t.Run("Rules match the validation", func(t *testing.T) {
+		v := newValidator(t)
+
+		for _, password := range []string{"", "short", "Password1!", "StrongP@ss123", "correct horse battery staple", "X9#kq!Lm2$vB7wZr"} {
+			evaluation, err := EvaluatePassword(context.Background(), v, DefaultPasswordPolicy, password)
+			assert.NoError(t, err)
+
+			var failed []string
+			for _, rule := range evaluation.Rules {
+				if !rule.Passed {
+					failed = append(failed, rule.Rule+": "+rule.Message)
+				}
+			}
+
+			var reported []string
+			if errs, ok := v.Validate(Signup{Password: password}).(validator.ValidationErrors); ok {
+				for _, e := range errs {
+					reported = append(reported, e.Reason+": "+e.Message)
+				}
+			}
+
+			assert.Equal(t, reported, failed, password)
+			assert.Equal(t, len(reported) == 0, evaluation.Valid, password)
+		}
+	})
+
+	t.Run("Every rule is listed", func(t *testing.T) {
+		v := newValidator(t)
+
+		evaluation, err := EvaluatePassword(context.Background(), v, DefaultPasswordPolicy, "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultPasswordPolicy, evaluation.Policy)
+		assert.False(t, evaluation.Valid)
+		assert.Equal(t, 0, evaluation.Score)
+		assert.NotEmpty(t, evaluation.Suggestions)
+
+		assert.Equal(t, []PasswordRuleResult{
+			{Rule: PasswordReasonMinLength, Passed: false, Message: "Password must be at least 8 characters"},
+			{Rule: PasswordReasonUppercase, Passed: false, Message: "Password must contain at least one uppercase letter"},
+			{Rule: PasswordReasonLowercase, Passed: true, Message: "Password must contain at least one lowercase letter"},
+			{Rule: PasswordReasonDigit, Passed: false, Message: "Password must contain at least one number"},
+			{Rule: PasswordReasonSpecial, Passed: false, Message: "Password must contain at least one special character"},
+			{Rule: PasswordReasonStrength, Passed: false, Message: "Password is too easy to guess"},
+		}, evaluation.Rules)
+	})
+
+	t.Run("Localized messages and hints", func(t *testing.T) {
+		type langKey struct{}
+
+		v := newValidator(t)
+		v.SetDefaultTagMessageFunc("password.digit", func(ctx context.Context, e validator.ValidationError) string {
+			if ctx.Value(langKey{}) == "fr" {
+				return "Le mot de passe doit contenir au moins un chiffre"
+			}
+			return "Add a number"
+		})
+		translate := func(ctx context.Context, hint string) string {
+			if ctx.Value(langKey{}) == "fr" && hint == suggestionAddWord {
+				return "Ajoutez un ou deux mots."
+			}
+			return hint
+		}
+
+		ctx := context.WithValue(context.Background(), langKey{}, "fr")
+		evaluation, err := EvaluatePassword(ctx, v, DefaultPasswordPolicy, "abc", translate)
+		assert.NoError(t, err)
+		assert.Equal(t, "Le mot de passe doit contenir au moins un chiffre", evaluation.Rules[3].Message)
+		assert.Equal(t, "Ajoutez un ou deux mots.", evaluation.Suggestions[0])
+	})
+
+	t.Run("Messages of the field path", func(t *testing.T) {
+		type Credentials struct {
+			Password string `json:"password" validate:"password"`
+		}
+		type User struct {
+			NewPassword Credentials `json:"new_password"`
+		}
+		type Request struct {
+			User User `json:"user"`
+		}
+
+		v := newValidator(t)
+		v.SetConstraintMessage("user.new_password.password", "password.digit", "Your new {field} needs a number")
+
+		errs := v.Validate(Request{User: User{NewPassword: Credentials{Password: "abcdefgH!"}}}).(validator.ValidationErrors)
+		assert.Equal(t, "Your new password needs a number", errs[0].Message)
+
+		evaluation, err := EvaluatePasswordField(context.Background(), v, DefaultPasswordPolicy, "user.new_password.password", "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, errs[0].Message, evaluation.Rules[3].Message)
+
+		evaluation, err = EvaluatePassword(context.Background(), v, DefaultPasswordPolicy, "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, "Password must contain at least one number", evaluation.Rules[3].Message)
+	})
+
+	t.Run("Unknown policy", func(t *testing.T) {
+		_, err := EvaluatePassword(context.Background(), validator.New(), "missing", "abc")
+		assert.EqualError(t, err, `validations: unknown password policy "missing"`)
+	})
+}
+
+func TestPasswordFeedbackHandler(t *testing.T) {
+	v := validator.New()
+	assert.NoError(t, AddPasswordValidation(v, DefaultPasswordOptions()))
+	handler := NewPasswordFeedbackHandler(v, DefaultPasswordPolicy)
+
+	t.Run("Evaluates the password", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/password/feedback", strings.NewReader(`{"password":"abc"}`))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+		var evaluation PasswordEvaluation
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &evaluation))
+		assert.False(t, evaluation.Valid)
+		assert.Len(t, evaluation.Rules, 5)
+		assert.Equal(t, PasswordReasonMinLength, evaluation.Rules[0].Rule)
+	})
+
+	t.Run("Messages of the field path", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPasswordValidation(v, DefaultPasswordOptions()))
+		v.SetConstraintMessage("signup.password", "password.min_length", "Too short")
+
+		handler := NewPasswordFeedbackHandler(v, DefaultPasswordPolicy)
+		handler.Path = "signup.password"
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password":"abc"}`)))
+
+		var evaluation PasswordEvaluation
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &evaluation))
+		assert.Equal(t, "Too short", evaluation.Rules[0].Message)
+	})
+
+	t.Run("Only POST", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/password/feedback?password=abc", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`not json`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = httptest.NewRecorder()
+		body := `{"password":"` + strings.Repeat("a", DefaultPasswordFeedbackMaxBodyBytes) + `"}`
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Unknown policy", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewPasswordFeedbackHandler(v, "missing").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"password":"abc"}`)))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
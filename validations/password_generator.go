@@ -0,0 +1,186 @@
+package validations
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// Default alphabets of the password generator
+const (
+	DefaultGeneratorLowercase = "abcdefghijklmnopqrstuvwxyz"
+	DefaultGeneratorUppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	DefaultGeneratorDigits    = "0123456789"
+	DefaultGeneratorSpecial   = "!@#$%^&*()-_=+[]{};:,.?"
+)
+
+// DefaultGeneratedPasswordLength is the length of generated passwords when neither the generator
+// options nor the policy require another length
+const DefaultGeneratedPasswordLength = 16
+
+// maxGeneratorAttempts is the number of candidates generated before giving up on a policy
+const maxGeneratorAttempts = 100
+
+// PasswordGeneratorOptions configures the password generator. Empty alphabets use the defaults.
+type PasswordGeneratorOptions struct {
+	Length    int    // Length of the password, defaults to DefaultGeneratedPasswordLength or the policy bounds
+	Lowercase string // Lowercase letters to pick from
+	Uppercase string // Uppercase letters to pick from
+	Digits    string // Digits to pick from
+	Special   string // Special characters to pick from, defaults to the policy SpecialChars if set
+}
+
+// GeneratePassword generates a random password with crypto/rand that satisfies policy. Characters required by
+// the policy are picked first from the matching alphabets, the rest from all alphabets, and the result is
+// shuffled. Every candidate is checked against the policy before being returned, so the password always passes
+// the same policy's validation, except for the BreachCheck, which is not performed.
+//
+// Example:
+//
+//	password, err := validations.GeneratePassword(validations.DefaultPasswordOptions(), validations.PasswordGeneratorOptions{
+//	    Length: 20,
+//	})
+func GeneratePassword(policy PasswordOptions, options PasswordGeneratorOptions) (string, error) {
+	length := options.Length
+	if length <= 0 {
+		length = DefaultGeneratedPasswordLength
+		if length < policy.MinLength {
+			length = policy.MinLength
+		}
+		if policy.MaxLength > 0 && length > policy.MaxLength {
+			length = policy.MaxLength
+		}
+	}
+	if length < policy.MinLength || (policy.MaxLength > 0 && length > policy.MaxLength) {
+		return "", fmt.Errorf("validations: cannot generate a password of %d characters for the policy", length)
+	}
+
+	alphabet := []rune(generatorAlphabet(policy, options))
+
+	// Characters of each class the policy requires a minimum number of
+	var required []passwordCharClass
+	total := 0
+	for _, class := range passwordCharClasses(policy) {
+		if class.min > 0 {
+			required = append(required, class)
+			total += class.min
+		}
+	}
+	if total > length {
+		return "", fmt.Errorf("validations: cannot generate a password of %d characters with %d required characters", length, total)
+	}
+
+	candidates := make([][]rune, len(required))
+	for i, class := range required {
+		for _, r := range alphabet {
+			if class.matches(r) {
+				candidates[i] = append(candidates[i], r)
+			}
+		}
+		if len(candidates[i]) == 0 {
+			return "", fmt.Errorf("validations: the generator alphabets have no %s", class.plural)
+		}
+	}
+
+	// Remote checks are not performed
+	policy.BreachCheck = nil
+
+	for attempt := 0; attempt < maxGeneratorAttempts; attempt++ {
+		password := make([]rune, 0, length)
+		for i, class := range required {
+			for n := 0; n < class.min; n++ {
+				r, err := randomRune(candidates[i])
+				if err != nil {
+					return "", err
+				}
+				password = append(password, r)
+			}
+		}
+		for len(password) < length {
+			r, err := randomRune(alphabet)
+			if err != nil {
+				return "", err
+			}
+			password = append(password, r)
+		}
+		if err := shuffleRunes(password); err != nil {
+			return "", err
+		}
+
+		if len(checkPassword(context.Background(), string(password), "", policy)) == 0 {
+			return string(password), nil
+		}
+	}
+
+	return "", fmt.Errorf("validations: could not generate a password satisfying the policy")
+}
+
+// GeneratePolicyPassword generates a random password satisfying a named policy registered with
+// AddPasswordPolicy or AddPasswordValidation. See GeneratePassword.
+func GeneratePolicyPassword(v *validator.Validator, name string, options PasswordGeneratorOptions) (string, error) {
+	policy, ok := GetPasswordPolicy(v, name)
+	if !ok {
+		return "", fmt.Errorf("validations: unknown password policy %q", name)
+	}
+	return GeneratePassword(policy, options)
+}
+
+// generatorAlphabet returns the characters the generator picks from, without duplicates
+func generatorAlphabet(policy PasswordOptions, options PasswordGeneratorOptions) string {
+	lowercase := defaultString(options.Lowercase, DefaultGeneratorLowercase)
+	uppercase := defaultString(options.Uppercase, DefaultGeneratorUppercase)
+	digits := defaultString(options.Digits, DefaultGeneratorDigits)
+	special := defaultString(options.Special, defaultString(policy.SpecialChars, DefaultGeneratorSpecial))
+
+	var alphabet strings.Builder
+	seen := make(map[rune]bool)
+	for _, r := range lowercase + uppercase + digits + special {
+		if !seen[r] {
+			seen[r] = true
+			alphabet.WriteRune(r)
+		}
+	}
+	return alphabet.String()
+}
+
+// defaultString returns value, or fallback when value is empty
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// randomRune returns a uniformly random rune of runes
+func randomRune(runes []rune) (rune, error) {
+	i, err := randomInt(len(runes))
+	if err != nil {
+		return 0, err
+	}
+	return runes[i], nil
+}
+
+// shuffleRunes shuffles runes in place with a Fisher-Yates shuffle
+func shuffleRunes(runes []rune) error {
+	for i := len(runes) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return nil
+}
+
+// randomInt returns a uniformly random int in [0, n) read from crypto/rand
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
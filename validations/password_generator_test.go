@@ -0,0 +1,108 @@
+package validations
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	type Account struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	policies := map[string]PasswordOptions{
+		"default": DefaultPasswordOptions(),
+		"nist":    NISTPasswordOptions(),
+		"owasp":   OWASPPasswordOptions(),
+		"pci":     PCIPasswordOptions(),
+		"strict": {
+			MinLength:        20,
+			MaxLength:        24,
+			MinUppercase:     3,
+			MinLowercase:     3,
+			MinDigits:        4,
+			MinSpecialChars:  2,
+			SpecialChars:     "#$%",
+			MaxRepeatedChars: 1,
+			MinScore:         4,
+			MaxBytes:         BcryptMaxBytes,
+			Blocklist:        CommonPasswords(0),
+		},
+		"digits only": {MinLength: 6, MinDigits: 6, MaxLength: 6},
+	}
+
+	// The guarantee: generated passwords always pass the validation of the same policy
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			v := validator.New()
+			v.UseJsonTagName()
+			assert.NoError(t, AddPasswordValidation(v, policy))
+
+			for i := 0; i < 200; i++ {
+				password, err := GeneratePassword(policy, PasswordGeneratorOptions{})
+				if !assert.NoError(t, err) {
+					return
+				}
+				if !assert.NoError(t, v.Validate(Account{Password: password}), password) {
+					return
+				}
+			}
+		})
+	}
+
+	t.Run("Length", func(t *testing.T) {
+		password, err := GeneratePassword(DefaultPasswordOptions(), PasswordGeneratorOptions{Length: 32})
+		assert.NoError(t, err)
+		assert.Equal(t, 32, utf8.RuneCountInString(password))
+
+		password, err = GeneratePassword(PasswordOptions{MinLength: 40}, PasswordGeneratorOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 40, utf8.RuneCountInString(password))
+
+		_, err = GeneratePassword(PasswordOptions{MinLength: 12}, PasswordGeneratorOptions{Length: 8})
+		assert.Error(t, err)
+
+		_, err = GeneratePassword(PasswordOptions{MinDigits: 5, MinUppercase: 5}, PasswordGeneratorOptions{Length: 8})
+		assert.Error(t, err)
+	})
+
+	t.Run("Custom alphabets", func(t *testing.T) {
+		options := PasswordGeneratorOptions{Lowercase: "abc", Uppercase: "XYZ", Digits: "7", Special: "!"}
+		password, err := GeneratePassword(DefaultPasswordOptions(), options)
+		assert.NoError(t, err)
+		assert.Empty(t, strings.Trim(password, "abcXYZ7!"))
+		assert.Contains(t, password, "7")
+		assert.Contains(t, password, "!")
+	})
+
+	t.Run("Unicode alphabets", func(t *testing.T) {
+		policy := PasswordOptions{MinLength: 12, MinUppercase: 2, MinLowercase: 2}
+		password, err := GeneratePassword(policy, PasswordGeneratorOptions{Lowercase: "éàü", Uppercase: "ÉÀÜ"})
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultGeneratedPasswordLength, utf8.RuneCountInString(password))
+	})
+
+	t.Run("Alphabets without a required class", func(t *testing.T) {
+		policy := PasswordOptions{RequireSpecialChar: true, SpecialChars: "#"}
+		_, err := GeneratePassword(policy, PasswordGeneratorOptions{Special: "!"})
+		assert.EqualError(t, err, "validations: the generator alphabets have no special characters")
+	})
+
+	t.Run("Named policy", func(t *testing.T) {
+		v := validator.New()
+		admin := DefaultPasswordOptions()
+		admin.MinLength = 24
+		assert.NoError(t, AddPasswordPolicy(v, "admin", admin))
+
+		password, err := GeneratePolicyPassword(v, "admin", PasswordGeneratorOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 24, utf8.RuneCountInString(password))
+
+		_, err = GeneratePolicyPassword(v, "missing", PasswordGeneratorOptions{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,259 @@
+package validations
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrUnsupportedHash is returned by a PasswordHasher when a hash was not created by it
+var ErrUnsupportedHash = errors.New("validations: unsupported password hash")
+
+// Limits of the parameters of the hashes verified by Argon2idHasher and ScryptHasher. Hashes above them are
+// rejected with ErrUnsupportedHash, so a corrupted stored hash cannot make a check exhaust the memory or
+// the CPU of the server.
+const (
+	maxHashMemory  = 1 << 30 // Bytes of memory used to verify a hash
+	maxArgon2Time  = 64      // Passes of Argon2id
+	maxScryptBlock = 1 << 10 // Block size and parallelization of scrypt
+)
+
+// PasswordHasher hashes passwords and verifies passwords against hashes
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password, including its parameters and salt
+	Hash(password string) (string, error)
+	// Verify reports whether password matches an encoded hash
+	Verify(hash, password string) (bool, error)
+}
+
+// BcryptHasher hashes passwords with bcrypt. Passwords longer than BcryptMaxBytes are rejected by bcrypt.
+type BcryptHasher struct {
+	Cost int // bcrypt cost, defaults to bcrypt.DefaultCost
+}
+
+// Hash returns the bcrypt hash of password
+func (h BcryptHasher) Hash(password string) (string, error) {
+	cost := h.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether password matches a bcrypt hash
+func (h BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	case errors.Is(err, bcrypt.ErrPasswordTooLong):
+		// A password too long to be hashed cannot match any hash
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+	}
+}
+
+// Argon2idHasher hashes passwords with Argon2id, encoding hashes in the PHC string format:
+// $argon2id$v=19$m=65536,t=1,p=4$salt$hash
+type Argon2idHasher struct {
+	Memory  uint32 // Memory in KiB, defaults to 64 MiB
+	Time    uint32 // Number of passes, defaults to 1
+	Threads uint8  // Degree of parallelism, defaults to 4
+	KeyLen  uint32 // Length of the hash in bytes, defaults to 32
+	SaltLen uint32 // Length of the salt in bytes, defaults to 16
+}
+
+// Hash returns the Argon2id hash of password
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	memory, time, threads := h.Memory, h.Time, h.Threads
+	if memory == 0 {
+		memory = 64 * 1024
+	}
+	if time == 0 {
+		time = 1
+	}
+	if threads == 0 {
+		threads = 4
+	}
+
+	salt, err := randomSalt(h.SaltLen)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, time, memory, threads, keyLength(h.KeyLen))
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, memory, time, threads,
+		encodeHashPart(salt), encodeHashPart(key)), nil
+}
+
+// Verify reports whether password matches an Argon2id hash
+func (h Argon2idHasher) Verify(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrUnsupportedHash
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, ErrUnsupportedHash
+	}
+	// argon2.IDKey panics without a pass or a thread, and needs at least 8 KiB of memory per thread
+	if time < 1 || time > maxArgon2Time || threads < 1 || memory < 8*uint32(threads) || uint64(memory)*1024 > maxHashMemory {
+		return false, ErrUnsupportedHash
+	}
+
+	salt, key, err := decodeSaltAndKey(parts[4], parts[5])
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// ScryptHasher hashes passwords with scrypt, encoding hashes in the PHC string format:
+// $scrypt$ln=15,r=8,p=1$salt$hash
+type ScryptHasher struct {
+	LogN    uint8  // Base 2 logarithm of the CPU/memory cost, defaults to 15
+	R       int    // Block size, defaults to 8
+	P       int    // Parallelization, defaults to 1
+	KeyLen  uint32 // Length of the hash in bytes, defaults to 32
+	SaltLen uint32 // Length of the salt in bytes, defaults to 16
+}
+
+// Hash returns the scrypt hash of password
+func (h ScryptHasher) Hash(password string) (string, error) {
+	logN, r, p := h.LogN, h.R, h.P
+	if logN == 0 {
+		logN = 15
+	}
+	if r == 0 {
+		r = 8
+	}
+	if p == 0 {
+		p = 1
+	}
+
+	salt, err := randomSalt(h.SaltLen)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, int(keyLength(h.KeyLen)))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", logN, r, p, encodeHashPart(salt), encodeHashPart(key)), nil
+}
+
+// Verify reports whether password matches a scrypt hash
+func (h ScryptHasher) Verify(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return false, ErrUnsupportedHash
+	}
+
+	var logN uint8
+	var r, p int
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &logN, &r, &p); err != nil || logN < 1 || logN >= 32 {
+		return false, ErrUnsupportedHash
+	}
+	// scrypt uses 128 * r * N bytes of memory
+	if r < 1 || r > maxScryptBlock || p < 1 || p > maxScryptBlock || 128*uint64(r)<<logN > maxHashMemory {
+		return false, ErrUnsupportedHash
+	}
+
+	salt, key, err := decodeSaltAndKey(parts[3], parts[4])
+	if err != nil {
+		return false, err
+	}
+
+	other, err := scrypt.Key([]byte(password), salt, 1<<logN, r, p, len(key))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+	}
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// MultiHasher verifies hashes with the first of its hashers that supports them, which allows checking
+// a history with hashes of several algorithms. New hashes are created with the first hasher.
+type MultiHasher []PasswordHasher
+
+// Hash returns the hash of password created by the first hasher
+func (m MultiHasher) Hash(password string) (string, error) {
+	if len(m) == 0 {
+		return "", errors.New("validations: MultiHasher has no hashers")
+	}
+	return m[0].Hash(password)
+}
+
+// Verify reports whether password matches hash, using the first hasher supporting it
+func (m MultiHasher) Verify(hash, password string) (bool, error) {
+	for _, hasher := range m {
+		ok, err := hasher.Verify(hash, password)
+		if errors.Is(err, ErrUnsupportedHash) {
+			continue
+		}
+		return ok, err
+	}
+	return false, ErrUnsupportedHash
+}
+
+// keyLength returns the hash length, defaulting to 32 bytes
+func keyLength(n uint32) uint32 {
+	if n == 0 {
+		return 32
+	}
+	return n
+}
+
+// randomSalt returns a random salt of n bytes, defaulting to 16 bytes
+func randomSalt(n uint32) ([]byte, error) {
+	if n == 0 {
+		n = 16
+	}
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// encodeHashPart encodes a salt or hash with unpadded standard base64, as the PHC string format does
+func encodeHashPart(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// decodeSaltAndKey decodes the salt and hash parts of a PHC string
+func decodeSaltAndKey(encodedSalt, encodedKey string) ([]byte, []byte, error) {
+	salt, err := base64.RawStdEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return nil, nil, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) == 0 {
+		return nil, nil, ErrUnsupportedHash
+	}
+	return salt, key, nil
+}
@@ -0,0 +1,143 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// DefaultPasswordHistoryLimit is the number of previous passwords compared when PasswordHistoryOptions.Limit is not set
+const DefaultPasswordHistoryLimit = 5
+
+// Reasons reported by the password history validation
+const (
+	PasswordReasonReused = "reused"
+	// PasswordReasonHistoryCheckFailed is reported when the history cannot be checked and FailClosed is set
+	PasswordReasonHistoryCheckFailed = "history_check_failed"
+)
+
+// PasswordHistory looks up the hashes of the previous passwords of the user being validated,
+// most recent first. limit is the maximum number of hashes needed.
+type PasswordHistory interface {
+	PreviousPasswordHashes(ctx context.Context, limit int) ([]string, error)
+}
+
+// PasswordHistoryFunc is a function that implements PasswordHistory
+type PasswordHistoryFunc func(ctx context.Context, limit int) ([]string, error)
+
+// PreviousPasswordHashes calls f
+func (f PasswordHistoryFunc) PreviousPasswordHashes(ctx context.Context, limit int) ([]string, error) {
+	return f(ctx, limit)
+}
+
+// passwordHistoryKey is the context key of the PasswordHistory of a request
+type passwordHistoryKey struct{}
+
+// WithPasswordHistory returns a context carrying the password history of the user being validated.
+// Pass it to ValidateCtx to enable the "password_history" validation.
+func WithPasswordHistory(ctx context.Context, history PasswordHistory) context.Context {
+	return context.WithValue(ctx, passwordHistoryKey{}, history)
+}
+
+// passwordHistoryFromContext returns the PasswordHistory carried by ctx, if any
+func passwordHistoryFromContext(ctx context.Context) (PasswordHistory, bool) {
+	history, ok := ctx.Value(passwordHistoryKey{}).(PasswordHistory)
+	return history, ok && history != nil
+}
+
+// PasswordHistoryOptions configures the password history validation
+type PasswordHistoryOptions struct {
+	Hasher     PasswordHasher // Verifies passwords against previous hashes, e.g. BcryptHasher{}
+	Limit      int            // Number of previous passwords compared, defaults to DefaultPasswordHistoryLimit
+	FailClosed bool           // Rejects the password when the history cannot be checked, instead of accepting it
+}
+
+// AddPasswordHistoryValidation registers the "password_history" tag, which rejects passwords matching
+// one of the last Limit passwords of the user. The previous hashes are looked up with the PasswordHistory
+// carried by the context passed to ValidateCtx:
+//
+//	validations.AddPasswordHistoryValidation(v, validations.PasswordHistoryOptions{
+//	    Hasher: validations.BcryptHasher{},
+//	    Limit:  5,
+//	})
+//
+//	type ChangePassword struct {
+//	    NewPassword string `validate:"password,password_history"`
+//	}
+//
+//	ctx := validations.WithPasswordHistory(r.Context(), validations.PasswordHistoryFunc(
+//	    func(ctx context.Context, limit int) ([]string, error) {
+//	        return store.PreviousPasswordHashes(ctx, userID, limit)
+//	    }))
+//	err := v.ValidateCtx(ctx, req)
+//
+// The validation is skipped when the context has no PasswordHistory. A reused password is reported with the
+// constraint "password_history" and the "reused" reason, like the reasons of the "password" tag, rather than
+// with a "reused" constraint of its own. A failed lookup or verification is reported with the
+// "history_check_failed" reason when FailClosed is set. Messages can use the {limit} param and be set with
+// the "password_history.reused" key, which is also the key of error codes.
+func AddPasswordHistoryValidation(v *validator.Validator, options PasswordHistoryOptions) error {
+	if options.Hasher == nil {
+		return fmt.Errorf("validations: password history requires a hasher")
+	}
+	if options.Limit <= 0 {
+		options.Limit = DefaultPasswordHistoryLimit
+	}
+
+	return v.RegisterViolationValidation("password_history", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		history, ok := passwordHistoryFromContext(ctx)
+		if !ok {
+			return nil
+		}
+
+		params := map[string]interface{}{"limit": options.Limit}
+
+		reused, err := isPasswordReused(ctx, history, options, fl.Field().String())
+		switch {
+		case err != nil && options.FailClosed:
+			return []validator.Violation{{
+				Reason:  PasswordReasonHistoryCheckFailed,
+				Message: "Password could not be checked, please try again later",
+				Params:  params,
+			}}
+		case err == nil && reused:
+			return []validator.Violation{{
+				Reason:  PasswordReasonReused,
+				Message: "Password cannot be one of your last {limit} passwords",
+				Params:  params,
+			}}
+		}
+
+		return nil
+	})
+}
+
+// isPasswordReused reports whether password matches one of the previous hashes of the history
+func isPasswordReused(ctx context.Context, history PasswordHistory, options PasswordHistoryOptions, password string) (bool, error) {
+	hashes, err := history.PreviousPasswordHashes(ctx, options.Limit)
+	if err != nil {
+		return false, err
+	}
+	if len(hashes) > options.Limit {
+		hashes = hashes[:options.Limit]
+	}
+
+	for _, hash := range hashes {
+		// Hashing is slow on purpose, stop early when the request is cancelled
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		match, err := options.Hasher.Verify(hash, password)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
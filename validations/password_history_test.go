@@ -0,0 +1,170 @@
+package validations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHashers(t *testing.T) {
+	hashers := map[string]PasswordHasher{
+		"bcrypt":   BcryptHasher{Cost: bcrypt.MinCost},
+		"argon2id": Argon2idHasher{Memory: 1024},
+		"scrypt":   ScryptHasher{LogN: 10},
+	}
+
+	for name, hasher := range hashers {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Hash("S3cret!pass")
+			assert.NoError(t, err)
+
+			ok, err := hasher.Verify(hash, "S3cret!pass")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = hasher.Verify(hash, "s3cret!pass")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			_, err = hasher.Verify("not a hash", "S3cret!pass")
+			assert.ErrorIs(t, err, ErrUnsupportedHash)
+		})
+	}
+
+	t.Run("PHC string format", func(t *testing.T) {
+		hash, _ := Argon2idHasher{Memory: 1024}.Hash("password")
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=4$"))
+
+		hash, _ = ScryptHasher{LogN: 10}.Hash("password")
+		assert.True(t, strings.HasPrefix(hash, "$scrypt$ln=10,r=8,p=1$"))
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		const saltAndKey = "$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+		hashes := []string{
+			"$argon2id$v=19$m=1024,t=0,p=4" + saltAndKey,
+			"$argon2id$v=19$m=1024,t=1,p=0" + saltAndKey,
+			"$argon2id$v=19$m=16,t=1,p=4" + saltAndKey,
+			"$argon2id$v=19$m=4294967295,t=1,p=4" + saltAndKey,
+			"$argon2id$v=19$m=1024,t=4294967295,p=4" + saltAndKey,
+			"$scrypt$ln=0,r=8,p=1" + saltAndKey,
+			"$scrypt$ln=31,r=8,p=1" + saltAndKey,
+			"$scrypt$ln=10,r=0,p=1" + saltAndKey,
+			"$scrypt$ln=10,r=8,p=0" + saltAndKey,
+			"$scrypt$ln=10,r=1073741824,p=1" + saltAndKey,
+		}
+
+		multi := MultiHasher{Argon2idHasher{}, ScryptHasher{}}
+		for _, hash := range hashes {
+			assert.NotPanics(t, func() {
+				_, err := multi.Verify(hash, "password")
+				assert.ErrorIs(t, err, ErrUnsupportedHash, hash)
+			}, hash)
+		}
+	})
+
+	t.Run("MultiHasher", func(t *testing.T) {
+		multi := MultiHasher{Argon2idHasher{Memory: 1024}, BcryptHasher{Cost: bcrypt.MinCost}, ScryptHasher{LogN: 10}}
+
+		for _, hasher := range multi {
+			hash, _ := hasher.Hash("password")
+			ok, err := multi.Verify(hash, "password")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		hash, _ := multi.Hash("password")
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+
+		_, err := multi.Verify("$unknown$", "password")
+		assert.ErrorIs(t, err, ErrUnsupportedHash)
+	})
+}
+
+func TestPasswordHistory(t *testing.T) {
+	type ChangePassword struct {
+		NewPassword string `json:"new_password" validate:"password_history"`
+	}
+
+	hasher := BcryptHasher{Cost: bcrypt.MinCost}
+	var previous []string
+	for _, password := range []string{"latest-pass", "older-pass", "oldest-pass"} {
+		hash, err := hasher.Hash(password)
+		assert.NoError(t, err)
+		previous = append(previous, hash)
+	}
+
+	var requestedLimit int
+	history := PasswordHistoryFunc(func(ctx context.Context, limit int) ([]string, error) {
+		requestedLimit = limit
+		return previous, nil
+	})
+
+	newValidator := func(options PasswordHistoryOptions) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPasswordHistoryValidation(v, options))
+		return v
+	}
+
+	t.Run("Reused password", func(t *testing.T) {
+		v := newValidator(PasswordHistoryOptions{Hasher: hasher, Limit: 3})
+		ctx := WithPasswordHistory(context.Background(), history)
+
+		errs := v.ValidateCtx(ctx, ChangePassword{NewPassword: "older-pass"}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "new_password", errs[0].Path)
+		assert.Equal(t, "password_history", errs[0].Constraint)
+		assert.Equal(t, PasswordReasonReused, errs[0].Reason)
+		assert.Equal(t, "Password cannot be one of your last 3 passwords", errs[0].Message)
+		assert.Equal(t, 3, requestedLimit)
+
+		assert.NoError(t, v.ValidateCtx(ctx, ChangePassword{NewPassword: "brand-new-pass"}))
+	})
+
+	t.Run("Only the last passwords are compared", func(t *testing.T) {
+		v := newValidator(PasswordHistoryOptions{Hasher: hasher, Limit: 2})
+		ctx := WithPasswordHistory(context.Background(), history)
+
+		assert.NoError(t, v.ValidateCtx(ctx, ChangePassword{NewPassword: "oldest-pass"}))
+	})
+
+	t.Run("Configurable message", func(t *testing.T) {
+		v := newValidator(PasswordHistoryOptions{Hasher: hasher})
+		v.SetDefaultTagMessage("password_history.reused", "Choose a password you have not used before")
+		ctx := WithPasswordHistory(context.Background(), history)
+
+		errs := v.ValidateCtx(ctx, ChangePassword{NewPassword: "latest-pass"}).(validator.ValidationErrors)
+		assert.Equal(t, "Choose a password you have not used before", errs[0].Message)
+		assert.Equal(t, DefaultPasswordHistoryLimit, requestedLimit)
+	})
+
+	t.Run("Skipped without a history in the context", func(t *testing.T) {
+		v := newValidator(PasswordHistoryOptions{Hasher: hasher})
+		assert.NoError(t, v.Validate(ChangePassword{NewPassword: "latest-pass"}))
+	})
+
+	t.Run("Lookup errors", func(t *testing.T) {
+		failing := PasswordHistoryFunc(func(ctx context.Context, limit int) ([]string, error) {
+			return nil, errors.New("database unavailable")
+		})
+		ctx := WithPasswordHistory(context.Background(), failing)
+
+		v := newValidator(PasswordHistoryOptions{Hasher: hasher})
+		assert.NoError(t, v.ValidateCtx(ctx, ChangePassword{NewPassword: "latest-pass"}))
+
+		v = newValidator(PasswordHistoryOptions{Hasher: hasher, FailClosed: true})
+		errs := v.ValidateCtx(ctx, ChangePassword{NewPassword: "latest-pass"}).(validator.ValidationErrors)
+		assert.Equal(t, PasswordReasonHistoryCheckFailed, errs[0].Reason)
+	})
+
+	t.Run("Hasher is required", func(t *testing.T) {
+		assert.Error(t, AddPasswordHistoryValidation(validator.New(), PasswordHistoryOptions{}))
+	})
+}
@@ -0,0 +1,262 @@
+package validations
+
+import (
+	"bufio"
+	"compress/gzip"
+	"embed"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// dataFiles holds the embedded word lists and metadata. Files ending in ".gz" are gzip compressed.
+//
+//go:embed data/*
+var dataFiles embed.FS
+
+// commonPasswordsFile is the embedded list of common passwords, most common first
+const commonPasswordsFile = "data/passwords.txt.gz"
+
+// PasswordList is a set of passwords stored as a sorted array of entries, which is searched
+// with a binary search. Passwords are compared case-insensitively, and leetspeak characters
+// of a password match the letter they stand for, so a list containing "password" also
+// contains "P@ssw0rd". The whole password must match an entry character for character:
+// letters never match digits or symbols of an entry, so "izeasg" does not match "123456".
+type PasswordList struct {
+	entries []passwordListEntry
+}
+
+// passwordListEntry is a lowercased password of a list, with its key
+type passwordListEntry struct {
+	key      string // The password with leetspeak characters replaced, see normalizeListedPassword
+	password string
+}
+
+var (
+	commonPasswords     []passwordListEntry
+	commonPasswordsOnce sync.Once
+)
+
+// NewPasswordList creates a PasswordList from the given passwords
+func NewPasswordList(passwords ...string) *PasswordList {
+	entries := make([]passwordListEntry, 0, len(passwords))
+	for _, password := range passwords {
+		if password = strings.TrimSpace(password); password != "" {
+			entries = append(entries, newPasswordListEntry(password))
+		}
+	}
+
+	sortPasswordListEntries(entries)
+
+	// Remove duplicates in place
+	unique := entries[:0]
+	for i, entry := range entries {
+		if i == 0 || entry != entries[i-1] {
+			unique = append(unique, entry)
+		}
+	}
+
+	return &PasswordList{entries: unique}
+}
+
+// ReadPasswordList creates a PasswordList from a reader with one password per line.
+// Empty lines are ignored.
+func ReadPasswordList(r io.Reader) (*PasswordList, error) {
+	words, err := scanWords(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasswordList(words...), nil
+}
+
+// LoadPasswordList creates a PasswordList from a file of fsys with one password per line.
+// Files ending in ".gz" are decompressed.
+//
+// Example:
+//
+//	//go:embed blocklist.txt.gz
+//	var blocklistFS embed.FS
+//
+//	list, err := validations.LoadPasswordList(blocklistFS, "blocklist.txt.gz")
+func LoadPasswordList(fsys fs.FS, name string) (*PasswordList, error) {
+	words, err := readWordList(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasswordList(words...), nil
+}
+
+// CommonPasswords returns a PasswordList with the n most common passwords of the embedded list.
+// A value of n that is not positive or exceeds the size of the list returns the whole list.
+//
+// Example:
+//
+//	options := validations.DefaultPasswordOptions()
+//	options.Blocklist = validations.CommonPasswords(500)
+func CommonPasswords(n int) *PasswordList {
+	commonPasswordsOnce.Do(func() {
+		words, err := readWordList(dataFiles, commonPasswordsFile)
+		if err != nil {
+			panic("validations: cannot read embedded password list: " + err.Error())
+		}
+
+		// Keep the first occurrence of every lowercased password, in rank order
+		seen := make(map[string]bool, len(words))
+		for _, word := range words {
+			entry := newPasswordListEntry(word)
+			if !seen[entry.password] {
+				seen[entry.password] = true
+				commonPasswords = append(commonPasswords, entry)
+			}
+		}
+	})
+
+	if n <= 0 || n > len(commonPasswords) {
+		n = len(commonPasswords)
+	}
+
+	entries := make([]passwordListEntry, n)
+	copy(entries, commonPasswords[:n])
+	sortPasswordListEntries(entries)
+
+	return &PasswordList{entries: entries}
+}
+
+// Contains reports whether password is in the list, ignoring case and leetspeak substitutions
+func (l *PasswordList) Contains(password string) bool {
+	if l == nil {
+		return false
+	}
+
+	candidate := newPasswordListEntry(password)
+
+	// Entries with the same key only differ by leetspeak characters, e.g. "password" and "p@ssw0rd"
+	i := sort.Search(len(l.entries), func(i int) bool { return l.entries[i].key >= candidate.key })
+	for ; i < len(l.entries) && l.entries[i].key == candidate.key; i++ {
+		if matchesListedPassword(candidate.password, l.entries[i].password) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of distinct lowercased passwords in the list
+func (l *PasswordList) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.entries)
+}
+
+// leetspeakReplacements maps leetspeak characters to the letter they usually stand for
+var leetspeakReplacements = map[rune]rune{
+	'4': 'a',
+	'@': 'a',
+	'8': 'b',
+	'(': 'c',
+	'3': 'e',
+	'6': 'g',
+	'9': 'g',
+	'1': 'i',
+	'!': 'i',
+	'|': 'i',
+	'0': 'o',
+	'$': 's',
+	'5': 's',
+	'7': 't',
+	'+': 't',
+	'2': 'z',
+}
+
+// normalizeListedPassword lowercases password and replaces leetspeak characters with letters.
+// The same normalization is applied to list entries and candidates, so both compare equal.
+func normalizeListedPassword(password string) string {
+	return strings.Map(func(r rune) rune {
+		if letter, ok := leetspeakReplacements[r]; ok {
+			return letter
+		}
+		return r
+	}, strings.ToLower(password))
+}
+
+// newPasswordListEntry returns the entry of password
+func newPasswordListEntry(password string) passwordListEntry {
+	password = strings.ToLower(password)
+	return passwordListEntry{key: normalizeListedPassword(password), password: password}
+}
+
+// sortPasswordListEntries sorts entries by key, then by password
+func sortPasswordListEntries(entries []passwordListEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].key != entries[j].key {
+			return entries[i].key < entries[j].key
+		}
+		return entries[i].password < entries[j].password
+	})
+}
+
+// matchesListedPassword reports whether the lowercased password matches a listed password with the same
+// key. Each character must be the listed one, or a leetspeak character standing for the same letter, so
+// leetspeak is only undone in the password: "p@ssw0rd" matches "password", but "izeasg" does not match
+// "123456", nor "password" match "p@ssw0rd".
+func matchesListedPassword(password, listed string) bool {
+	candidate, entry := []rune(password), []rune(listed)
+	if len(candidate) != len(entry) {
+		return false
+	}
+
+	for i, r := range candidate {
+		if r == entry[i] {
+			continue
+		}
+		letter, ok := leetspeakReplacements[r]
+		if !ok {
+			return false
+		}
+		if listedLetter, ok := leetspeakReplacements[entry[i]]; ok {
+			entry[i] = listedLetter
+		}
+		if letter != entry[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// readWordList reads a file of fsys with one word per line, decompressing files ending in ".gz"
+func readWordList(fsys fs.FS, name string) ([]string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if path.Ext(name) == ".gz" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return scanWords(r)
+}
+
+// scanWords returns the non-empty lines of r, without surrounding spaces
+func scanWords(r io.Reader) ([]string, error) {
+	var words []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words, scanner.Err()
+}
@@ -0,0 +1,111 @@
+package validations
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordList(t *testing.T) {
+	t.Run("Case and leetspeak insensitive", func(t *testing.T) {
+		list := NewPasswordList("password", "Dragon", "", "PASSWORD")
+		assert.Equal(t, 2, list.Len())
+
+		assert.True(t, list.Contains("password"))
+		assert.True(t, list.Contains("PassWord"))
+		assert.True(t, list.Contains("p@ssw0rd"))
+		assert.True(t, list.Contains("dr4g0n"))
+		assert.False(t, list.Contains("passwords"))
+		assert.False(t, list.Contains(""))
+	})
+
+	t.Run("Leetspeak is only undone in the password", func(t *testing.T) {
+		list := NewPasswordList("123456", "P@ssw0rd", "iloveyou")
+		assert.Equal(t, 3, list.Len())
+
+		assert.True(t, list.Contains("123456"))
+		assert.True(t, list.Contains("p@ssw0rd"))
+		assert.True(t, list.Contains("p4ssw0rd"))
+		assert.True(t, list.Contains("!l0v3y0u"))
+		assert.False(t, list.Contains("izeasg"))
+		assert.False(t, list.Contains("password"))
+		assert.False(t, list.Contains("iloveyou1"))
+	})
+
+	t.Run("Nil list contains nothing", func(t *testing.T) {
+		var list *PasswordList
+		assert.False(t, list.Contains("password"))
+		assert.Equal(t, 0, list.Len())
+	})
+
+	t.Run("ReadPasswordList", func(t *testing.T) {
+		list, err := ReadPasswordList(strings.NewReader("hunter2\n\n  letmein  \n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, list.Len())
+		assert.True(t, list.Contains("Hunter2"))
+		assert.True(t, list.Contains("letmein"))
+	})
+
+	t.Run("LoadPasswordList", func(t *testing.T) {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, _ = gz.Write([]byte("companyname\nsummer2024\n"))
+		assert.NoError(t, gz.Close())
+
+		fsys := fstest.MapFS{
+			"plain.txt":      {Data: []byte("acme123\n")},
+			"blocked.txt.gz": {Data: compressed.Bytes()},
+		}
+
+		list, err := LoadPasswordList(fsys, "plain.txt")
+		assert.NoError(t, err)
+		assert.True(t, list.Contains("ACME123"))
+
+		list, err = LoadPasswordList(fsys, "blocked.txt.gz")
+		assert.NoError(t, err)
+		assert.True(t, list.Contains("Summer2024"))
+		assert.True(t, list.Contains("c0mpanyname"))
+
+		_, err = LoadPasswordList(fsys, "missing.txt")
+		assert.Error(t, err)
+	})
+
+	t.Run("CommonPasswords", func(t *testing.T) {
+		top := CommonPasswords(10)
+		assert.Equal(t, 10, top.Len())
+		assert.True(t, top.Contains("123456"))
+		assert.True(t, top.Contains("Password"))
+
+		all := CommonPasswords(0)
+		assert.Greater(t, all.Len(), 500)
+		assert.Equal(t, all.Len(), CommonPasswords(all.Len()+1).Len())
+		assert.True(t, all.Contains("trustno1"))
+		assert.False(t, all.Contains("correct horse battery staple"))
+		assert.False(t, all.Contains("izeasg"))
+	})
+}
+
+func TestPasswordBlocklist(t *testing.T) {
+	type Signup struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	options := DefaultPasswordOptions()
+	options.Blocklist = CommonPasswords(1000)
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddPasswordValidation(v, options))
+
+	assert.NoError(t, v.Validate(Signup{Password: "Xq7!mVz#42pL"}))
+
+	errors := v.Validate(Signup{Password: "P@ssw0rd"}).(validator.ValidationErrors)
+	assert.Len(t, errors, 1)
+	assert.Equal(t, PasswordReasonCommon, errors[0].Reason)
+	assert.Equal(t, "Password is too common", errors[0].Message)
+}
@@ -0,0 +1,179 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// DefaultPasswordPolicy is the name of the policy used by `validate:"password"` tags
+// that do not select a policy. AddPasswordValidation registers its options under this name.
+const DefaultPasswordPolicy = "default"
+
+// passwordPolicies holds the named password policies registered on a validator
+type passwordPolicies struct {
+	mu       sync.RWMutex
+	policies map[string]PasswordOptions
+}
+
+// passwordPoliciesKey is the key of the password policies of a validator, see validator.Validator.Extension.
+// Validators created with UseMessages share the base validator, and therefore the policies.
+type passwordPoliciesKey struct{}
+
+// getPasswordPolicies returns the password policies of v, and whether they were just created
+func getPasswordPolicies(v *validator.Validator) (*passwordPolicies, bool) {
+	created := false
+	registry := v.Extension(passwordPoliciesKey{}, func() interface{} {
+		created = true
+		return &passwordPolicies{policies: make(map[string]PasswordOptions)}
+	})
+	return registry.(*passwordPolicies), created
+}
+
+// get returns the options of a named policy
+func (p *passwordPolicies) get(name string) (PasswordOptions, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	options, ok := p.policies[name]
+	return options, ok
+}
+
+// set registers or replaces a named policy
+func (p *passwordPolicies) set(name string, options PasswordOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policies[name] = options
+}
+
+// AddPasswordPolicy registers a named password policy with the validator. Policies are selected
+// with the "password" tag parameter, and tags without a parameter use the DefaultPasswordPolicy.
+// Registering a policy with an existing name replaces it.
+//
+// Example:
+//
+//	admin := validations.DefaultPasswordOptions()
+//	admin.MinLength = 16
+//	validations.AddPasswordPolicy(v, "admin", admin)
+//
+//	type AdminUser struct {
+//	    Password string `validate:"password=admin"`
+//	}
+//
+// Policy names cannot be empty or contain spaces, commas, pipes or equal signs, as those have a meaning
+// in validation tags. A field that references a policy that was never registered makes ValidateCtx return
+// an error instead of ValidationErrors, as it is a bug in the code rather than invalid input; use
+// CheckPasswordPolicies at startup to catch these before the first validation.
+func AddPasswordPolicy(v *validator.Validator, name string, options PasswordOptions) error {
+	if name == "" || strings.ContainsAny(name, " \t,|=") {
+		return fmt.Errorf("validations: invalid password policy name %q", name)
+	}
+
+	registry, created := getPasswordPolicies(v)
+	registry.set(name, options)
+
+	if !created {
+		return nil
+	}
+
+	// Register the password validation function once per validator
+	return v.RegisterViolationValidation("password", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		name := fl.Param()
+		if name == "" {
+			name = DefaultPasswordPolicy
+		}
+
+		options, ok := registry.get(name)
+		if !ok {
+			validator.AbortValidation(ctx, fmt.Errorf("validations: unknown password policy %q on field '%s'", name, fl.FieldName()))
+			// The field still fails when the underlying go-playground validator is used directly
+			return []validator.Violation{{}}
+		}
+
+		return checkPassword(ctx, fl.Field().String(), name, options)
+	})
+}
+
+// GetPasswordPolicy returns the options of a password policy registered with AddPasswordPolicy
+// or AddPasswordValidation.
+func GetPasswordPolicy(v *validator.Validator, name string) (PasswordOptions, bool) {
+	registry, ok := v.Extension(passwordPoliciesKey{}, nil).(*passwordPolicies)
+	if !ok {
+		return PasswordOptions{}, false
+	}
+	return registry.get(name)
+}
+
+// CheckPasswordPolicies inspects the `validate` tags of the given structs, including nested structs,
+// slices and maps of structs, and returns an error listing every password policy they reference
+// that is not registered on v. Call it at startup to catch typos in policy names before the first
+// validation.
+//
+// Example:
+//
+//	if err := validations.CheckPasswordPolicies(v, SignupRequest{}, AdminUser{}); err != nil {
+//	    log.Fatal(err)
+//	}
+func CheckPasswordPolicies(v *validator.Validator, structs ...interface{}) error {
+	unknown := make(map[string]bool)
+	visited := make(map[reflect.Type]bool)
+
+	for _, s := range structs {
+		for _, name := range referencedPasswordPolicies(reflect.TypeOf(s), visited) {
+			if _, ok := GetPasswordPolicy(v, name); !ok {
+				unknown[name] = true
+			}
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("validations: unknown password policies: %s", strings.Join(names, ", "))
+}
+
+// referencedPasswordPolicies returns the policy names referenced by password tags in t
+func referencedPasswordPolicies(t reflect.Type, visited map[reflect.Type]bool) []string {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		names = append(names, passwordPolicyNames(field.Tag.Get("validate"))...)
+		names = append(names, referencedPasswordPolicies(field.Type, visited)...)
+	}
+
+	return names
+}
+
+// passwordPolicyNames returns the policy names selected by the password tags of a validate tag
+func passwordPolicyNames(tag string) []string {
+	var names []string
+	for _, rule := range strings.FieldsFunc(tag, func(r rune) bool { return r == ',' || r == '|' }) {
+		if rule == "password" {
+			names = append(names, DefaultPasswordPolicy)
+		} else if name, ok := strings.CutPrefix(rule, "password="); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,129 @@
+package validations
+
+import (
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicies(t *testing.T) {
+	type Accounts struct {
+		User    string `json:"user" validate:"password"`
+		Admin   string `json:"admin" validate:"password=admin"`
+		Service string `json:"service" validate:"password=service"`
+	}
+
+	newValidator := func(t *testing.T) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+
+		assert.NoError(t, AddPasswordValidation(v, DefaultPasswordOptions()))
+
+		admin := DefaultPasswordOptions()
+		admin.MinLength = 16
+		assert.NoError(t, AddPasswordPolicy(v, "admin", admin))
+
+		service := PasswordOptions{MinLength: 32}
+		assert.NoError(t, AddPasswordPolicy(v, "service", service))
+
+		return v
+	}
+
+	t.Run("Each field uses its policy", func(t *testing.T) {
+		v := newValidator(t)
+
+		err := v.Validate(Accounts{
+			User:    "StrongP@ss123",
+			Admin:   "StrongP@ss123",
+			Service: "0123456789abcdef0123456789abcdef",
+		})
+		errors, ok := err.(validator.ValidationErrors)
+		assert.True(t, ok, "Expected ValidationErrors type")
+
+		assert.Len(t, errors, 1)
+		assert.Equal(t, "admin", errors[0].Path)
+		assert.Equal(t, "admin", errors[0].Param)
+		assert.Equal(t, PasswordReasonMinLength, errors[0].Reason)
+		assert.Equal(t, "Password must be at least 16 characters", errors[0].Message)
+		assert.Equal(t, "admin", errors[0].Params["policy"])
+	})
+
+	t.Run("Each policy has its own requirements message", func(t *testing.T) {
+		v := newValidator(t)
+		v.SetDefaultTagMessage("password", "{requirements}")
+
+		errors := v.Validate(Accounts{}).(validator.ValidationErrors)
+		assert.Equal(t, "Password must be at least 16 characters and contain at least one uppercase letter, "+
+			"at least one lowercase letter, at least one number and at least one special character",
+			errors.ErrorsForPath("admin")[0].Message)
+		assert.Equal(t, "Password must be at least 32 characters", errors.ErrorsForPath("service")[0].Message)
+	})
+
+	t.Run("Policies are shared with UseMessages", func(t *testing.T) {
+		v := newValidator(t).UseMessages(validator.NewValidationMessages())
+
+		errors := v.Validate(Accounts{Service: "short"}).(validator.ValidationErrors)
+		assert.Equal(t, "Password must be at least 32 characters", errors.ErrorsForPath("service")[0].Message)
+
+		options, ok := GetPasswordPolicy(v, "service")
+		assert.True(t, ok)
+		assert.Equal(t, 32, options.MinLength)
+	})
+
+	t.Run("Invalid policy names", func(t *testing.T) {
+		v := validator.New()
+		assert.Error(t, AddPasswordPolicy(v, "", DefaultPasswordOptions()))
+		assert.Error(t, AddPasswordPolicy(v, "a,b", DefaultPasswordOptions()))
+		assert.Error(t, AddPasswordPolicy(v, "a|b", DefaultPasswordOptions()))
+		assert.Error(t, AddPasswordPolicy(v, "a b", DefaultPasswordOptions()))
+	})
+
+	t.Run("Unknown policy is an error", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPasswordValidation(v, DefaultPasswordOptions()))
+
+		type Login struct {
+			Email    string `validate:"required,email"`
+			Password string `validate:"password=missing"`
+		}
+
+		err := v.Validate(Login{Email: "invalid", Password: "StrongP@ss123"})
+		assert.EqualError(t, err, `validations: unknown password policy "missing" on field 'Password'`)
+		_, isValidationErrors := err.(validator.ValidationErrors)
+		assert.False(t, isValidationErrors)
+
+		// The field is invalid when the underlying validator is used directly
+		assert.Error(t, v.BaseValidator.Struct(Login{Email: "john@example.com", Password: "StrongP@ss123"}))
+	})
+
+	t.Run("Policies are stored on the validator", func(t *testing.T) {
+		a, b := validator.New(), validator.New()
+		assert.NoError(t, AddPasswordPolicy(a, "admin", DefaultPasswordOptions()))
+
+		_, ok := GetPasswordPolicy(a, "admin")
+		assert.True(t, ok)
+		_, ok = GetPasswordPolicy(b, "admin")
+		assert.False(t, ok)
+	})
+
+	t.Run("CheckPasswordPolicies", func(t *testing.T) {
+		v := newValidator(t)
+
+		type Nested struct {
+			Password string `validate:"required,password=missing|password=other"`
+		}
+		type Form struct {
+			Accounts Accounts
+			Items    []*Nested
+		}
+
+		assert.NoError(t, CheckPasswordPolicies(v, Accounts{}))
+
+		err := CheckPasswordPolicies(v, Form{})
+		assert.EqualError(t, err, `validations: unknown password policies: "missing", "other"`)
+
+		err = CheckPasswordPolicies(validator.New(), Accounts{})
+		assert.EqualError(t, err, `validations: unknown password policies: "admin", "default", "service"`)
+	})
+}
@@ -0,0 +1,59 @@
+package validations
+
+// Password policy presets based on published guidelines. Each preset returns a new PasswordOptions
+// value that can be adjusted before registering it, e.g. to add a BreachCheck or MaxBytes:
+//
+//	options := validations.NISTPasswordOptions()
+//	options.BreachCheck = &validations.BreachCheck{Client: validations.NewHTTPPwnedPasswordsClient()}
+//	options.MaxBytes = validations.BcryptMaxBytes
+//	validations.AddPasswordPolicy(v, "nist", options)
+//
+// The presets only cover the rules that can be checked on the password itself, and do not comply with
+// the guidelines on their own: their blocklist is the embedded list of about a thousand common passwords,
+// while NIST and OWASP require checking passwords against breached ones, which needs a BreachCheck.
+// Rate limiting, rotation and storage requirements of the guidelines are out of scope.
+
+// NISTPasswordOptions returns options following NIST SP 800-63B, section 5.1.1.2 (Memorized Secret Verifiers):
+//   - MinLength 8: secrets shall be at least 8 characters
+//   - MaxLength 64: verifiers should permit at least 64 characters
+//   - Normalize: Unicode characters should be accepted and normalized with NFKC or NFKD before hashing
+//   - No composition rules: verifiers should not impose rules requiring mixtures of character types
+//   - Blocklist CommonPasswords(0): secrets shall be compared against lists of commonly used values.
+//     The guideline also lists values from previous breaches, add a BreachCheck to check them
+func NISTPasswordOptions() PasswordOptions {
+	return PasswordOptions{
+		MinLength: 8,
+		MaxLength: 64,
+		Normalize: true,
+		Blocklist: CommonPasswords(0),
+	}
+}
+
+// OWASPPasswordOptions returns options following OWASP ASVS 4.0.3, section V2.1 (Password Security):
+//   - MinLength 12: 2.1.1, passwords shall be at least 12 characters
+//   - MaxLength 128: 2.1.2, passwords of at least 64 characters shall be permitted, and of more than 128 denied
+//   - Normalize: 2.1.4, any printable Unicode character shall be permitted
+//   - No composition rules: 2.1.9, there shall be no requirements on upper or lower case, numbers or special characters
+//   - Blocklist CommonPasswords(0): common passwords only. 2.1.7 requires checking passwords against a set
+//     of breached passwords, add a BreachCheck to check them
+func OWASPPasswordOptions() PasswordOptions {
+	return PasswordOptions{
+		MinLength: 12,
+		MaxLength: 128,
+		Normalize: true,
+		Blocklist: CommonPasswords(0),
+	}
+}
+
+// PCIPasswordOptions returns options following PCI DSS 4.0, requirement 8.3.6:
+//   - MinLength 12: passwords shall be at least 12 characters
+//   - RequireLetter and RequireDigit: passwords shall contain both numeric and alphabetic characters
+//   - Blocklist CommonPasswords(0): not required by 8.3.6, but in line with guidance for strong passwords
+func PCIPasswordOptions() PasswordOptions {
+	return PasswordOptions{
+		MinLength:     12,
+		RequireLetter: true,
+		RequireDigit:  true,
+		Blocklist:     CommonPasswords(0),
+	}
+}
@@ -0,0 +1,124 @@
+package validations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPresets(t *testing.T) {
+	type Accounts struct {
+		NIST  string `json:"nist" validate:"password=nist"`
+		OWASP string `json:"owasp" validate:"password=owasp"`
+		PCI   string `json:"pci" validate:"password=pci"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddPasswordPolicy(v, "nist", NISTPasswordOptions()))
+	assert.NoError(t, AddPasswordPolicy(v, "owasp", OWASPPasswordOptions()))
+	assert.NoError(t, AddPasswordPolicy(v, "pci", PCIPasswordOptions()))
+
+	reasons := func(errs validator.ValidationErrors, path string) []string {
+		var reasons []string
+		for _, e := range errs.ErrorsForPath(path) {
+			reasons = append(reasons, e.Reason)
+		}
+		return reasons
+	}
+
+	t.Run("Passphrases without composition", func(t *testing.T) {
+		errs := v.Validate(Accounts{
+			NIST:  "purple elephant dances",
+			OWASP: "purple elephant dances",
+			PCI:   "purple elephant dances",
+		}).(validator.ValidationErrors)
+
+		assert.Empty(t, reasons(errs, "nist"))
+		assert.Empty(t, reasons(errs, "owasp"))
+		assert.Equal(t, []string{PasswordReasonDigit}, reasons(errs, "pci"))
+	})
+
+	t.Run("Common passwords", func(t *testing.T) {
+		errs := v.Validate(Accounts{NIST: "Passw0rd", OWASP: "purple elephant dances"}).(validator.ValidationErrors)
+		assert.Equal(t, []string{PasswordReasonCommon}, reasons(errs, "nist"))
+		assert.Empty(t, reasons(errs, "owasp"))
+
+		assert.NotNil(t, OWASPPasswordOptions().Blocklist)
+		assert.NotNil(t, PCIPasswordOptions().Blocklist)
+	})
+
+	t.Run("Maximum length", func(t *testing.T) {
+		errs := v.Validate(Accounts{
+			NIST:  strings.Repeat("abcd ", 13),
+			OWASP: strings.Repeat("abcd ", 26),
+			PCI:   strings.Repeat("abcd ", 26) + "1",
+		}).(validator.ValidationErrors)
+
+		assert.Equal(t, []string{PasswordReasonMaxLength}, reasons(errs, "nist"))
+		assert.Equal(t, "Password must be at most 64 characters", errs.ErrorsForPath("nist")[0].Message)
+		assert.Equal(t, []string{PasswordReasonMaxLength}, reasons(errs, "owasp"))
+		assert.Empty(t, reasons(errs, "pci"))
+	})
+}
+
+func TestPasswordNormalization(t *testing.T) {
+	type Signup struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	t.Run("Length counts normalized characters", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPasswordValidation(v, PasswordOptions{MinLength: 8, Normalize: true}))
+
+		// 8 characters, 11 bytes
+		assert.NoError(t, v.Validate(Signup{Password: "ñandúesá"}))
+		// 7 characters, 9 bytes
+		assert.Error(t, v.Validate(Signup{Password: "ñandúes"}))
+
+		// The "ﬁ" ligature is normalized to "fi"
+		assert.NoError(t, v.Validate(Signup{Password: "ﬁreﬁght"}))
+	})
+
+	t.Run("Blocklist sees the normalized password", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPasswordValidation(v, PasswordOptions{Normalize: true, Blocklist: NewPasswordList("password")}))
+
+		// Fullwidth letters
+		err := v.Validate(Signup{Password: "ｐａｓｓｗｏｒｄ"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Without normalization length counts characters", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPasswordValidation(v, PasswordOptions{MinLength: 8}))
+		assert.Error(t, v.Validate(Signup{Password: "ñandúes"}))
+		assert.Error(t, v.Validate(Signup{Password: "ﬁreﬁght"}))
+	})
+
+	t.Run("NormalizePassword", func(t *testing.T) {
+		assert.Equal(t, "fire", NormalizePassword("ﬁre"))
+		assert.Equal(t, "\u00e9", NormalizePassword("e\u0301"))
+	})
+}
+
+func TestPasswordMaxBytes(t *testing.T) {
+	type Signup struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddPasswordValidation(v, PasswordOptions{MinLength: 8, MaxBytes: BcryptMaxBytes}))
+
+	assert.NoError(t, v.Validate(Signup{Password: strings.Repeat("a", 72)}))
+
+	// 36 characters but 72 bytes is allowed, one more character is not
+	assert.NoError(t, v.Validate(Signup{Password: strings.Repeat("é", 36)}))
+	errs := v.Validate(Signup{Password: strings.Repeat("é", 37)}).(validator.ValidationErrors)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, PasswordReasonMaxBytes, errs[0].Reason)
+	assert.Equal(t, 72, errs[0].Params["max_bytes"])
+}
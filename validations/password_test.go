@@ -137,14 +137,19 @@ func TestPasswordErrorMessage(t *testing.T) {
 
 	// Test various configurations and their resulting error messages
 	tests := []struct {
-		name           string
-		options        PasswordOptions
-		expectedPhrase string // Error message should contain this phrase
+		name            string
+		options         PasswordOptions
+		expectedPhrases []string // Error messages should contain these phrases, one per failed requirement
 	}{
 		{
-			name:           "Default options",
-			options:        DefaultPasswordOptions(),
-			expectedPhrase: "at least one uppercase letter, at least one lowercase letter, at least one number and at least one special character",
+			name:    "Default options",
+			options: DefaultPasswordOptions(),
+			expectedPhrases: []string{
+				"at least 8 characters",
+				"at least one uppercase letter",
+				"at least one number",
+				"at least one special character",
+			},
 		},
 		{
 			name: "Custom length only",
@@ -157,7 +162,7 @@ func TestPasswordErrorMessage(t *testing.T) {
 				opts.RequireSpecialChar = false
 				return opts
 			}(),
-			expectedPhrase: "at least 12 characters",
+			expectedPhrases: []string{"at least 12 characters"},
 		},
 		{
 			name: "Only digits required",
@@ -168,7 +173,7 @@ func TestPasswordErrorMessage(t *testing.T) {
 				opts.RequireSpecialChar = false
 				return opts
 			}(),
-			expectedPhrase: "at least one number",
+			expectedPhrases: []string{"at least 8 characters", "at least one number"},
 		},
 	}
 
@@ -192,18 +197,163 @@ func TestPasswordErrorMessage(t *testing.T) {
 			errors, ok := err.(validator.ValidationErrors)
 			assert.True(t, ok, "Expected ValidationErrors type")
 
-			// Find password error message
-			var errorMessage string
+			// Find password error messages
+			var errorMessages []string
 			for _, e := range errors {
 				if e.Path == "password" && e.Constraint == "password" {
-					errorMessage = e.Message
-					break
+					errorMessages = append(errorMessages, e.Message)
 				}
 			}
 
-			// Check error message contains expected phrase
-			assert.Contains(t, errorMessage, tt.expectedPhrase,
-				"Error message should contain the expected requirements phrase")
+			// Check there is one message per failed requirement, containing the expected phrase
+			assert.Len(t, errorMessages, len(tt.expectedPhrases))
+			for i, phrase := range tt.expectedPhrases {
+				if i < len(errorMessages) {
+					assert.Contains(t, errorMessages[i], phrase,
+						"Error message should contain the expected requirement phrase")
+				}
+			}
 		})
 	}
 }
+
+func TestPasswordFailureReasons(t *testing.T) {
+	type User struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	err := AddPasswordValidation(v, DefaultPasswordOptions())
+	assert.NoError(t, err)
+
+	t.Run("Only failed requirements are reported", func(t *testing.T) {
+		err := v.Validate(User{Password: "StrongPass!"})
+		errors, ok := err.(validator.ValidationErrors)
+		assert.True(t, ok, "Expected ValidationErrors type")
+
+		assert.Len(t, errors, 1)
+		assert.Equal(t, "password", errors[0].Constraint)
+		assert.Equal(t, PasswordReasonDigit, errors[0].Reason)
+		assert.Equal(t, "Password must contain at least one number", errors[0].Message)
+	})
+
+	t.Run("All failed requirements", func(t *testing.T) {
+		err := v.Validate(User{Password: "!"})
+		errors := err.(validator.ValidationErrors)
+
+		var reasons []string
+		for _, e := range errors {
+			reasons = append(reasons, e.Reason)
+		}
+		assert.Equal(t, []string{
+			PasswordReasonMinLength,
+			PasswordReasonUppercase,
+			PasswordReasonLowercase,
+			PasswordReasonDigit,
+		}, reasons)
+	})
+
+	t.Run("Requirement messages can be overridden", func(t *testing.T) {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPasswordValidation(v, DefaultPasswordOptions()))
+		v.SetDefaultTagMessage("password.min_length", "Use {min} characters or more")
+		v.SetDefaultTagMessage("password.uppercase", "Add an uppercase letter")
+
+		errors := v.Validate(User{Password: "short1!"}).(validator.ValidationErrors)
+		assert.Len(t, errors, 2)
+		assert.Equal(t, "Use 8 characters or more", errors[0].Message)
+		assert.Equal(t, "Add an uppercase letter", errors[1].Message)
+	})
+
+	t.Run("Tag message overrides all requirements", func(t *testing.T) {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPasswordValidation(v, DefaultPasswordOptions()))
+		v.SetDefaultTagMessage("password", "{requirements}")
+
+		errors := v.Validate(User{Password: "short"}).(validator.ValidationErrors)
+		for _, e := range errors {
+			assert.Equal(t, "Password must be at least 8 characters and contain at least one uppercase letter, "+
+				"at least one lowercase letter, at least one number and at least one special character", e.Message)
+		}
+	})
+}
+
+func TestPasswordCharacterClasses(t *testing.T) {
+	type User struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	newValidator := func(options PasswordOptions) *validator.Validator {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPasswordValidation(v, options))
+		return v
+	}
+
+	reasons := func(err error) []string {
+		var reasons []string
+		if errors, ok := err.(validator.ValidationErrors); ok {
+			for _, e := range errors {
+				reasons = append(reasons, e.Reason)
+			}
+		}
+		return reasons
+	}
+
+	t.Run("Unicode letters and digits", func(t *testing.T) {
+		v := newValidator(DefaultPasswordOptions())
+		assert.NoError(t, v.Validate(User{Password: "Éclairß٣!"}))
+		assert.NoError(t, v.Validate(User{Password: "Ñandú#2024"}))
+		assert.Equal(t, []string{PasswordReasonUppercase}, reasons(v.Validate(User{Password: "éclairs٣!"})))
+	})
+
+	t.Run("Length counts characters", func(t *testing.T) {
+		v := newValidator(DefaultPasswordOptions())
+		// 7 characters, 12 bytes
+		assert.Equal(t, []string{PasswordReasonMinLength}, reasons(v.Validate(User{Password: "Парол1!"})))
+		assert.NoError(t, v.Validate(User{Password: "Пароль1!"}))
+	})
+
+	t.Run("Default special characters are Unicode punctuation and symbols", func(t *testing.T) {
+		v := newValidator(PasswordOptions{RequireSpecialChar: true})
+		for _, password := range []string{"a!", "a~", "a€", "a¿", "a«"} {
+			assert.NoError(t, v.Validate(User{Password: password}), password)
+		}
+		assert.Equal(t, []string{PasswordReasonSpecial}, reasons(v.Validate(User{Password: "a b"})))
+	})
+
+	t.Run("Configurable special characters", func(t *testing.T) {
+		v := newValidator(PasswordOptions{RequireSpecialChar: true, SpecialChars: "!@#"})
+		assert.NoError(t, v.Validate(User{Password: "a@"}))
+		assert.Equal(t, []string{PasswordReasonSpecial}, reasons(v.Validate(User{Password: "a~"})))
+	})
+
+	t.Run("Minimum counts per class", func(t *testing.T) {
+		v := newValidator(PasswordOptions{MinDigits: 2, MinUppercase: 2, RequireLowercase: true})
+
+		err := v.Validate(User{Password: "Abc1"})
+		assert.Equal(t, []string{PasswordReasonUppercase, PasswordReasonDigit}, reasons(err))
+
+		errors := err.(validator.ValidationErrors)
+		assert.Equal(t, "Password must contain at least 2 uppercase letters", errors[0].Message)
+		assert.Equal(t, "Password must contain at least 2 numbers", errors[1].Message)
+		assert.Equal(t, 2, errors[1].Params["min_digits"])
+		assert.Equal(t, "Password must be at least 0 characters and contain at least 2 uppercase letters, "+
+			"at least one lowercase letter and at least 2 numbers", errors[0].Params["requirements"])
+
+		assert.NoError(t, v.Validate(User{Password: "ABc12"}))
+	})
+
+	t.Run("Maximum repeated characters", func(t *testing.T) {
+		v := newValidator(PasswordOptions{MaxRepeatedChars: 3})
+		assert.NoError(t, v.Validate(User{Password: "aaabbbccc"}))
+
+		errors := v.Validate(User{Password: "abééééc"}).(validator.ValidationErrors)
+		assert.Len(t, errors, 1)
+		assert.Equal(t, PasswordReasonRepeated, errors[0].Reason)
+		assert.Equal(t, "Password cannot contain the same character more than 3 times in a row", errors[0].Message)
+	})
+}
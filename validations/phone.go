@@ -0,0 +1,522 @@
+package validations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// phoneMetadataFile is the embedded numbering plan metadata
+const phoneMetadataFile = "data/phone_metadata.json"
+
+// phoneAny is the tag param accepting numbers of every supported region
+const phoneAny = "any"
+
+// Types of phone numbers detected by ParsePhoneNumber
+const (
+	PhoneTypeMobile            = "mobile"
+	PhoneTypeFixedLine         = "fixed_line"
+	PhoneTypeFixedLineOrMobile = "fixed_line_or_mobile" // Regions such as US and CA where both share the same ranges
+	PhoneTypeTollFree          = "toll_free"
+	PhoneTypePremiumRate       = "premium_rate"
+	PhoneTypeSharedCost        = "shared_cost"
+	PhoneTypePersonal          = "personal"
+	PhoneTypeVoIP              = "voip"
+	PhoneTypeUAN               = "uan" // Universal access numbers
+)
+
+// phoneTypes is the order in which the types of a region are matched, most specific first
+var phoneTypes = []string{
+	PhoneTypeTollFree,
+	PhoneTypePremiumRate,
+	PhoneTypeSharedCost,
+	PhoneTypePersonal,
+	PhoneTypeVoIP,
+	PhoneTypeUAN,
+	PhoneTypeMobile,
+	PhoneTypeFixedLine,
+	PhoneTypeFixedLineOrMobile,
+}
+
+// Reasons reported by the "phone" validation
+const (
+	PhoneReasonFormat      = "format"       // Not a phone number, e.g. contains letters
+	PhoneReasonCountryCode = "country_code" // The country calling code is missing or not supported
+	PhoneReasonCountry     = "country"      // The number belongs to a region that is not accepted
+	PhoneReasonLength      = "length"       // Invalid number of digits for the region
+	PhoneReasonPrefix      = "prefix"       // The digits are not assigned in the numbering plan of the region
+	PhoneReasonType        = "type"         // The type of the number is not accepted
+)
+
+// PhoneNumber is a phone number validated against the numbering plan of its region
+type PhoneNumber struct {
+	E164           string // Normalized form, e.g. "+15062345678"
+	Country        string // ISO 3166-1 alpha-2 code of the region, e.g. "CA"
+	Type           string // One of the PhoneType* values
+	NationalNumber string // Digits after the country calling code, without the national prefix
+}
+
+// PhoneNumberError is returned by ParsePhoneNumber for numbers that are not valid
+type PhoneNumberError struct {
+	Reason  string // One of the PhoneReason* values
+	Number  string // The number as given
+	Country string // Region the number was checked against, if known
+	E164    string // Normalized form, if the number could be read
+	Type    string // Detected type, if known
+}
+
+// Error implements the error interface
+func (e *PhoneNumberError) Error() string {
+	if e.Country != "" {
+		return fmt.Sprintf("validations: invalid phone number %q for %s: %s", e.Number, e.Country, e.Reason)
+	}
+	return fmt.Sprintf("validations: invalid phone number %q: %s", e.Number, e.Reason)
+}
+
+// phoneRegion is the numbering plan of a region, as stored in the embedded metadata
+type phoneRegion struct {
+	Region                string            `json:"region"`
+	Code                  string            `json:"code"`
+	NationalPrefix        string            `json:"national_prefix"`
+	Lengths               []int             `json:"lengths"`
+	Main                  bool              `json:"main"`                    // Region of the calling code when no leading digits match
+	LeadingDigits         []string          `json:"leading_digits"`          // Prefixes selecting this region among those sharing the calling code
+	ExcludedLeadingDigits []string          `json:"excluded_leading_digits"` // Prefixes of regions sharing the calling code that are not supported
+	Types                 map[string]string `json:"types"`                   // Patterns of each type, on the national number
+	Example               string            `json:"example"`
+
+	patterns map[string]*regexp.Regexp
+}
+
+// phoneMetadata is the embedded metadata indexed for parsing
+type phoneMetadata struct {
+	regions map[string]*phoneRegion   // By region code
+	codes   map[string][]*phoneRegion // By calling code
+}
+
+var (
+	phoneMetadataData *phoneMetadata
+	phoneMetadataOnce sync.Once
+)
+
+// loadPhoneMetadata reads the embedded metadata the first time it is needed
+func loadPhoneMetadata() *phoneMetadata {
+	phoneMetadataOnce.Do(func() {
+		data, err := dataFiles.ReadFile(phoneMetadataFile)
+		if err != nil {
+			panic("validations: cannot read embedded phone metadata: " + err.Error())
+		}
+
+		var file struct {
+			Regions []*phoneRegion `json:"regions"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			panic("validations: cannot parse embedded phone metadata: " + err.Error())
+		}
+
+		metadata := &phoneMetadata{
+			regions: make(map[string]*phoneRegion, len(file.Regions)),
+			codes:   make(map[string][]*phoneRegion),
+		}
+		for _, region := range file.Regions {
+			region.patterns = make(map[string]*regexp.Regexp, len(region.Types))
+			for name, pattern := range region.Types {
+				region.patterns[name] = regexp.MustCompile("^(?:" + pattern + ")$")
+			}
+			metadata.regions[region.Region] = region
+			metadata.codes[region.Code] = append(metadata.codes[region.Code], region)
+		}
+		phoneMetadataData = metadata
+	})
+	return phoneMetadataData
+}
+
+// PhoneRegions returns the codes of the regions supported by ParsePhoneNumber, sorted
+func PhoneRegions() []string {
+	metadata := loadPhoneMetadata()
+	regions := make([]string, 0, len(metadata.regions))
+	for region := range metadata.regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// ParsePhoneNumber validates number against the numbering plans of the embedded metadata and returns
+// it normalized. Numbers in international format start with "+" and their country calling code.
+// Numbers in national format, e.g. "(506) 234-5678" or "020 7946 0018", are accepted when countries
+// are given and are read as numbers of each of these regions in turn. Spaces, dashes, dots, slashes
+// and parentheses are ignored.
+//
+// When countries are given, numbers of other regions are rejected. An unknown region is an error.
+// Invalid numbers return a *PhoneNumberError with the reason.
+func ParsePhoneNumber(number string, countries ...string) (PhoneNumber, error) {
+	metadata := loadPhoneMetadata()
+
+	allowed, err := metadata.lookupRegions(countries)
+	if err != nil {
+		return PhoneNumber{}, err
+	}
+
+	return metadata.parse(number, allowed)
+}
+
+// lookupRegions returns the regions of the given codes, or nil for every region
+func (m *phoneMetadata) lookupRegions(countries []string) ([]*phoneRegion, error) {
+	var regions []*phoneRegion
+	for _, country := range countries {
+		if strings.EqualFold(country, phoneAny) {
+			return nil, nil
+		}
+		region, ok := m.regions[strings.ToUpper(country)]
+		if !ok {
+			return nil, fmt.Errorf("validations: unknown phone region %q", country)
+		}
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
+// parse validates number for the allowed regions, nil allowing every region
+func (m *phoneMetadata) parse(number string, allowed []*phoneRegion) (PhoneNumber, error) {
+	fail := func(reason string) (PhoneNumber, error) {
+		return PhoneNumber{}, &PhoneNumberError{Reason: reason, Number: number}
+	}
+
+	value := strings.TrimSpace(number)
+	international := strings.HasPrefix(value, "+")
+	if international {
+		value = value[1:]
+	}
+
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '/' || r == '(' || r == ')':
+		default:
+			return fail(PhoneReasonFormat)
+		}
+	}
+	// E.164 numbers have at most 15 digits, a few more are tolerated for national prefixes
+	if digits.Len() < 3 || digits.Len() > 17 {
+		return fail(PhoneReasonFormat)
+	}
+
+	if international {
+		return m.parseInternational(number, digits.String(), allowed)
+	}
+
+	if len(allowed) == 0 {
+		// Without a region there is no way to know the country calling code
+		return fail(PhoneReasonCountryCode)
+	}
+
+	// Try each region, reporting the most specific failure when none matches
+	var failure *PhoneNumberError
+	for _, region := range allowed {
+		phone, err := m.parseNational(number, digits.String(), region)
+		if err == nil {
+			return phone, nil
+		}
+		if e := err.(*PhoneNumberError); failure == nil || phoneReasonRank(e.Reason) > phoneReasonRank(failure.Reason) {
+			failure = e
+		}
+	}
+	return PhoneNumber{}, failure
+}
+
+// parseInternational validates the digits of a number in international format
+func (m *phoneMetadata) parseInternational(number, digits string, allowed []*phoneRegion) (PhoneNumber, error) {
+	// Calling codes are prefix-free, so at most one of the lengths matches
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		regions, ok := m.codes[digits[:n]]
+		if !ok {
+			continue
+		}
+
+		national := digits[n:]
+		region := selectPhoneRegion(regions, national)
+		if region == nil {
+			// A region sharing the calling code that is not in the metadata, e.g. in the Caribbean
+			return PhoneNumber{}, &PhoneNumberError{Reason: PhoneReasonCountryCode, Number: number}
+		}
+
+		// Some people keep the national prefix, e.g. "+44 (0)20 7946 0018"
+		if prefix := region.NationalPrefix; prefix != "" && strings.HasPrefix(national, prefix) {
+			if _, reason := region.match(national); reason != "" {
+				if _, reason := region.match(national[len(prefix):]); reason == "" {
+					national = national[len(prefix):]
+				}
+			}
+		}
+
+		phone, err := region.validate(number, national)
+		if err != nil {
+			return phone, err
+		}
+		if len(allowed) > 0 && !containsPhoneRegion(allowed, region) {
+			return PhoneNumber{}, &PhoneNumberError{
+				Reason:  PhoneReasonCountry,
+				Number:  number,
+				Country: region.Region,
+				E164:    phone.E164,
+				Type:    phone.Type,
+			}
+		}
+		return phone, nil
+	}
+
+	return PhoneNumber{}, &PhoneNumberError{Reason: PhoneReasonCountryCode, Number: number}
+}
+
+// parseNational validates the digits of a number in the national format of region
+func (m *phoneMetadata) parseNational(number, digits string, region *phoneRegion) (PhoneNumber, error) {
+	national := digits
+	if prefix := region.NationalPrefix; prefix != "" && strings.HasPrefix(digits, prefix) {
+		if _, reason := region.match(digits); reason != "" {
+			national = digits[len(prefix):]
+		}
+	}
+
+	phone, err := region.validate(number, national)
+	if err != nil {
+		return phone, err
+	}
+
+	// Regions sharing a calling code also share the format of their numbers, e.g. a US number is
+	// read as a valid CA number until its area code is checked
+	if actual := selectPhoneRegion(m.codes[region.Code], national); actual != region {
+		e := &PhoneNumberError{Reason: PhoneReasonCountry, Number: number, E164: phone.E164, Type: phone.Type}
+		if actual != nil {
+			e.Country = actual.Region
+		}
+		return PhoneNumber{}, e
+	}
+
+	return phone, nil
+}
+
+// validate checks the national number against the numbering plan of the region
+func (r *phoneRegion) validate(number, national string) (PhoneNumber, error) {
+	phoneType, reason := r.match(national)
+	if reason != "" {
+		return PhoneNumber{}, &PhoneNumberError{
+			Reason:  reason,
+			Number:  number,
+			Country: r.Region,
+			E164:    "+" + r.Code + national,
+		}
+	}
+
+	return PhoneNumber{
+		E164:           "+" + r.Code + national,
+		Country:        r.Region,
+		Type:           phoneType,
+		NationalNumber: national,
+	}, nil
+}
+
+// match returns the type of the national number, or the reason it is not valid in the region
+func (r *phoneRegion) match(national string) (string, string) {
+	validLength := false
+	for _, length := range r.Lengths {
+		if len(national) == length {
+			validLength = true
+			break
+		}
+	}
+	if !validLength {
+		return "", PhoneReasonLength
+	}
+
+	for _, name := range phoneTypes {
+		if pattern, ok := r.patterns[name]; ok && pattern.MatchString(national) {
+			return name, ""
+		}
+	}
+	return "", PhoneReasonPrefix
+}
+
+// selectPhoneRegion returns the region of a national number among the regions sharing a calling code
+func selectPhoneRegion(regions []*phoneRegion, national string) *phoneRegion {
+	if len(regions) == 1 {
+		return regions[0]
+	}
+
+	var main *phoneRegion
+	for _, region := range regions {
+		for _, prefix := range region.LeadingDigits {
+			if strings.HasPrefix(national, prefix) {
+				return region
+			}
+		}
+		if region.Main {
+			main = region
+		}
+	}
+
+	if main != nil {
+		for _, prefix := range main.ExcludedLeadingDigits {
+			if strings.HasPrefix(national, prefix) {
+				return nil
+			}
+		}
+	}
+	return main
+}
+
+// containsPhoneRegion reports whether region is one of regions
+func containsPhoneRegion(regions []*phoneRegion, region *phoneRegion) bool {
+	for _, r := range regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// phoneReasonRank orders the reasons from the least to the most specific, to report the failure
+// of the region that came closest to accepting a number in national format
+func phoneReasonRank(reason string) int {
+	switch reason {
+	case PhoneReasonLength:
+		return 1
+	case PhoneReasonPrefix:
+		return 2
+	case PhoneReasonCountry:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// phoneTypeAccepted reports whether a number of the detected type is one of the accepted types.
+// Numbers of regions that do not tell mobile and fixed line numbers apart are accepted as either.
+func phoneTypeAccepted(detected string, accepted []string) bool {
+	for _, t := range accepted {
+		if t == detected {
+			return true
+		}
+		if detected == PhoneTypeFixedLineOrMobile && (t == PhoneTypeMobile || t == PhoneTypeFixedLine) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddPhoneValidation registers the "phone" tag, which validates phone numbers against the numbering plans
+// of the embedded metadata: the number of digits, the assigned prefixes and the type of number.
+// The param lists the accepted regions, separated by spaces since "|" separates alternative tags:
+//
+//	type Contact struct {
+//	    Phone  string `validate:"phone=CA US"`        // Canadian or US numbers
+//	    Mobile string `validate:"phone=any mobile"`   // Mobile numbers of any supported region
+//	    Office string `validate:"omitempty,phone=GB"` // UK numbers, optional
+//	}
+//
+// Without regions, or with "any", numbers must be in international format, starting with "+" and the
+// country calling code. With regions, numbers can also be in their national format. Type names such
+// as "mobile", "fixed_line" or "toll_free" (see the PhoneType* values) restrict the accepted types.
+// Unknown regions or types panic.
+//
+// Invalid numbers are reported with a constraint for each failure, "phone_" followed by one of the PhoneReason*
+// values, e.g. "phone_country" or "phone_length", which is also the reason. Messages can be set for a single
+// failure with these constraints, and use these params:
+//   - {e164}: the normalized number, when it could be read
+//   - {type}: the detected type, when known
+//   - {country}: the region the number was checked against, when known
+//   - {countries}: the accepted regions, separated by spaces, or "any"
+//   - {types}: the accepted types, separated by spaces, for the "type" reason
+func AddPhoneValidation(v *validator.Validator) error {
+	return v.RegisterViolationValidation("phone", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		metadata := loadPhoneMetadata()
+
+		var countries, types []string
+		for _, word := range strings.Fields(fl.Param()) {
+			if isPhoneType(word) {
+				types = append(types, word)
+			} else {
+				countries = append(countries, word)
+			}
+		}
+
+		allowed, err := metadata.lookupRegions(countries)
+		if err != nil {
+			panic(fmt.Sprintf("validations: phone on field '%s': %s", fl.FieldName(), strings.TrimPrefix(err.Error(), "validations: ")))
+		}
+
+		params := map[string]interface{}{
+			"countries": phoneAny,
+			"types":     strings.Join(types, " "),
+		}
+		if len(allowed) > 0 {
+			names := make([]string, len(allowed))
+			for i, region := range allowed {
+				names[i] = region.Region
+			}
+			params["countries"] = strings.Join(names, " ")
+		}
+
+		if fl.Field().Kind() != reflect.String {
+			return []validator.Violation{phoneViolation(PhoneReasonFormat, params)}
+		}
+
+		phone, err := metadata.parse(fl.Field().String(), allowed)
+		if err != nil {
+			e := err.(*PhoneNumberError)
+			params["e164"] = e.E164
+			params["type"] = e.Type
+			params["country"] = e.Country
+			return []validator.Violation{phoneViolation(e.Reason, params)}
+		}
+
+		if len(types) > 0 && !phoneTypeAccepted(phone.Type, types) {
+			params["e164"] = phone.E164
+			params["type"] = phone.Type
+			params["country"] = phone.Country
+			return []validator.Violation{phoneViolation(PhoneReasonType, params)}
+		}
+
+		return nil
+	})
+}
+
+// phoneViolation returns the violation of a failure of the "phone" validation, with its own constraint
+func phoneViolation(reason string, params map[string]interface{}) validator.Violation {
+	return validator.Violation{
+		Reason:     reason,
+		Message:    phoneMessages[reason],
+		Params:     params,
+		Constraint: "phone_" + reason,
+	}
+}
+
+// phoneMessages are the default messages of each reason of the "phone" validation
+var phoneMessages = map[string]string{
+	PhoneReasonFormat:      "{field} must be a valid phone number",
+	PhoneReasonCountryCode: "{field} must include a valid country code",
+	PhoneReasonCountry:     "{field} must be a phone number from {countries}",
+	PhoneReasonLength:      "{field} has an invalid number of digits for {country}",
+	PhoneReasonPrefix:      "{field} is not a valid phone number for {country}",
+	PhoneReasonType:        "{field} must be a phone number of type {types}",
+}
+
+// isPhoneType reports whether word is one of the PhoneType* values
+func isPhoneType(word string) bool {
+	for _, t := range phoneTypes {
+		if word == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package validations
+
+import (
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePhoneNumber(t *testing.T) {
+	t.Run("International format", func(t *testing.T) {
+		tests := []struct {
+			number string
+			phone  PhoneNumber
+		}{
+			{"+1 (506) 234-5678", PhoneNumber{E164: "+15062345678", Country: "CA", Type: PhoneTypeFixedLineOrMobile, NationalNumber: "5062345678"}},
+			{"+1 201-555-0123", PhoneNumber{E164: "+12015550123", Country: "US", Type: PhoneTypeFixedLineOrMobile, NationalNumber: "2015550123"}},
+			{"+1 800 234 5678", PhoneNumber{E164: "+18002345678", Country: "US", Type: PhoneTypeTollFree, NationalNumber: "8002345678"}},
+			{"+44 7400 123456", PhoneNumber{E164: "+447400123456", Country: "GB", Type: PhoneTypeMobile, NationalNumber: "7400123456"}},
+			{"+44 (0)20 7946 0018", PhoneNumber{E164: "+442079460018", Country: "GB", Type: PhoneTypeFixedLine, NationalNumber: "2079460018"}},
+			{"+33 6 12 34 56 78", PhoneNumber{E164: "+33612345678", Country: "FR", Type: PhoneTypeMobile, NationalNumber: "612345678"}},
+			{"+39 06 1234 5678", PhoneNumber{E164: "+390612345678", Country: "IT", Type: PhoneTypeFixedLine, NationalNumber: "0612345678"}},
+			{"+353 85 012 3456", PhoneNumber{E164: "+353850123456", Country: "IE", Type: PhoneTypeMobile, NationalNumber: "850123456"}},
+			{"+81 90-1234-5678", PhoneNumber{E164: "+819012345678", Country: "JP", Type: PhoneTypeMobile, NationalNumber: "9012345678"}},
+		}
+
+		for _, tt := range tests {
+			phone, err := ParsePhoneNumber(tt.number)
+			assert.NoError(t, err, tt.number)
+			assert.Equal(t, tt.phone, phone, tt.number)
+		}
+	})
+
+	t.Run("National format", func(t *testing.T) {
+		phone, err := ParsePhoneNumber("(506) 234-5678", "CA")
+		assert.NoError(t, err)
+		assert.Equal(t, "+15062345678", phone.E164)
+
+		phone, err = ParsePhoneNumber("1-506-234-5678", "CA")
+		assert.NoError(t, err)
+		assert.Equal(t, "+15062345678", phone.E164)
+
+		phone, err = ParsePhoneNumber("020 7946 0018", "gb")
+		assert.NoError(t, err)
+		assert.Equal(t, "+442079460018", phone.E164)
+
+		// Tried against each region in turn
+		phone, err = ParsePhoneNumber("06 12 34 56 78", "GB", "FR")
+		assert.NoError(t, err)
+		assert.Equal(t, "FR", phone.Country)
+		assert.Equal(t, PhoneTypeMobile, phone.Type)
+	})
+
+	t.Run("Invalid numbers", func(t *testing.T) {
+		tests := []struct {
+			number    string
+			countries []string
+			reason    string
+			country   string
+		}{
+			{"call me", nil, PhoneReasonFormat, ""},
+			{"12", []string{"US"}, PhoneReasonFormat, ""},
+			{"+1 555 0123 x12", nil, PhoneReasonFormat, ""},
+			{"506 234 5678", nil, PhoneReasonCountryCode, ""},
+			{"+999 1234 5678", nil, PhoneReasonCountryCode, ""},
+			{"+1 876 234 5678", nil, PhoneReasonCountryCode, ""}, // Jamaica shares +1 and is not supported
+			{"+1 506 234 567", nil, PhoneReasonLength, "CA"},
+			{"+44 7400 1234567", nil, PhoneReasonLength, "GB"},
+			{"+44 7400 12345", nil, PhoneReasonPrefix, "GB"},
+			{"+1 506 134 5678", nil, PhoneReasonPrefix, "CA"},
+			{"+33 0 12 34 56 78", nil, PhoneReasonPrefix, "FR"},
+			{"+1 201 555 0123", []string{"CA"}, PhoneReasonCountry, "US"},
+			{"201 555 0123", []string{"CA"}, PhoneReasonCountry, "US"},
+			{"+44 7400 123456", []string{"CA", "US"}, PhoneReasonCountry, "GB"},
+		}
+
+		for _, tt := range tests {
+			_, err := ParsePhoneNumber(tt.number, tt.countries...)
+			if e, ok := err.(*PhoneNumberError); assert.True(t, ok, tt.number) {
+				assert.Equal(t, tt.reason, e.Reason, tt.number)
+				assert.Equal(t, tt.country, e.Country, tt.number)
+			}
+		}
+	})
+
+	t.Run("Examples of every region are valid", func(t *testing.T) {
+		metadata := loadPhoneMetadata()
+		for _, code := range PhoneRegions() {
+			region := metadata.regions[code]
+			phone, err := ParsePhoneNumber("+"+region.Code+region.Example, code)
+			assert.NoError(t, err, code)
+			assert.Equal(t, code, phone.Country)
+		}
+	})
+
+	t.Run("Unknown region", func(t *testing.T) {
+		_, err := ParsePhoneNumber("+15062345678", "XX")
+		assert.EqualError(t, err, `validations: unknown phone region "XX"`)
+	})
+}
+
+func TestPhoneValidation(t *testing.T) {
+	type Contact struct {
+		Phone  string `json:"phone" validate:"omitempty,phone=CA US"`
+		Mobile string `json:"mobile" validate:"omitempty,phone=any mobile"`
+		Any    string `json:"any" validate:"omitempty,phone"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddPhoneValidation(v))
+
+	t.Run("Valid numbers", func(t *testing.T) {
+		assert.NoError(t, v.Validate(Contact{
+			Phone:  "(506) 234-5678",
+			Mobile: "+44 7400 123456",
+			Any:    "+81 3-1234-5678",
+		}))
+		assert.NoError(t, v.Validate(Contact{Mobile: "+1 506 234 5678"}))
+	})
+
+	t.Run("Wrong country", func(t *testing.T) {
+		errs := v.Validate(Contact{Phone: "+44 7400 123456"}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "phone_country", errs[0].Constraint)
+		assert.Equal(t, PhoneReasonCountry, errs[0].Reason)
+		assert.Equal(t, "+447400123456", errs[0].Params["e164"])
+		assert.Equal(t, PhoneTypeMobile, errs[0].Params["type"])
+		assert.Equal(t, "GB", errs[0].Params["country"])
+		assert.Equal(t, "phone must be a phone number from CA US", errs[0].Message)
+	})
+
+	t.Run("Wrong length", func(t *testing.T) {
+		errs := v.Validate(Contact{Phone: "506 234 567"}).(validator.ValidationErrors)
+		assert.Equal(t, "phone_length", errs[0].Constraint)
+		assert.Equal(t, PhoneReasonLength, errs[0].Reason)
+		assert.Equal(t, "phone has an invalid number of digits for CA", errs[0].Message)
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		errs := v.Validate(Contact{Mobile: "+44 20 7946 0018"}).(validator.ValidationErrors)
+		assert.Equal(t, "phone_type", errs[0].Constraint)
+		assert.Equal(t, PhoneReasonType, errs[0].Reason)
+		assert.Equal(t, PhoneTypeFixedLine, errs[0].Params["type"])
+		assert.Equal(t, "mobile must be a phone number of type mobile", errs[0].Message)
+	})
+
+	t.Run("Missing country code", func(t *testing.T) {
+		errs := v.Validate(Contact{Any: "020 7946 0018"}).(validator.ValidationErrors)
+		assert.Equal(t, PhoneReasonCountryCode, errs[0].Reason)
+		assert.Equal(t, "any must include a valid country code", errs[0].Message)
+	})
+
+	t.Run("Message per constraint", func(t *testing.T) {
+		v := validator.New()
+		v.UseJsonTagName()
+		assert.NoError(t, AddPhoneValidation(v))
+		v.SetDefaultTagMessage("phone_country", "We only call {countries} numbers, not {e164}")
+		v.SetDefaultTagCode("phone_length", "PHONE_LENGTH")
+
+		errs := v.Validate(Contact{Phone: "+33 6 12 34 56 78"}).(validator.ValidationErrors)
+		assert.Equal(t, "We only call CA US numbers, not +33612345678", errs[0].Message)
+
+		errs = v.Validate(Contact{Phone: "506 234 567"}).(validator.ValidationErrors)
+		assert.Equal(t, "PHONE_LENGTH", errs[0].Code)
+	})
+
+	t.Run("Unknown region panics", func(t *testing.T) {
+		type Invalid struct {
+			Phone string `validate:"phone=ZZ"`
+		}
+		assert.Panics(t, func() { _ = v.Validate(Invalid{Phone: "+15062345678"}) })
+	})
+}
@@ -0,0 +1,154 @@
+package validations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// postalCodesFile is the embedded table of postal code formats
+const postalCodesFile = "data/postal_codes.json"
+
+// Reasons reported by the "postcode_for" validation
+const (
+	PostalCodeReasonFormat  = "format"   // The postal code does not have the format of the country
+	PostalCodeReasonNotUsed = "not_used" // The country does not use postal codes, but one was given
+)
+
+// PostalCodeFormat is the format of the postal codes of a country
+type PostalCodeFormat struct {
+	Country string // ISO 3166-1 alpha-2 code of the country, e.g. "CA"
+	Example string // Example of a postal code, e.g. "K1A 0B1", empty if the country has no postal codes
+	Pattern string // Regular expression matching postal codes, ignoring case, empty if the country has no postal codes
+
+	re *regexp.Regexp
+}
+
+// HasPostalCodes reports whether the country uses postal codes
+func (f PostalCodeFormat) HasPostalCodes() bool {
+	return f.re != nil
+}
+
+// Match reports whether code is a postal code of the country, ignoring case and surrounding spaces.
+// Countries that do not use postal codes only match an empty code.
+func (f PostalCodeFormat) Match(code string) bool {
+	code = strings.TrimSpace(code)
+	if f.re == nil {
+		return code == ""
+	}
+	return f.re.MatchString(strings.ToUpper(code))
+}
+
+var (
+	postalCodeFormats     map[string]PostalCodeFormat
+	postalCodeFormatsOnce sync.Once
+)
+
+// loadPostalCodeFormats reads the embedded table the first time it is needed
+func loadPostalCodeFormats() map[string]PostalCodeFormat {
+	postalCodeFormatsOnce.Do(func() {
+		data, err := dataFiles.ReadFile(postalCodesFile)
+		if err != nil {
+			panic("validations: cannot read embedded postal code formats: " + err.Error())
+		}
+
+		var file struct {
+			Countries []PostalCodeFormat `json:"countries"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			panic("validations: cannot parse embedded postal code formats: " + err.Error())
+		}
+
+		formats := make(map[string]PostalCodeFormat, len(file.Countries))
+		for _, format := range file.Countries {
+			if format.Pattern != "" {
+				format.re = regexp.MustCompile("^(?:" + format.Pattern + ")$")
+			}
+			formats[format.Country] = format
+		}
+		postalCodeFormats = formats
+	})
+	return postalCodeFormats
+}
+
+// LookupPostalCodeFormat returns the postal code format of a country, given as an ISO 3166-1 alpha-2 code
+// in any case. It returns false for countries that are not in the embedded table.
+func LookupPostalCodeFormat(country string) (PostalCodeFormat, bool) {
+	format, ok := loadPostalCodeFormats()[strings.ToUpper(strings.TrimSpace(country))]
+	return format, ok
+}
+
+// AddPostalCodeValidation registers the "postcode_for" tag, which validates postal codes against the format
+// of the country held by another field of the same struct. The country field is referenced like eqfield
+// does and holds an ISO 3166-1 alpha-2 code, in any case:
+//
+//	type Address struct {
+//	    Country    string `validate:"required,iso3166_1_alpha2"`
+//	    PostalCode string `validate:"postcode_for=Country"`
+//	}
+//
+// Postal codes are compared ignoring case and surrounding spaces. Countries that do not use postal codes,
+// such as "AE" or "HK", only accept an empty postal code. Postal codes are not checked when the country is
+// empty or not in the embedded table, so the country field should be validated on its own. An unknown field
+// panics.
+//
+// Invalid postal codes are reported with the constraint "postcode_for" and one of the PostalCodeReason*
+// values as reason, so messages can be set with the "postcode_for.format" and "postcode_for.not_used" keys.
+// Messages can use these params:
+//   - {country}: the country code, in upper case
+//   - {example}: an example of a valid postal code, e.g. "K1A 0B1"
+//   - {pattern}: the regular expression of the format
+//   - {other_field}: the name of the country field
+func AddPostalCodeValidation(v *validator.Validator) error {
+	return v.RegisterViolationValidation("postcode_for", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		name := strings.TrimSpace(fl.Param())
+		if name == "" {
+			panic(fmt.Sprintf("validations: postcode_for on field '%s' has no country field", fl.FieldName()))
+		}
+
+		country, kind, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), name)
+		if !found {
+			panic(fmt.Sprintf("validations: postcode_for on field '%s' references unknown field '%s'", fl.FieldName(), name))
+		}
+		if kind != reflect.String {
+			return nil
+		}
+
+		format, ok := LookupPostalCodeFormat(country.String())
+		if !ok {
+			return nil
+		}
+
+		code := ""
+		if fl.Field().Kind() == reflect.String {
+			code = fl.Field().String()
+		}
+		if format.Match(code) {
+			return nil
+		}
+
+		violation := validator.Violation{
+			Reason:  PostalCodeReasonFormat,
+			Message: "{field} must be a valid postal code for {country}, e.g. {example}",
+			Params: map[string]interface{}{
+				"country":     format.Country,
+				"example":     format.Example,
+				"pattern":     format.Pattern,
+				"other_field": name,
+			},
+		}
+		if !format.HasPostalCodes() {
+			violation.Reason = PostalCodeReasonNotUsed
+			violation.Message = "{field} must be empty, {country} does not use postal codes"
+		}
+
+		return []validator.Violation{violation}
+	})
+}
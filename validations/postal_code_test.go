@@ -0,0 +1,115 @@
+package validations
+
+import (
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupPostalCodeFormat(t *testing.T) {
+	t.Run("Formats", func(t *testing.T) {
+		tests := []struct {
+			country string
+			valid   []string
+			invalid []string
+		}{
+			{"CA", []string{"K1A 0B1", "k1a0b1", " M5V 3L9 "}, []string{"K1A 0B", "D1A 0B1", "12345"}},
+			{"US", []string{"95014", "95014-2083"}, []string{"9501", "95014-20", "K1A 0B1"}},
+			{"GB", []string{"SW1A 1AA", "EC1A1BB", "M1 1AE", "GIR 0AA"}, []string{"SW1A", "QA1 1AA"}},
+			{"NL", []string{"1012 JS", "1012js"}, []string{"0123 AB", "1012"}},
+			{"JP", []string{"154-0023", "1540023"}, []string{"154-002"}},
+			{"PT", []string{"2725-079"}, []string{"2725079"}},
+		}
+
+		for _, tt := range tests {
+			format, ok := LookupPostalCodeFormat(tt.country)
+			if !assert.True(t, ok, tt.country) {
+				continue
+			}
+			assert.True(t, format.HasPostalCodes(), tt.country)
+			for _, code := range tt.valid {
+				assert.True(t, format.Match(code), "%s %q", tt.country, code)
+			}
+			for _, code := range tt.invalid {
+				assert.False(t, format.Match(code), "%s %q", tt.country, code)
+			}
+		}
+	})
+
+	t.Run("Examples match their format", func(t *testing.T) {
+		for country, format := range loadPostalCodeFormats() {
+			assert.Equal(t, format.HasPostalCodes(), format.Example != "", country)
+			assert.True(t, format.Match(format.Example), country)
+		}
+	})
+
+	t.Run("Country without postal codes", func(t *testing.T) {
+		format, ok := LookupPostalCodeFormat("hk")
+		assert.True(t, ok)
+		assert.Equal(t, "HK", format.Country)
+		assert.False(t, format.HasPostalCodes())
+		assert.True(t, format.Match(""))
+		assert.False(t, format.Match("00000"))
+	})
+
+	t.Run("Unknown country", func(t *testing.T) {
+		_, ok := LookupPostalCodeFormat("XX")
+		assert.False(t, ok)
+	})
+}
+
+func TestPostalCodeValidation(t *testing.T) {
+	type Address struct {
+		Country    string `json:"country"`
+		PostalCode string `json:"postal_code" validate:"postcode_for=Country"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddPostalCodeValidation(v))
+
+	t.Run("Valid postal codes", func(t *testing.T) {
+		assert.NoError(t, v.Validate(Address{Country: "CA", PostalCode: "K1A 0B1"}))
+		assert.NoError(t, v.Validate(Address{Country: "us", PostalCode: "95014"}))
+		assert.NoError(t, v.Validate(Address{Country: "AE", PostalCode: ""}))
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		errs := v.Validate(Address{Country: "CA", PostalCode: "95014"}).(validator.ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "postal_code", errs[0].Path)
+		assert.Equal(t, "postcode_for", errs[0].Constraint)
+		assert.Equal(t, PostalCodeReasonFormat, errs[0].Reason)
+		assert.Equal(t, "K1A 0B1", errs[0].Params["example"])
+		assert.Equal(t, "Country", errs[0].Params["other_field"])
+		assert.Equal(t, "postal_code must be a valid postal code for CA, e.g. K1A 0B1", errs[0].Message)
+	})
+
+	t.Run("Country without postal codes", func(t *testing.T) {
+		errs := v.Validate(Address{Country: "AE", PostalCode: "12345"}).(validator.ValidationErrors)
+		assert.Equal(t, PostalCodeReasonNotUsed, errs[0].Reason)
+		assert.Equal(t, "postal_code must be empty, AE does not use postal codes", errs[0].Message)
+	})
+
+	t.Run("Unknown or empty country is not checked", func(t *testing.T) {
+		assert.NoError(t, v.Validate(Address{Country: "XX", PostalCode: "anything"}))
+		assert.NoError(t, v.Validate(Address{PostalCode: "anything"}))
+	})
+
+	t.Run("Custom message", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPostalCodeValidation(v))
+		v.SetDefaultTagMessage("postcode_for.format", "Expected a postal code like {example}")
+
+		errs := v.Validate(Address{Country: "GB", PostalCode: "12345"}).(validator.ValidationErrors)
+		assert.Equal(t, "Expected a postal code like SW1A 1AA", errs[0].Message)
+	})
+
+	t.Run("Unknown field panics", func(t *testing.T) {
+		type Invalid struct {
+			PostalCode string `validate:"postcode_for=Nation"`
+		}
+		assert.Panics(t, func() { _ = v.Validate(Invalid{PostalCode: "95014"}) })
+	})
+}
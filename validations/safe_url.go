@@ -0,0 +1,436 @@
+package validations
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// DefaultURLLookupTimeout is the maximum duration of the DNS lookup of a URL host
+const DefaultURLLookupTimeout = 2 * time.Second
+
+// DefaultURLSchemes are the schemes accepted when URLOptions.Schemes is empty and the tag has no param
+var DefaultURLSchemes = []string{"https"}
+
+// Reasons reported by the "safe_url" validation
+const (
+	URLReasonFormat     = "format"      // Not an absolute URL with a host
+	URLReasonScheme     = "scheme"      // The scheme is not accepted
+	URLReasonUserinfo   = "userinfo"    // The URL contains a username or password
+	URLReasonPort       = "port"        // The port is not the default port of the scheme, nor an allowed port
+	URLReasonHost       = "host"        // The host is not a public domain name, e.g. "intranet"
+	URLReasonIPEncoding = "ip_encoding" // The host is an IPv4 address in decimal, octal, hex or short form
+	URLReasonLoopback   = "loopback"    // The host is a loopback address or "localhost"
+	URLReasonLinkLocal  = "link_local"  // The host is a link-local address
+	URLReasonPrivate    = "private"     // The host is in a private network, e.g. 10.0.0.0/8 or fc00::/7
+	URLReasonCGNAT      = "cgnat"       // The host is in the shared address space of carrier-grade NAT, 100.64.0.0/10
+	URLReasonMetadata   = "metadata"    // The host is a cloud metadata service, e.g. 169.254.169.254
+	URLReasonReserved   = "reserved"    // The host is an unspecified, multicast, documentation or other reserved address
+	URLReasonUnresolved = "unresolved"  // The host could not be resolved
+)
+
+// urlMessages are the default messages of each reason
+var urlMessages = map[string]string{
+	URLReasonFormat:     "{field} must be a valid URL",
+	URLReasonScheme:     "{field} must use one of these schemes: {schemes}",
+	URLReasonUserinfo:   "{field} cannot contain a username or password",
+	URLReasonPort:       "{field} cannot use port {port}",
+	URLReasonHost:       "{field} must use a public domain name",
+	URLReasonIPEncoding: "{field} must write IP addresses in standard form",
+	URLReasonLoopback:   "{field} cannot point to this server",
+	URLReasonLinkLocal:  "{field} cannot point to a link-local address",
+	URLReasonPrivate:    "{field} cannot point to a private network",
+	URLReasonCGNAT:      "{field} cannot point to a carrier-grade NAT address",
+	URLReasonMetadata:   "{field} cannot point to a cloud metadata service",
+	URLReasonReserved:   "{field} cannot point to a reserved address",
+	URLReasonUnresolved: "{field} must use a host that can be resolved",
+}
+
+// defaultURLPorts are the ports that URLs of a scheme can always use
+var defaultURLPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// metadataAddrs are the addresses of the metadata services of cloud providers
+var metadataAddrs = []netip.Addr{
+	netip.MustParseAddr("169.254.169.254"), // AWS, GCP, Azure, Oracle, DigitalOcean
+	netip.MustParseAddr("169.254.170.2"),   // AWS ECS task metadata
+	netip.MustParseAddr("100.100.100.200"), // Alibaba Cloud
+	netip.MustParseAddr("fd00:ec2::254"),   // AWS IPv6
+}
+
+// metadataHosts are the host names of the metadata services of cloud providers
+var metadataHosts = []string{"metadata.google.internal", "metadata.goog", "metadata.azure.internal"}
+
+// cgnatPrefix is the shared address space of carrier-grade NAT (RFC 6598)
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// reservedPrefixes are the ranges that are not reachable on the public internet and not covered by a more
+// specific reason
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This network"
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // Documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, including the broadcast address
+	netip.MustParsePrefix("::/96"),           // IPv4-compatible addresses, deprecated
+	netip.MustParsePrefix("100::/64"),        // Discard
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+}
+
+// Prefixes of IPv6 addresses embedding an IPv4 address that is reached through a translator
+var (
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96") // NAT64, the IPv4 address is in the last 4 bytes
+	sixToFour   = netip.MustParsePrefix("2002::/16")    // 6to4, the IPv4 address follows the prefix
+	teredo      = netip.MustParsePrefix("2001::/32")    // Teredo, the client IPv4 address is in the last 4 bytes, inverted
+)
+
+// URLResolver resolves the host of a URL to its IP addresses. *net.Resolver implements it.
+type URLResolver interface {
+	// LookupNetIP returns the addresses of host, network being "ip"
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// URLOptions configures the "safe_url" validation
+type URLOptions struct {
+	Schemes       []string      // Accepted schemes, defaults to DefaultURLSchemes. The tag param replaces them.
+	Ports         []int         // Ports accepted in addition to the default port of the scheme
+	Resolver      URLResolver   // Checks every address of the host; nil only checks hosts that are IP addresses
+	LookupTimeout time.Duration // Maximum duration of the DNS lookup, defaults to DefaultURLLookupTimeout
+	Async         bool          // Check URLs concurrently with validator.RunAsync, for slices of URLs
+}
+
+// DefaultURLOptions returns options accepting https URLs on the default port, with hosts resolved with
+// net.DefaultResolver
+func DefaultURLOptions() URLOptions {
+	return URLOptions{
+		Schemes:  DefaultURLSchemes,
+		Resolver: net.DefaultResolver,
+	}
+}
+
+// UnsafeURLError is returned by CheckSafeURL for URLs that are not safe to request
+type UnsafeURLError struct {
+	Reason string // One of the URLReason* values
+	URL    string // The URL as given
+	Host   string // Host of the URL, if it could be parsed
+	IP     string // Address the host is or resolves to, when the reason is about an address
+}
+
+// Error implements the error interface
+func (e *UnsafeURLError) Error() string {
+	if e.IP != "" && e.IP != e.Host {
+		return fmt.Sprintf("validations: unsafe URL %q: %s (%s resolves to %s)", e.URL, e.Reason, e.Host, e.IP)
+	}
+	return fmt.Sprintf("validations: unsafe URL %q: %s", e.URL, e.Reason)
+}
+
+// CheckSafeURL checks that rawURL is safe for a server to request, e.g. a webhook URL submitted by a user.
+// It returns an *UnsafeURLError when it is not, or when its host cannot be resolved with options.Resolver.
+// The lookup uses ctx, limited to options.LookupTimeout.
+//
+// A URL is safe when it uses an accepted scheme, has no username or password, uses the default port of its
+// scheme or one of options.Ports, and its host is a public domain name or IP address. Hosts that are
+// IP addresses are checked with UnsafeIPReason, as are all the addresses a domain name resolves to.
+//
+// Example:
+//
+//	err := validations.CheckSafeURL(ctx, "https://0x7f000001/admin", validations.DefaultURLOptions())
+//	// err.(*validations.UnsafeURLError).Reason: "ip_encoding"
+func CheckSafeURL(ctx context.Context, rawURL string, options URLOptions) error {
+	return checkSafeURL(ctx, rawURL, options.Schemes, options)
+}
+
+// checkSafeURL checks rawURL with the given accepted schemes
+func checkSafeURL(ctx context.Context, rawURL string, schemes []string, options URLOptions) error {
+	if len(schemes) == 0 {
+		schemes = DefaultURLSchemes
+	}
+	fail := func(reason, host, ip string) error {
+		return &UnsafeURLError{Reason: reason, URL: rawURL, Host: host, IP: ip}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Opaque != "" {
+		return fail(URLReasonFormat, "", "")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	scheme := strings.ToLower(u.Scheme)
+	accepted := false
+	for _, s := range schemes {
+		accepted = accepted || strings.EqualFold(s, scheme)
+	}
+	if !accepted {
+		return fail(URLReasonScheme, host, "")
+	}
+
+	if u.User != nil {
+		return fail(URLReasonUserinfo, host, "")
+	}
+
+	if port := u.Port(); port != "" && port != defaultURLPorts[scheme] {
+		n, _ := strconv.Atoi(port)
+		allowed := false
+		for _, p := range options.Ports {
+			allowed = allowed || p == n
+		}
+		if !allowed {
+			return fail(URLReasonPort, host, "")
+		}
+	}
+
+	if host == "" {
+		return fail(URLReasonFormat, host, "")
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if reason := UnsafeIPReason(addr); reason != "" {
+			return fail(reason, host, addr.WithZone("").String())
+		}
+		return nil
+	}
+
+	if reason := unsafeHostReason(host); reason != "" {
+		return fail(reason, host, "")
+	}
+
+	if options.Resolver == nil {
+		return nil
+	}
+
+	timeout := options.LookupTimeout
+	if timeout <= 0 {
+		timeout = DefaultURLLookupTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addrs, err := options.Resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fail(URLReasonUnresolved, host, "")
+	}
+	// Every address is checked, as the client requesting the URL may connect to any of them
+	for _, addr := range addrs {
+		if reason := UnsafeIPReason(addr); reason != "" {
+			return fail(reason, host, addr.WithZone("").String())
+		}
+	}
+	return nil
+}
+
+// unsafeHostReason returns the reason a host that is not an IP address is not safe, or an empty string
+func unsafeHostReason(host string) string {
+	if isNumericHost(host) {
+		return URLReasonIPEncoding
+	}
+
+	for _, name := range metadataHosts {
+		if host == name {
+			return URLReasonMetadata
+		}
+	}
+
+	switch {
+	case host == "localhost" || strings.HasSuffix(host, ".localhost"):
+		return URLReasonLoopback
+	case strings.HasSuffix(host, ".local"):
+		return URLReasonLinkLocal
+	case strings.HasSuffix(host, ".internal") || strings.HasSuffix(host, ".home.arpa"):
+		return URLReasonPrivate
+	}
+
+	// Names that are not ASCII can be mapped to other names by resolvers, e.g. full width digits
+	for i := 0; i < len(host); i++ {
+		if host[i] >= 0x80 {
+			return URLReasonHost
+		}
+	}
+	if !isFQDN(host) {
+		return URLReasonHost
+	}
+	return ""
+}
+
+// isNumericHost reports whether every label of host is a number, which some resolvers and clients read as
+// an IPv4 address, e.g. "2130706433", "0x7f.1" or "0177.0.0.1"
+func isNumericHost(host string) bool {
+	for _, label := range strings.Split(host, ".") {
+		digits := label
+		hex := strings.HasPrefix(label, "0x")
+		if hex {
+			digits = label[2:]
+		}
+		if digits == "" && !hex {
+			return false
+		}
+		for _, r := range digits {
+			if !(r >= '0' && r <= '9') && !(hex && r >= 'a' && r <= 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// UnsafeIPReason returns the reason a server should not connect to addr, as one of the URLReason* values,
+// or an empty string for public addresses. IPv4 addresses mapped or translated to IPv6 are checked as
+// IPv4 addresses, as are the client addresses of Teredo addresses.
+//
+// It can be used by the HTTP client requesting the URLs to check the addresses it connects to, as DNS
+// records can change between validation and request:
+//
+//	dialer := &net.Dialer{
+//	    Control: func(network, address string, c syscall.RawConn) error {
+//	        addrPort, err := netip.ParseAddrPort(address)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        if reason := validations.UnsafeIPReason(addrPort.Addr()); reason != "" {
+//	            return fmt.Errorf("connection to %s blocked: %s", address, reason)
+//	        }
+//	        return nil
+//	    },
+//	}
+func UnsafeIPReason(addr netip.Addr) string {
+	addr = addr.WithZone("").Unmap()
+	if addr.Is6() {
+		if nat64Prefix.Contains(addr) {
+			b := addr.As16()
+			addr = netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]})
+		} else if sixToFour.Contains(addr) {
+			b := addr.As16()
+			addr = netip.AddrFrom4([4]byte{b[2], b[3], b[4], b[5]})
+		} else if teredo.Contains(addr) {
+			b := addr.As16()
+			addr = netip.AddrFrom4([4]byte{^b[12], ^b[13], ^b[14], ^b[15]})
+		}
+	}
+
+	for _, metadata := range metadataAddrs {
+		if addr == metadata {
+			return URLReasonMetadata
+		}
+	}
+
+	switch {
+	case !addr.IsValid():
+		return URLReasonReserved
+	case addr.IsLoopback():
+		return URLReasonLoopback
+	case addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast():
+		return URLReasonLinkLocal
+	case addr.IsPrivate():
+		return URLReasonPrivate
+	case cgnatPrefix.Contains(addr):
+		return URLReasonCGNAT
+	case addr.IsUnspecified() || addr.IsMulticast() || !addr.IsGlobalUnicast():
+		return URLReasonReserved
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return URLReasonReserved
+		}
+	}
+	return ""
+}
+
+// AddSafeURLValidation registers the "safe_url" tag, which accepts URLs that are safe for a server to
+// request, such as webhook URLs submitted by users, see CheckSafeURL. It rejects URLs pointing to the
+// server itself, private networks or cloud metadata services, including IP addresses written in decimal,
+// octal or hex and IPv4 addresses mapped to IPv6.
+//
+// The param replaces the accepted schemes of options, separated by spaces:
+//
+//	type Webhook struct {
+//	    URL      string `validate:"required,safe_url"`
+//	    Fallback string `validate:"omitempty,safe_url=https http"`
+//	}
+//
+// When options.Resolver is set, every address of the host must be public. The lookup uses the context passed
+// to ValidateCtx, limited to options.LookupTimeout. Hosts that cannot be resolved are rejected. With
+// options.Async, URLs are checked concurrently on the worker pool of the validator, see
+// validator.RegisterAsyncValidation.
+//
+// Invalid URLs are reported with the constraint "safe_url" and one of the URLReason* values as reason.
+// Messages can use these params:
+//   - {host}: the host of the URL, if it could be parsed
+//   - {ip}: the address the host is or resolves to, when the reason is about an address
+//   - {port}: the port of the URL
+//   - {scheme}: the scheme of the URL
+//   - {schemes}: the accepted schemes, e.g. "https, http"
+func AddSafeURLValidation(v *validator.Validator, options URLOptions) error {
+	// The schemes and the URL are read from the field before RunAsync, as fl is reused once the struct is traversed
+	if options.Async {
+		return v.BaseValidator.RegisterValidationCtx("safe_url", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+			schemes, rawURL := safeURLField(fl, options)
+			return validator.RunAsync(ctx, fl, func(ctx context.Context, param string, value interface{}) []validator.Violation {
+				return checkSafeURLField(ctx, rawURL, schemes, options)
+			})
+		})
+	}
+
+	return v.RegisterViolationValidation("safe_url", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		schemes, rawURL := safeURLField(fl, options)
+		return checkSafeURLField(ctx, rawURL, schemes, options)
+	})
+}
+
+// safeURLField returns the accepted schemes of a field and its URL
+func safeURLField(fl govalidator.FieldLevel, options URLOptions) ([]string, string) {
+	schemes := strings.Fields(fl.Param())
+	if len(schemes) == 0 {
+		schemes = options.Schemes
+	}
+	if len(schemes) == 0 {
+		schemes = DefaultURLSchemes
+	}
+
+	rawURL := ""
+	if fl.Field().Kind() == reflect.String {
+		rawURL = strings.TrimSpace(fl.Field().String())
+	}
+	return schemes, rawURL
+}
+
+// checkSafeURLField checks the URL of a field and returns its violation
+func checkSafeURLField(ctx context.Context, rawURL string, schemes []string, options URLOptions) []validator.Violation {
+	err := checkSafeURL(ctx, rawURL, schemes, options)
+	if err == nil {
+		return nil
+	}
+	urlErr := err.(*UnsafeURLError)
+
+	params := map[string]interface{}{
+		"host":    urlErr.Host,
+		"ip":      urlErr.IP,
+		"port":    "",
+		"scheme":  "",
+		"schemes": strings.Join(schemes, ", "),
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		params["port"] = u.Port()
+		params["scheme"] = strings.ToLower(u.Scheme)
+	}
+
+	return []validator.Violation{{
+		Reason:  urlErr.Reason,
+		Message: urlMessages[urlErr.Reason],
+		Params:  params,
+	}}
+}
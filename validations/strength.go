@@ -0,0 +1,1264 @@
+package validations
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// The strength estimator follows the approach of zxcvbn (https://github.com/dropbox/zxcvbn):
+// the password is split into the sequence of patterns (dictionary words, keyboard walks, repeats,
+// sequences, years and dates) that is the easiest to guess, and the number of guesses needed to
+// crack it is turned into a score from 0 to 4.
+
+// Pattern names of the matches found by the strength estimator
+const (
+	patternDictionary = "dictionary"
+	patternSpatial    = "spatial"
+	patternRepeat     = "repeat"
+	patternSequence   = "sequence"
+	patternRegex      = "regex"
+	patternDate       = "date"
+	patternBruteforce = "bruteforce"
+)
+
+// Dictionary names used by the strength estimator
+const (
+	dictionaryPasswords  = "passwords"
+	dictionaryEnglish    = "english"
+	dictionaryNames      = "names"
+	dictionaryUserInputs = "user_inputs"
+)
+
+const (
+	// maxStrengthInput is the number of runes of a password that are analyzed.
+	// Longer passwords are truncated, which keeps the estimation fast.
+	maxStrengthInput = 128
+
+	bruteforceCardinality             = 10
+	minGuessesBeforeGrowingSequence   = 10000
+	minSubmatchGuessesSingleChar      = 10
+	minSubmatchGuessesMultiChar       = 50
+	minYearSpace                      = 20
+	dateMinYear                       = 1000
+	dateMaxYear                       = 2050
+	maxSequenceDelta                  = 5
+	maxL33tSubstitutionCombinations   = 64
+	maxDictionaryWordLength           = 32
+	keyboardShiftedCharacters         = "~!@#$%^&*()_+QWERTYUIOP{}|ASDFGHJKL:\"ZXCVBNM<>?"
+	recentYearPattern                 = `19\d\d|200\d|201\d|202\d`
+	dateWithSeparatorPattern          = `^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`
+	strengthScoreTooGuessable         = 1e3
+	strengthScoreVeryGuessable        = 1e6
+	strengthScoreSomewhatGuessable    = 1e8
+	strengthScoreSafelyUnguessable    = 1e10
+	strengthScoreThresholdDeltaMargin = 5
+)
+
+// PasswordStrength is the result of EstimatePasswordStrength.
+type PasswordStrength struct {
+	Score       int      `json:"score"`                 // From 0 (too guessable) to 4 (very unguessable)
+	Guesses     float64  `json:"guesses"`               // Estimated number of guesses needed to find the password
+	Warning     string   `json:"warning,omitempty"`     // Explains what makes the password weak
+	Suggestions []string `json:"suggestions,omitempty"` // Hints to pick a stronger password
+}
+
+// strengthMatch is a pattern found in a password. Indices are rune positions, both inclusive.
+type strengthMatch struct {
+	pattern string
+	i, j    int
+	token   string
+	guesses float64
+
+	// dictionary
+	matchedWord    string
+	rank           int
+	dictionaryName string
+	reversed       bool
+	l33t           bool
+	substitutions  map[rune]rune
+
+	// spatial
+	graph        string
+	turns        int
+	shiftedCount int
+
+	// repeat
+	baseToken   string
+	baseGuesses float64
+	repeatCount int
+
+	// sequence
+	sequenceSpace int
+	ascending     bool
+
+	// regex
+	regexName string
+
+	// date
+	year      int
+	separator string
+}
+
+var (
+	rankedDictionaries     map[string]map[string]int
+	rankedDictionariesOnce sync.Once
+
+	recentYearRegex        = regexp.MustCompile(recentYearPattern)
+	dateWithSeparatorRegex = regexp.MustCompile(dateWithSeparatorPattern)
+)
+
+// loadRankedDictionaries reads the embedded word lists. The rank of a word is its line number.
+func loadRankedDictionaries() map[string]map[string]int {
+	rankedDictionariesOnce.Do(func() {
+		rankedDictionaries = map[string]map[string]int{
+			dictionaryPasswords: loadRankedDictionary("data/passwords.txt.gz"),
+			dictionaryEnglish:   loadRankedDictionary("data/english.txt"),
+			dictionaryNames:     loadRankedDictionary("data/names.txt"),
+		}
+	})
+	return rankedDictionaries
+}
+
+// loadRankedDictionary reads an embedded word list with one word per line, most common first.
+// The rank of a word is its position in the list, ignoring case and duplicates.
+func loadRankedDictionary(name string) map[string]int {
+	words, err := readWordList(dataFiles, name)
+	if err != nil {
+		panic("validations: cannot read embedded dictionary " + name + ": " + err.Error())
+	}
+
+	ranked := make(map[string]int, len(words))
+	rank := 1
+	for _, word := range words {
+		word = strings.ToLower(word)
+		if _, exists := ranked[word]; !exists {
+			ranked[word] = rank
+			rank++
+		}
+	}
+	return ranked
+}
+
+// EstimatePasswordStrength estimates how hard password is to guess, zxcvbn-style. It detects common
+// passwords, dictionary words and names (also reversed or with l33t substitutions), keyboard walks,
+// repeats, sequences, recent years and dates, and returns a score from 0 to 4 with feedback.
+//
+// userInputs are words that should be considered easy to guess for this particular user, such as the
+// username or email address.
+//
+// Score meaning:
+//   - 0: too guessable, risky password (guesses < 10^3)
+//   - 1: very guessable, protection from throttled online attacks (guesses < 10^6)
+//   - 2: somewhat guessable, protection from unthrottled online attacks (guesses < 10^8)
+//   - 3: safely unguessable, moderate protection from offline slow-hash scenarios (guesses < 10^10)
+//   - 4: very unguessable, strong protection from offline slow-hash scenarios (guesses >= 10^10)
+func EstimatePasswordStrength(password string, userInputs ...string) PasswordStrength {
+	runes := []rune(password)
+	if len(runes) > maxStrengthInput {
+		runes = runes[:maxStrengthInput]
+	}
+
+	dictionaries := loadRankedDictionaries()
+	if len(userInputs) > 0 {
+		withInputs := make(map[string]map[string]int, len(dictionaries)+1)
+		for name, dictionary := range dictionaries {
+			withInputs[name] = dictionary
+		}
+		inputs := make(map[string]int)
+		for rank, input := range userInputs {
+			input = strings.ToLower(strings.TrimSpace(input))
+			if _, exists := inputs[input]; input != "" && !exists {
+				inputs[input] = rank + 1
+			}
+		}
+		withInputs[dictionaryUserInputs] = inputs
+		dictionaries = withInputs
+	}
+
+	guesses, sequence := mostGuessableSequence(runes, findMatches(runes, dictionaries))
+	score := guessesToScore(guesses)
+	warning, suggestions := strengthFeedback(score, sequence)
+
+	return PasswordStrength{
+		Score:       score,
+		Guesses:     guesses,
+		Warning:     warning,
+		Suggestions: suggestions,
+	}
+}
+
+// guessesToScore converts a number of guesses into a score from 0 to 4
+func guessesToScore(guesses float64) int {
+	switch {
+	case guesses < strengthScoreTooGuessable+strengthScoreThresholdDeltaMargin:
+		return 0
+	case guesses < strengthScoreVeryGuessable+strengthScoreThresholdDeltaMargin:
+		return 1
+	case guesses < strengthScoreSomewhatGuessable+strengthScoreThresholdDeltaMargin:
+		return 2
+	case guesses < strengthScoreSafelyUnguessable+strengthScoreThresholdDeltaMargin:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// findMatches returns every pattern found in password
+func findMatches(password []rune, dictionaries map[string]map[string]int) []*strengthMatch {
+	var matches []*strengthMatch
+	matches = append(matches, dictionaryMatches(password, dictionaries)...)
+	matches = append(matches, reverseDictionaryMatches(password, dictionaries)...)
+	matches = append(matches, l33tMatches(password, dictionaries)...)
+	matches = append(matches, spatialMatches(password)...)
+	matches = append(matches, repeatMatches(password, dictionaries)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, regexMatches(password)...)
+	matches = append(matches, dateMatches(password)...)
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].i != matches[b].i {
+			return matches[a].i < matches[b].i
+		}
+		return matches[a].j < matches[b].j
+	})
+
+	return matches
+}
+
+// dictionaryMatches finds every substring of password that is a word of a dictionary
+func dictionaryMatches(password []rune, dictionaries map[string]map[string]int) []*strengthMatch {
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		// Lowercasing changed the number of runes, fall back to a rune by rune conversion
+		lower = make([]rune, len(password))
+		for k, r := range password {
+			lower[k] = unicode.ToLower(r)
+		}
+	}
+
+	var matches []*strengthMatch
+	for _, name := range sortedDictionaryNames(dictionaries) {
+		dictionary := dictionaries[name]
+		for i := 0; i < len(lower); i++ {
+			for j := i; j < len(lower) && j-i < maxDictionaryWordLength; j++ {
+				word := string(lower[i : j+1])
+				if rank, ok := dictionary[word]; ok {
+					matches = append(matches, &strengthMatch{
+						pattern:        patternDictionary,
+						i:              i,
+						j:              j,
+						token:          string(password[i : j+1]),
+						matchedWord:    word,
+						rank:           rank,
+						dictionaryName: name,
+					})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// sortedDictionaryNames returns the dictionary names in a stable order
+func sortedDictionaryNames(dictionaries map[string]map[string]int) []string {
+	names := make([]string, 0, len(dictionaries))
+	for name := range dictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reverseDictionaryMatches finds dictionary words written backwards
+func reverseDictionaryMatches(password []rune, dictionaries map[string]map[string]int) []*strengthMatch {
+	reversed := reverseRunes(password)
+
+	var matches []*strengthMatch
+	for _, match := range dictionaryMatches(reversed, dictionaries) {
+		match.token = string(reverseRunes([]rune(match.token)))
+		match.reversed = true
+		match.i, match.j = len(password)-1-match.j, len(password)-1-match.i
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+// reverseRunes returns a reversed copy of runes
+func reverseRunes(runes []rune) []rune {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return reversed
+}
+
+// l33tTable maps letters to the characters commonly substituted for them
+var l33tTable = map[rune][]rune{
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
+
+// l33tSubstitutions returns the possible ways of replacing the l33t characters of password with letters
+func l33tSubstitutions(password []rune) []map[rune]rune {
+	// Collect, for every l33t character in the password, the letters it could stand for
+	candidates := make(map[rune][]rune)
+	for letter, subs := range l33tTable {
+		for _, sub := range subs {
+			for _, r := range password {
+				if r == sub {
+					candidates[sub] = append(candidates[sub], letter)
+					break
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	subs := make([]rune, 0, len(candidates))
+	for sub := range candidates {
+		subs = append(subs, sub)
+		sort.Slice(candidates[sub], func(a, b int) bool { return candidates[sub][a] < candidates[sub][b] })
+	}
+	sort.Slice(subs, func(a, b int) bool { return subs[a] < subs[b] })
+
+	combinations := []map[rune]rune{{}}
+	for _, sub := range subs {
+		var next []map[rune]rune
+		for _, combination := range combinations {
+			for _, letter := range candidates[sub] {
+				extended := make(map[rune]rune, len(combination)+1)
+				for k, v := range combination {
+					extended[k] = v
+				}
+				extended[sub] = letter
+				next = append(next, extended)
+				if len(next) >= maxL33tSubstitutionCombinations {
+					break
+				}
+			}
+		}
+		combinations = next
+	}
+
+	return combinations
+}
+
+// l33tMatches finds dictionary words written with l33t substitutions, e.g. "p@ssw0rd"
+func l33tMatches(password []rune, dictionaries map[string]map[string]int) []*strengthMatch {
+	var matches []*strengthMatch
+	seen := make(map[string]bool)
+
+	for _, substitution := range l33tSubstitutions(password) {
+		subbed := make([]rune, len(password))
+		for k, r := range password {
+			if letter, ok := substitution[r]; ok {
+				subbed[k] = letter
+			} else {
+				subbed[k] = r
+			}
+		}
+
+		for _, match := range dictionaryMatches(subbed, dictionaries) {
+			token := password[match.i : match.j+1]
+
+			// Only keep matches where a substitution was actually used
+			used := make(map[rune]rune)
+			for _, r := range token {
+				if letter, ok := substitution[r]; ok {
+					used[r] = letter
+				}
+			}
+			if len(used) == 0 {
+				continue
+			}
+
+			key := strconv.Itoa(match.i) + ":" + strconv.Itoa(match.j) + ":" + match.dictionaryName + ":" + match.matchedWord
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			match.token = string(token)
+			match.l33t = true
+			match.substitutions = used
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+// keyboardGraph holds the adjacent keys of every character of a keyboard layout.
+// Adjacent keys are listed in a fixed direction order, nil when there is no key in that direction,
+// and each key lists its unshifted then shifted character.
+type keyboardGraph struct {
+	name              string
+	adjacency         map[rune][]string
+	startingPositions float64
+	averageDegree     float64
+}
+
+var (
+	keyboardGraphs     []*keyboardGraph
+	keyboardGraphsOnce sync.Once
+)
+
+const qwertyLayout = "" +
+	"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+\n" +
+	"    qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|\n" +
+	"     aA sS dD fF gG hH jJ kK lL ;: '\"\n" +
+	"      zZ xX cC vV bB nN mM ,< .> /?"
+
+const keypadLayout = "" +
+	"  / * -\n" +
+	"7 8 9 +\n" +
+	"4 5 6\n" +
+	"1 2 3\n" +
+	"  0 ."
+
+// loadKeyboardGraphs builds the keyboard adjacency graphs used to detect keyboard walks
+func loadKeyboardGraphs() []*keyboardGraph {
+	keyboardGraphsOnce.Do(func() {
+		keyboardGraphs = []*keyboardGraph{
+			buildKeyboardGraph("qwerty", qwertyLayout, true),
+			buildKeyboardGraph("keypad", keypadLayout, false),
+		}
+	})
+	return keyboardGraphs
+}
+
+// buildKeyboardGraph builds the adjacency graph of a layout. Slanted layouts (keyboards) have
+// six neighbors per key, aligned layouts (keypads) have eight.
+func buildKeyboardGraph(name, layout string, slanted bool) *keyboardGraph {
+	type position struct{ x, y int }
+
+	xUnit := 2
+	if slanted {
+		xUnit = 3
+	}
+
+	positions := make(map[position]string)
+	var tokens []string
+	var tokenPositions []position
+	for y, line := range strings.Split(layout, "\n") {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		column := 0
+		for _, token := range strings.Fields(line) {
+			index := strings.Index(line[column:], token) + column
+			column = index + len(token)
+			pos := position{x: (index - slant) / xUnit, y: y}
+			positions[pos] = token
+			tokens = append(tokens, token)
+			tokenPositions = append(tokenPositions, pos)
+		}
+	}
+
+	adjacentPositions := func(p position) []position {
+		if slanted {
+			return []position{{p.x - 1, p.y}, {p.x, p.y - 1}, {p.x + 1, p.y - 1}, {p.x + 1, p.y}, {p.x, p.y + 1}, {p.x - 1, p.y + 1}}
+		}
+		return []position{{p.x - 1, p.y}, {p.x - 1, p.y - 1}, {p.x, p.y - 1}, {p.x + 1, p.y - 1}, {p.x + 1, p.y}, {p.x + 1, p.y + 1}, {p.x, p.y + 1}, {p.x - 1, p.y + 1}}
+	}
+
+	graph := &keyboardGraph{name: name, adjacency: make(map[rune][]string)}
+	degrees := 0
+	for k, token := range tokens {
+		neighbors := make([]string, 0, 8)
+		for _, adjacent := range adjacentPositions(tokenPositions[k]) {
+			neighbor := positions[adjacent]
+			if neighbor != "" {
+				degrees++
+			}
+			neighbors = append(neighbors, neighbor)
+		}
+		for _, r := range token {
+			graph.adjacency[r] = neighbors
+		}
+	}
+
+	graph.startingPositions = float64(len(graph.adjacency))
+	graph.averageDegree = float64(degrees) / float64(len(tokens))
+	return graph
+}
+
+// spatialMatches finds keyboard walks such as "qwerty", "asdf" or "7896"
+func spatialMatches(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	for _, graph := range loadKeyboardGraphs() {
+		matches = append(matches, spatialMatchesForGraph(password, graph)...)
+	}
+	return matches
+}
+
+// spatialMatchesForGraph finds walks of at least three adjacent keys in one keyboard graph
+func spatialMatchesForGraph(password []rune, graph *keyboardGraph) []*strengthMatch {
+	var matches []*strengthMatch
+
+	i := 0
+	for i < len(password)-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shiftedCount := 0
+		if graph.name == "qwerty" && strings.ContainsRune(keyboardShiftedCharacters, password[i]) {
+			shiftedCount = 1
+		}
+
+		for {
+			found := false
+			if j < len(password) {
+				current := password[j]
+				for direction, adjacent := range graph.adjacency[password[j-1]] {
+					index := strings.IndexRune(adjacent, current)
+					if adjacent == "" || index < 0 {
+						continue
+					}
+					found = true
+					if index > 0 {
+						shiftedCount++
+					}
+					if lastDirection != direction {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+
+			if j-i > 2 {
+				matches = append(matches, &strengthMatch{
+					pattern:      patternSpatial,
+					i:            i,
+					j:            j - 1,
+					token:        string(password[i:j]),
+					graph:        graph.name,
+					turns:        turns,
+					shiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+
+	return matches
+}
+
+// repeatMatches finds repeated characters or blocks, such as "aaa" or "abcabcabc"
+func repeatMatches(password []rune, dictionaries map[string]map[string]int) []*strengthMatch {
+	var matches []*strengthMatch
+
+	i := 0
+	for i < len(password) {
+		bestLength, bestBase := 0, 0
+		for base := 1; i+2*base <= len(password); base++ {
+			count := 1
+			for i+(count+1)*base <= len(password) && string(password[i+count*base:i+(count+1)*base]) == string(password[i:i+base]) {
+				count++
+			}
+			if count >= 2 && count*base > bestLength {
+				bestLength, bestBase = count*base, base
+			}
+		}
+
+		if bestLength == 0 {
+			i++
+			continue
+		}
+
+		base := password[i : i+bestBase]
+		baseGuesses, _ := mostGuessableSequence(base, findMatches(base, dictionaries))
+		matches = append(matches, &strengthMatch{
+			pattern:     patternRepeat,
+			i:           i,
+			j:           i + bestLength - 1,
+			token:       string(password[i : i+bestLength]),
+			baseToken:   string(base),
+			baseGuesses: baseGuesses,
+			repeatCount: bestLength / bestBase,
+		})
+		i += bestLength
+	}
+
+	return matches
+}
+
+// sequenceMatches finds runs of characters with a constant code point delta, such as "abc", "9753" or "zyx"
+func sequenceMatches(password []rune) []*strengthMatch {
+	if len(password) == 1 {
+		return nil
+	}
+
+	var matches []*strengthMatch
+	update := func(i, j, delta int) {
+		absDelta := delta
+		if absDelta < 0 {
+			absDelta = -absDelta
+		}
+		if (j-i > 1 || absDelta == 1) && absDelta > 0 && absDelta <= maxSequenceDelta {
+			token := password[i : j+1]
+			space := 26
+			if allRunes(token, func(r rune) bool { return r >= '0' && r <= '9' }) {
+				space = 10
+			}
+			matches = append(matches, &strengthMatch{
+				pattern:       patternSequence,
+				i:             i,
+				j:             j,
+				token:         string(token),
+				sequenceSpace: space,
+				ascending:     delta > 0,
+			})
+		}
+	}
+
+	i := 0
+	lastDelta := 0
+	hasLastDelta := false
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k]) - int(password[k-1])
+		if !hasLastDelta {
+			lastDelta, hasLastDelta = delta, true
+		}
+		if delta == lastDelta {
+			continue
+		}
+		j := k - 1
+		update(i, j, lastDelta)
+		i = j
+		lastDelta = delta
+	}
+	update(i, len(password)-1, lastDelta)
+
+	return matches
+}
+
+// allRunes reports whether every rune of runes satisfies f
+func allRunes(runes []rune, f func(rune) bool) bool {
+	for _, r := range runes {
+		if !f(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// regexMatches finds recent years
+func regexMatches(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+
+	s := string(password)
+	for _, loc := range recentYearRegex.FindAllStringIndex(s, -1) {
+		i := len([]rune(s[:loc[0]]))
+		token := s[loc[0]:loc[1]]
+		matches = append(matches, &strengthMatch{
+			pattern:   patternRegex,
+			i:         i,
+			j:         i + len([]rune(token)) - 1,
+			token:     token,
+			regexName: "recent_year",
+		})
+	}
+
+	return matches
+}
+
+// dateSplits lists, for each length of a date without separators, the positions where the
+// day, month and year may be split
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+// dateMatches finds dates with or without separators, such as "1/1/91", "13.05.1987" or "19870513"
+func dateMatches(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	reference := referenceYear()
+
+	// Dates without separators
+	for i := 0; i+3 < len(password); i++ {
+		for j := i + 3; j <= i+7 && j < len(password); j++ {
+			token := string(password[i : j+1])
+			if !allRunes(password[i:j+1], func(r rune) bool { return r >= '0' && r <= '9' }) {
+				break
+			}
+
+			var best *dmy
+			bestDistance := 0
+			for _, split := range dateSplits[len(token)] {
+				ints := [3]int{atoi(token[:split[0]]), atoi(token[split[0]:split[1]]), atoi(token[split[1]:])}
+				date, ok := mapIntsToDMY(ints)
+				if !ok {
+					continue
+				}
+				distance := absInt(date.year - reference)
+				if best == nil || distance < bestDistance {
+					best, bestDistance = &date, distance
+				}
+			}
+			if best != nil {
+				matches = append(matches, &strengthMatch{pattern: patternDate, i: i, j: j, token: token, year: best.year})
+			}
+		}
+	}
+
+	// Dates with separators
+	for i := 0; i+5 < len(password); i++ {
+		for j := i + 5; j <= i+9 && j < len(password); j++ {
+			token := string(password[i : j+1])
+			groups := dateWithSeparatorRegex.FindStringSubmatch(token)
+			if groups == nil || groups[2] != groups[4] {
+				continue
+			}
+			date, ok := mapIntsToDMY([3]int{atoi(groups[1]), atoi(groups[3]), atoi(groups[5])})
+			if !ok {
+				continue
+			}
+			matches = append(matches, &strengthMatch{pattern: patternDate, i: i, j: j, token: token, year: date.year, separator: groups[2]})
+		}
+	}
+
+	// Remove dates that are strictly inside other dates
+	var filtered []*strengthMatch
+	for _, match := range matches {
+		contained := false
+		for _, other := range matches {
+			if match != other && other.i <= match.i && other.j >= match.j {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			filtered = append(filtered, match)
+		}
+	}
+
+	return filtered
+}
+
+// dmy is a day, month and year found in a date
+type dmy struct {
+	day, month, year int
+}
+
+// mapIntsToDMY interprets three integers as a day, month and year in any plausible order
+func mapIntsToDMY(ints [3]int) (dmy, bool) {
+	if ints[1] > 31 || ints[1] <= 0 {
+		return dmy{}, false
+	}
+
+	over12, over31, under1 := 0, 0, 0
+	for _, n := range ints {
+		if (n > 99 && n < dateMinYear) || n > dateMaxYear {
+			return dmy{}, false
+		}
+		if n > 31 {
+			over31++
+		}
+		if n > 12 {
+			over12++
+		}
+		if n <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return dmy{}, false
+	}
+
+	splits := []struct {
+		year int
+		rest [2]int
+	}{
+		{ints[2], [2]int{ints[0], ints[1]}},
+		{ints[0], [2]int{ints[1], ints[2]}},
+	}
+
+	for _, split := range splits {
+		if split.year >= dateMinYear && split.year <= dateMaxYear {
+			day, month, ok := mapIntsToDM(split.rest)
+			if !ok {
+				// A four-digit year was found but the rest is not a valid day and month
+				return dmy{}, false
+			}
+			return dmy{day: day, month: month, year: split.year}, true
+		}
+	}
+
+	for _, split := range splits {
+		if day, month, ok := mapIntsToDM(split.rest); ok {
+			return dmy{day: day, month: month, year: twoToFourDigitYear(split.year)}, true
+		}
+	}
+
+	return dmy{}, false
+}
+
+// mapIntsToDM interprets two integers as a day and month in any order
+func mapIntsToDM(ints [2]int) (int, int, bool) {
+	for _, pair := range [][2]int{ints, {ints[1], ints[0]}} {
+		day, month := pair[0], pair[1]
+		if day >= 1 && day <= 31 && month >= 1 && month <= 12 {
+			return day, month, true
+		}
+	}
+	return 0, 0, false
+}
+
+// twoToFourDigitYear expands two-digit years, e.g. 87 -> 1987 and 15 -> 2015
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return 1900 + year
+	default:
+		return 2000 + year
+	}
+}
+
+// referenceYear is the year used to estimate how guessable years and dates are
+func referenceYear() int {
+	return time.Now().Year()
+}
+
+// atoi converts a string of digits to an int, returning 0 on failure
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// absInt returns the absolute value of n
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// mostGuessableSequence finds the sequence of non-overlapping matches covering password that needs
+// the fewest guesses, filling gaps with bruteforce matches. It returns the number of guesses and
+// the sequence of matches.
+func mostGuessableSequence(password []rune, matches []*strengthMatch) (float64, []*strengthMatch) {
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matchesByEnd := make([][]*strengthMatch, n)
+	for _, match := range matches {
+		matchesByEnd[match.j] = append(matchesByEnd[match.j], match)
+	}
+
+	// For each end position k and sequence length l: the last match, the product of the guesses
+	// of the sequence, and the overall metric to minimize
+	type state struct {
+		match *strengthMatch
+		pi    float64
+		g     float64
+	}
+	optimal := make([]map[int]state, n)
+	for k := range optimal {
+		optimal[k] = make(map[int]state)
+	}
+
+	update := func(m *strengthMatch, l int) {
+		k := m.j
+		pi := estimateGuesses(m, n)
+		if l > 1 {
+			pi *= optimal[m.i-1][l-1].pi
+		}
+		g := factorial(l)*pi + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+
+		// Skip if a sequence of the same or a shorter length ending at k is at least as good
+		for otherL, other := range optimal[k] {
+			if otherL > l {
+				continue
+			}
+			if other.g <= g {
+				return
+			}
+		}
+		optimal[k][l] = state{match: m, pi: pi, g: g}
+	}
+
+	bruteforce := func(i, j int) *strengthMatch {
+		return &strengthMatch{pattern: patternBruteforce, i: i, j: j, token: string(password[i : j+1])}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range matchesByEnd[k] {
+			if m.i > 0 {
+				for _, l := range sortedKeys(optimal[m.i-1]) {
+					update(m, l+1)
+				}
+			} else {
+				update(m, 1)
+			}
+		}
+
+		update(bruteforce(0, k), 1)
+		for i := 1; i <= k; i++ {
+			m := bruteforce(i, k)
+			for _, l := range sortedKeys(optimal[i-1]) {
+				// Never put two bruteforce matches next to each other, a longer one is always better
+				if optimal[i-1][l].match.pattern == patternBruteforce {
+					continue
+				}
+				update(m, l+1)
+			}
+		}
+	}
+
+	// Unwind the optimal sequence
+	bestL, bestG := 0, math.Inf(1)
+	for l, s := range optimal[n-1] {
+		if s.g < bestG || (s.g == bestG && l < bestL) {
+			bestL, bestG = l, s.g
+		}
+	}
+
+	sequence := make([]*strengthMatch, 0, bestL)
+	k, l := n-1, bestL
+	for k >= 0 && l > 0 {
+		m := optimal[k][l].match
+		sequence = append([]*strengthMatch{m}, sequence...)
+		k = m.i - 1
+		l--
+	}
+
+	return optimal[n-1][bestL].g, sequence
+}
+
+// sortedKeys returns the keys of a map in increasing order
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// factorial returns n! as a float64
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
+
+// nCk returns the binomial coefficient of n and k
+func nCk(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	result := 1.0
+	for d := 1; d <= k; d++ {
+		result *= float64(n)
+		result /= float64(d)
+		n--
+	}
+	return result
+}
+
+// estimateGuesses returns the number of guesses needed to find the token of a match,
+// caching the result in the match
+func estimateGuesses(m *strengthMatch, passwordLength int) float64 {
+	if m.guesses > 0 {
+		return m.guesses
+	}
+
+	tokenLength := len([]rune(m.token))
+	minGuesses := 1.0
+	if tokenLength < passwordLength {
+		if tokenLength == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		} else {
+			minGuesses = minSubmatchGuessesMultiChar
+		}
+	}
+
+	var guesses float64
+	switch m.pattern {
+	case patternBruteforce:
+		guesses = math.Pow(bruteforceCardinality, float64(tokenLength))
+		minBruteforce := float64(minSubmatchGuessesMultiChar + 1)
+		if tokenLength == 1 {
+			minBruteforce = minSubmatchGuessesSingleChar + 1
+		}
+		guesses = math.Max(guesses, minBruteforce)
+	case patternDictionary:
+		guesses = float64(m.rank) * uppercaseVariations(m.token) * l33tVariations(m)
+		if m.reversed {
+			guesses *= 2
+		}
+	case patternSpatial:
+		guesses = spatialGuesses(m)
+	case patternRepeat:
+		guesses = m.baseGuesses * float64(m.repeatCount)
+	case patternSequence:
+		base := float64(m.sequenceSpace)
+		switch []rune(m.token)[0] {
+		case 'a', 'A', 'z', 'Z', '0', '1', '9':
+			base = 4
+		}
+		if !m.ascending {
+			base *= 2
+		}
+		guesses = base * float64(tokenLength)
+	case patternRegex:
+		guesses = math.Max(float64(absInt(atoi(m.token)-referenceYear())), minYearSpace)
+	case patternDate:
+		guesses = math.Max(float64(absInt(m.year-referenceYear())), minYearSpace) * 365
+		if m.separator != "" {
+			guesses *= 4
+		}
+	}
+
+	m.guesses = math.Max(guesses, minGuesses)
+	return m.guesses
+}
+
+// uppercaseVariations estimates how many capitalization variants of a word an attacker has to try
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 1
+	}
+
+	// Common capitalizations: first letter, last letter or all letters
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(runes[0]) || unicode.IsUpper(runes[len(runes)-1]))) {
+		return 2
+	}
+
+	variations := 0.0
+	for i := 1; i <= upper && i <= lower; i++ {
+		variations += nCk(upper+lower, i)
+	}
+	return variations
+}
+
+// l33tVariations estimates how many l33t variants of a word an attacker has to try
+func l33tVariations(m *strengthMatch) float64 {
+	if !m.l33t {
+		return 1
+	}
+
+	variations := 1.0
+	lower := []rune(strings.ToLower(m.token))
+	for sub, letter := range m.substitutions {
+		subbed, unsubbed := 0, 0
+		for _, r := range lower {
+			if r == sub {
+				subbed++
+			}
+			if r == letter {
+				unsubbed++
+			}
+		}
+
+		if subbed == 0 || unsubbed == 0 {
+			variations *= 2
+			continue
+		}
+
+		possibilities := 0.0
+		for i := 1; i <= subbed && i <= unsubbed; i++ {
+			possibilities += nCk(subbed+unsubbed, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+// spatialGuesses estimates the number of guesses of a keyboard walk from its length,
+// number of turns and number of shifted characters
+func spatialGuesses(m *strengthMatch) float64 {
+	var graph *keyboardGraph
+	for _, g := range loadKeyboardGraphs() {
+		if g.name == m.graph {
+			graph = g
+		}
+	}
+
+	length := len([]rune(m.token))
+	guesses := 0.0
+	for i := 2; i <= length; i++ {
+		possibleTurns := m.turns
+		if i-1 < possibleTurns {
+			possibleTurns = i - 1
+		}
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += nCk(i-1, j-1) * graph.startingPositions * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	if m.shiftedCount > 0 {
+		shifted, unshifted := m.shiftedCount, length-m.shiftedCount
+		if shifted == 0 || unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for i := 1; i <= shifted && i <= unshifted; i++ {
+				variations += nCk(shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+
+	return guesses
+}
+
+// Suggestions returned by the strength estimator
+const (
+	suggestionDefaultWords      = "Use a few words, avoid common phrases."
+	suggestionNoSymbolsNeeded   = "No need for symbols, digits, or uppercase letters."
+	suggestionAddWord           = "Add another word or two. Uncommon words are better."
+	suggestionLongerKeyboard    = "Use a longer keyboard pattern with more turns."
+	suggestionAvoidRepeats      = "Avoid repeated words and characters."
+	suggestionAvoidSequences    = "Avoid sequences."
+	suggestionAvoidRecentYears  = "Avoid recent years."
+	suggestionAvoidYears        = "Avoid years that are associated with you."
+	suggestionAvoidDates        = "Avoid dates and years that are associated with you."
+	suggestionCapitalization    = "Capitalization doesn't help very much."
+	suggestionAllUppercase      = "All-uppercase is almost as easy to guess as all-lowercase."
+	suggestionReversedWords     = "Reversed words aren't much harder to guess."
+	suggestionL33tSubstitutions = "Predictable substitutions like '@' instead of 'a' don't help very much."
+)
+
+// strengthFeedback explains what makes a password weak, based on its longest match
+func strengthFeedback(score int, sequence []*strengthMatch) (string, []string) {
+	if len(sequence) == 0 {
+		return "", []string{suggestionDefaultWords, suggestionNoSymbolsNeeded}
+	}
+	if score > 2 {
+		return "", nil
+	}
+
+	longest := sequence[0]
+	for _, m := range sequence[1:] {
+		if len([]rune(m.token)) > len([]rune(longest.token)) {
+			longest = m
+		}
+	}
+
+	warning, suggestions := matchFeedback(longest, len(sequence) == 1)
+	return warning, append([]string{suggestionAddWord}, suggestions...)
+}
+
+// matchFeedback returns the warning and suggestions for a single match
+func matchFeedback(m *strengthMatch, isSoleMatch bool) (string, []string) {
+	switch m.pattern {
+	case patternDictionary:
+		return dictionaryFeedback(m, isSoleMatch)
+	case patternSpatial:
+		if m.turns == 1 {
+			return "Straight rows of keys are easy to guess", []string{suggestionLongerKeyboard}
+		}
+		return "Short keyboard patterns are easy to guess", []string{suggestionLongerKeyboard}
+	case patternRepeat:
+		if len([]rune(m.baseToken)) == 1 {
+			return `Repeats like "aaa" are easy to guess`, []string{suggestionAvoidRepeats}
+		}
+		return `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`, []string{suggestionAvoidRepeats}
+	case patternSequence:
+		return "Sequences like abc or 6543 are easy to guess", []string{suggestionAvoidSequences}
+	case patternRegex:
+		return "Recent years are easy to guess", []string{suggestionAvoidRecentYears, suggestionAvoidYears}
+	case patternDate:
+		return "Dates are often easy to guess", []string{suggestionAvoidDates}
+	default:
+		return "", nil
+	}
+}
+
+// dictionaryFeedback returns the warning and suggestions for a dictionary match
+func dictionaryFeedback(m *strengthMatch, isSoleMatch bool) (string, []string) {
+	var warning string
+	switch m.dictionaryName {
+	case dictionaryPasswords:
+		switch {
+		case isSoleMatch && !m.l33t && !m.reversed && m.rank <= 10:
+			warning = "This is a top-10 common password"
+		case isSoleMatch && !m.l33t && !m.reversed && m.rank <= 100:
+			warning = "This is a top-100 common password"
+		case isSoleMatch && !m.l33t && !m.reversed:
+			warning = "This is a very common password"
+		default:
+			warning = "This is similar to a commonly used password"
+		}
+	case dictionaryEnglish:
+		if isSoleMatch {
+			warning = "A word by itself is easy to guess"
+		}
+	case dictionaryNames:
+		if isSoleMatch {
+			warning = "Names and surnames by themselves are easy to guess"
+		} else {
+			warning = "Common names and surnames are easy to guess"
+		}
+	case dictionaryUserInputs:
+		warning = "Passwords based on your personal information are easy to guess"
+	}
+
+	var suggestions []string
+	runes := []rune(m.token)
+	if unicode.IsUpper(runes[0]) && strings.ToLower(string(runes[1:])) == string(runes[1:]) {
+		suggestions = append(suggestions, suggestionCapitalization)
+	} else if strings.ToUpper(m.token) == m.token && strings.ToLower(m.token) != m.token {
+		suggestions = append(suggestions, suggestionAllUppercase)
+	}
+	if m.reversed && len(runes) >= 4 {
+		suggestions = append(suggestions, suggestionReversedWords)
+	}
+	if m.l33t {
+		suggestions = append(suggestions, suggestionL33tSubstitutions)
+	}
+
+	return warning, suggestions
+}
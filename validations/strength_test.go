@@ -0,0 +1,123 @@
+package validations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatePasswordStrength(t *testing.T) {
+	t.Run("Weak passwords", func(t *testing.T) {
+		cases := []struct {
+			password string
+			warning  string
+		}{
+			{"password", "This is a top-10 common password"},
+			{"p@ssw0rd", "This is similar to a commonly used password"},
+			{"drowssap", "This is similar to a commonly used password"},
+			{"aaaaaaaa", `Repeats like "aaa" are easy to guess`},
+			{"abcdef", "Sequences like abc or 6543 are easy to guess"},
+			{"2019", "Recent years are easy to guess"},
+			{"zxcvbnm", "This is a top-100 common password"},
+		}
+
+		for _, c := range cases {
+			strength := EstimatePasswordStrength(c.password)
+			assert.Equal(t, 0, strength.Score, c.password)
+			assert.Equal(t, c.warning, strength.Warning, c.password)
+			assert.NotEmpty(t, strength.Suggestions, c.password)
+		}
+	})
+
+	t.Run("Character classes do not make a password strong", func(t *testing.T) {
+		strength := EstimatePasswordStrength("Password1!")
+		assert.LessOrEqual(t, strength.Score, 1)
+		assert.Contains(t, strength.Suggestions, suggestionCapitalization)
+	})
+
+	t.Run("Long passphrases are strong", func(t *testing.T) {
+		strength := EstimatePasswordStrength("correct horse battery staple")
+		assert.Equal(t, 4, strength.Score)
+		assert.Empty(t, strength.Warning)
+		assert.Empty(t, strength.Suggestions)
+	})
+
+	t.Run("Random passwords are strong", func(t *testing.T) {
+		strength := EstimatePasswordStrength("xkcd-Vf9#qLm2!Rt")
+		assert.Equal(t, 4, strength.Score)
+	})
+
+	t.Run("Dates", func(t *testing.T) {
+		for _, password := range []string{"13/05/1987", "19870513"} {
+			strength := EstimatePasswordStrength(password)
+			assert.LessOrEqual(t, strength.Score, 1, password)
+			assert.Equal(t, "Dates are often easy to guess", strength.Warning, password)
+		}
+	})
+
+	t.Run("Keyboard walks", func(t *testing.T) {
+		walk := EstimatePasswordStrength("7896541230")
+		random := EstimatePasswordStrength("7150392648")
+		assert.Less(t, walk.Guesses, random.Guesses)
+		assert.Equal(t, "Short keyboard patterns are easy to guess", walk.Warning)
+	})
+
+	t.Run("User inputs", func(t *testing.T) {
+		without := EstimatePasswordStrength("kowalczykxq")
+		with := EstimatePasswordStrength("kowalczykxq", "Kowalczyk", "")
+		assert.Less(t, with.Guesses, without.Guesses)
+		assert.Equal(t, "Passwords based on your personal information are easy to guess", with.Warning)
+	})
+
+	t.Run("Empty password", func(t *testing.T) {
+		strength := EstimatePasswordStrength("")
+		assert.Equal(t, 0, strength.Score)
+		assert.Equal(t, float64(1), strength.Guesses)
+	})
+
+	t.Run("Long inputs are truncated", func(t *testing.T) {
+		strength := EstimatePasswordStrength(strings.Repeat("correct horse battery staple ", 100))
+		assert.Equal(t, 4, strength.Score)
+	})
+}
+
+func TestPasswordMinScore(t *testing.T) {
+	type Signup struct {
+		Password string `json:"password" validate:"password"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddPasswordValidation(v, PasswordOptions{MinLength: 8, MinScore: 3}))
+
+	t.Run("Passphrase passes", func(t *testing.T) {
+		assert.NoError(t, v.Validate(Signup{Password: "correct horse battery staple"}))
+	})
+
+	t.Run("Guessable password fails", func(t *testing.T) {
+		errors := v.Validate(Signup{Password: "Password1!"}).(validator.ValidationErrors)
+		assert.Len(t, errors, 1)
+		assert.Equal(t, PasswordReasonStrength, errors[0].Reason)
+		assert.Equal(t, "Password is too easy to guess", errors[0].Message)
+		assert.Equal(t, 3, errors[0].Params["min_score"])
+		assert.Equal(t, "This is similar to a commonly used password", errors[0].Params["warning"])
+	})
+
+	t.Run("Feedback params in messages", func(t *testing.T) {
+		v := v.UseMessages(validator.NewValidationMessages())
+		v.SetDefaultTagMessage("password.strength", "Score {score}/{min_score}: {warning}. {suggestions}")
+
+		errors := v.Validate(Signup{Password: "p@ssw0rd"}).(validator.ValidationErrors)
+		assert.Equal(t, "Score 0/3: This is similar to a commonly used password. "+
+			"Add another word or two. Uncommon words are better. "+
+			"Predictable substitutions like '@' instead of 'a' don't help very much.", errors[0].Message)
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddPasswordValidation(v, PasswordOptions{MinLength: 8}))
+		assert.NoError(t, v.Validate(Signup{Password: "password"}))
+	})
+}
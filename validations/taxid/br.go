@@ -0,0 +1,100 @@
+package taxid
+
+import "github.com/juancwu/go-valkit/v2/validator"
+
+// cpf describes the Brazilian Cadastro de Pessoas Físicas, the tax number of people
+var cpf = identifier{
+	tag:    "br_cpf",
+	name:   "CPF",
+	check:  checkCPF,
+	redact: validator.MaskKeepLast(2),
+}
+
+// cnpj describes the Brazilian Cadastro Nacional da Pessoa Jurídica, the tax number of companies
+var cnpj = identifier{
+	tag:   "br_cnpj",
+	name:  "CNPJ",
+	check: checkCNPJ,
+}
+
+// AddBrazilValidations registers the Brazilian identifiers:
+//   - "br_cpf": CPF, 11 digits with 2 check digits, e.g. "390.533.447-05"
+//   - "br_cnpj": CNPJ, 14 characters with 2 check digits, e.g. "11.222.333/0001-81". The first
+//     12 characters can be letters, as in the alphanumeric CNPJs issued from July 2026.
+//
+// CPFs are registered as sensitive and masked except for their check digits.
+func AddBrazilValidations(v *validator.Validator) error {
+	return register(v, cpf, cnpj)
+}
+
+// ParseCPF validates a Brazilian CPF and returns its 11 digits.
+// Invalid numbers return an *Error with the reason.
+func ParseCPF(number string) (string, error) {
+	return cpf.parse(number)
+}
+
+// ParseCNPJ validates a Brazilian CNPJ and returns its 14 characters.
+// Invalid numbers return an *Error with the reason.
+func ParseCNPJ(number string) (string, error) {
+	return cnpj.parse(number)
+}
+
+// checkCPF returns the digits of a CPF, or the reason it is not valid
+func checkCPF(number string) (string, string) {
+	number = compact(number)
+	if !isDigits(number) {
+		return "", ReasonFormat
+	}
+	if len(number) != 11 {
+		return "", ReasonLength
+	}
+	// Numbers with a single repeated digit pass the checksum but are not assigned
+	if allSame(number) {
+		return "", ReasonRepeated
+	}
+
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += digit(number, i) * (n + 1 - i)
+		}
+		if sum*10%11%10 != digit(number, n) {
+			return "", ReasonChecksum
+		}
+	}
+	return number, ""
+}
+
+// checkCNPJ returns the characters of a CNPJ, or the reason it is not valid
+func checkCNPJ(number string) (string, string) {
+	number = compact(number)
+	for i, r := range number {
+		isLetter := r >= 'A' && r <= 'Z' && i < 12
+		if !isLetter && (r < '0' || r > '9') {
+			return "", ReasonFormat
+		}
+	}
+	if len(number) != 14 {
+		return "", ReasonLength
+	}
+	if allSame(number) {
+		return "", ReasonRepeated
+	}
+
+	// Characters count as their ASCII code minus 48, so digits keep their value
+	weights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	for n := 12; n <= 13; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(number[i]-'0') * weights[13-n+i]
+		}
+		check := 11 - sum%11
+		if check >= 10 {
+			check = 0
+		}
+		if check != digit(number, n) {
+			return "", ReasonChecksum
+		}
+	}
+	return number, ""
+}
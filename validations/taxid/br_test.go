@@ -0,0 +1,55 @@
+package taxid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCPF(t *testing.T) {
+	for _, number := range []string{"390.533.447-05", "111.444.777-35", "52998224725"} {
+		_, err := ParseCPF(number)
+		assert.NoError(t, err, number)
+	}
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"390.533.447-0X", ReasonFormat},
+		{"390.533.447-0", ReasonLength},
+		{"111.111.111-11", ReasonRepeated},
+		{"390.533.447-06", ReasonChecksum},
+		{"390.533.447-15", ReasonChecksum},
+	}
+	for _, tt := range tests {
+		_, err := ParseCPF(tt.number)
+		assertReason(t, tt.reason, err, tt.number)
+	}
+}
+
+func TestParseCNPJ(t *testing.T) {
+	cnpj, err := ParseCNPJ("11.222.333/0001-81")
+	assert.NoError(t, err)
+	assert.Equal(t, "11222333000181", cnpj)
+
+	// Alphanumeric CNPJ, issued from July 2026
+	cnpj, err = ParseCNPJ("12.abc.345/01de-35")
+	assert.NoError(t, err)
+	assert.Equal(t, "12ABC34501DE35", cnpj)
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"11.222.333/0001-8A", ReasonFormat},
+		{"11.222.333/0001-8", ReasonLength},
+		{"00.000.000/0000-00", ReasonRepeated},
+		{"11.222.333/0001-82", ReasonChecksum},
+		{"12.ABC.345/01DE-36", ReasonChecksum},
+	}
+	for _, tt := range tests {
+		_, err := ParseCNPJ(tt.number)
+		assertReason(t, tt.reason, err, tt.number)
+	}
+}
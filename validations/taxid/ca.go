@@ -0,0 +1,44 @@
+package taxid
+
+import "github.com/juancwu/go-valkit/v2/validator"
+
+// sin describes the Canadian Social Insurance Number
+var sin = identifier{
+	tag:    "ca_sin",
+	name:   "SIN",
+	check:  checkSIN,
+	redact: validator.MaskKeepLast(3),
+}
+
+// AddCanadaValidations registers the Canadian identifiers:
+//   - "ca_sin": Social Insurance Number, 9 digits with a Luhn check digit, e.g. "130 692 544"
+//
+// SINs are registered as sensitive and masked except for their last 3 digits.
+func AddCanadaValidations(v *validator.Validator) error {
+	return register(v, sin)
+}
+
+// ParseSIN validates a Canadian Social Insurance Number and returns its 9 digits.
+// Invalid numbers return an *Error with the reason.
+func ParseSIN(number string) (string, error) {
+	return sin.parse(number)
+}
+
+// checkSIN returns the digits of a SIN, or the reason it is not valid
+func checkSIN(number string) (string, string) {
+	number = compact(number)
+	if !isDigits(number) {
+		return "", ReasonFormat
+	}
+	if len(number) != 9 {
+		return "", ReasonLength
+	}
+	// 0 is not assigned and 8 is used for business numbers; 9 is for temporary residents
+	if number[0] == '0' || number[0] == '8' {
+		return "", ReasonPrefix
+	}
+	if !luhn(number) {
+		return "", ReasonChecksum
+	}
+	return number, ""
+}
@@ -0,0 +1,41 @@
+package taxid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertReason asserts that err is an *Error with the given reason
+func assertReason(t *testing.T, reason string, err error, value string) {
+	t.Helper()
+	if e, ok := err.(*Error); assert.True(t, ok, value) {
+		assert.Equal(t, reason, e.Reason, value)
+	}
+}
+
+func TestParseSIN(t *testing.T) {
+	for _, number := range []string{"130 692 544", "130-692-544", "918640012"} {
+		_, err := ParseSIN(number)
+		assert.NoError(t, err, number)
+	}
+
+	sin, err := ParseSIN("130 692 544")
+	assert.NoError(t, err)
+	assert.Equal(t, "130692544", sin)
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"130 692 54A", ReasonFormat},
+		{"13069254", ReasonLength},
+		{"046 454 286", ReasonPrefix},
+		{"830692544", ReasonPrefix},
+		{"130 692 545", ReasonChecksum},
+	}
+	for _, tt := range tests {
+		_, err := ParseSIN(tt.number)
+		assertReason(t, tt.reason, err, tt.number)
+	}
+}
@@ -0,0 +1,524 @@
+package taxid
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// VATNumber is an EU VAT identification number
+type VATNumber struct {
+	Country string // VAT country prefix, e.g. "DE"; Greece uses "EL"
+	Number  string // Number without the country prefix, e.g. "136695976"
+}
+
+// String returns the number with its country prefix, e.g. "DE136695976"
+func (n VATNumber) String() string {
+	return n.Country + n.Number
+}
+
+// vatCheck validates the number of a VAT country, without the prefix. It returns the reason it is not valid.
+type vatCheck func(number string) string
+
+// vatChecks are the check digit algorithms of each EU member state, by VAT country prefix
+var vatChecks = map[string]vatCheck{
+	"AT": checkVATAustria,
+	"BE": checkVATBelgium,
+	"BG": checkVATBulgaria,
+	"CY": checkVATCyprus,
+	"CZ": checkVATCzechia,
+	"DE": vatDigits(9, mod11x10),
+	"DK": vatDigits(8, func(n string) bool { return weightedSum(n, 2, 7, 6, 5, 4, 3, 2, 1)%11 == 0 }),
+	"EE": vatDigits(9, func(n string) bool { return weightedSum(n, 3, 7, 1, 3, 7, 1, 3, 7, 1)%10 == 0 }),
+	"EL": vatDigits(9, checkVATGreece),
+	"ES": checkVATSpain,
+	"FI": vatDigits(8, checkVATFinland),
+	"FR": checkVATFrance,
+	"HR": vatDigits(11, mod11x10),
+	"HU": vatDigits(8, func(n string) bool { return (10-weightedSum(n, 9, 7, 3, 1, 9, 7, 3)%10)%10 == digit(n, 7) }),
+	"IE": checkVATIreland,
+	"IT": vatDigits(11, luhn),
+	"LT": checkVATLithuania,
+	"LU": vatDigits(8, func(n string) bool { v, _ := strconv.Atoi(n[:6]); c, _ := strconv.Atoi(n[6:]); return v%89 == c }),
+	"LV": checkVATLatvia,
+	"MT": vatDigits(8, func(n string) bool { return weightedSum(n, 3, 4, 6, 7, 8, 9, 10, 1)%37 == 0 }),
+	"NL": checkVATNetherlands,
+	"PL": vatDigits(10, func(n string) bool { return weightedSum(n, 6, 5, 7, 2, 3, 4, 5, 6, 7)%11 == digit(n, 9) }),
+	"PT": vatDigits(9, checkVATPortugal),
+	"RO": checkVATRomania,
+	"SE": checkVATSweden,
+	"SI": vatDigits(8, checkVATSlovenia),
+	"SK": vatDigits(10, checkVATSlovakia),
+}
+
+// vatDigits returns a vatCheck for numbers of length digits validated with check
+func vatDigits(length int, check func(number string) bool) vatCheck {
+	return func(number string) string {
+		if !isDigits(number) {
+			return ReasonFormat
+		}
+		if len(number) != length {
+			return ReasonLength
+		}
+		if !check(number) {
+			return ReasonChecksum
+		}
+		return ""
+	}
+}
+
+// AddEUValidations registers the EU identifiers:
+//   - "eu_vat": VAT identification number with its country prefix, e.g. "DE136695976", validated with the
+//     check digit algorithm of each member state. The param optionally lists the accepted countries,
+//     separated by spaces, e.g. "eu_vat=DE FR"; Greece uses the "EL" prefix.
+//
+// Messages can use the {name}, {country} and {countries} params.
+func AddEUValidations(v *validator.Validator) error {
+	return v.RegisterViolationValidation("eu_vat", func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+		countries := strings.Fields(strings.ToUpper(fl.Param()))
+		for _, country := range countries {
+			if _, ok := vatChecks[country]; !ok {
+				panic(fmt.Sprintf("taxid: eu_vat on field '%s' references unknown country '%s'", fl.FieldName(), country))
+			}
+		}
+
+		vat, reason := checkVAT(fieldString(fl), countries)
+		if reason == "" {
+			return nil
+		}
+
+		msg := message(reason)
+		if reason == ReasonCountry {
+			msg = "{field} has an unknown VAT country code"
+			if len(countries) > 0 {
+				msg = "{field} must be a VAT number from {countries}"
+			}
+		}
+
+		return []validator.Violation{{
+			Reason:  reason,
+			Message: msg,
+			Params: map[string]interface{}{
+				"name":      "VAT number",
+				"country":   vat.Country,
+				"countries": strings.Join(countries, " "),
+			},
+		}}
+	})
+}
+
+// ParseVAT validates an EU VAT identification number with its country prefix. When countries are given,
+// numbers of other countries are rejected. Invalid numbers return an *Error with the reason.
+func ParseVAT(number string, countries ...string) (VATNumber, error) {
+	countries = strings.Fields(strings.ToUpper(strings.Join(countries, " ")))
+
+	vat, reason := checkVAT(number, countries)
+	if reason != "" {
+		return VATNumber{}, &Error{Constraint: "eu_vat", Reason: reason}
+	}
+	return vat, nil
+}
+
+// checkVAT returns a VAT number, or the reason it is not valid. The country of the result is set
+// whenever it could be read.
+func checkVAT(number string, countries []string) (VATNumber, string) {
+	number = compact(number)
+	if len(number) < 3 || number[0] < 'A' || number[0] > 'Z' || number[1] < 'A' || number[1] > 'Z' {
+		return VATNumber{}, ReasonFormat
+	}
+
+	vat := VATNumber{Country: number[:2], Number: number[2:]}
+	check, ok := vatChecks[vat.Country]
+	if !ok || !containsCountry(countries, vat.Country) {
+		return vat, ReasonCountry
+	}
+	if reason := check(vat.Number); reason != "" {
+		return vat, reason
+	}
+	return vat, ""
+}
+
+// containsCountry reports whether country is accepted, an empty list accepting every country
+func containsCountry(countries []string, country string) bool {
+	if len(countries) == 0 {
+		return true
+	}
+	for _, c := range countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVATAustria checks "U" followed by 8 digits
+func checkVATAustria(number string) string {
+	if !strings.HasPrefix(number, "U") || !isDigits(number[1:]) {
+		return ReasonFormat
+	}
+	number = number[1:]
+	if len(number) != 8 {
+		return ReasonLength
+	}
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		d := digit(number, i) * (1 + i%2)
+		sum += d/10 + d%10
+	}
+	if (10-(sum+4)%10)%10 != digit(number, 7) {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATBelgium checks 10 digits, or 9 digits written without the leading 0
+func checkVATBelgium(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	if len(number) == 9 {
+		number = "0" + number
+	}
+	if len(number) != 10 {
+		return ReasonLength
+	}
+	if number[0] != '0' && number[0] != '1' {
+		return ReasonPrefix
+	}
+
+	value, _ := strconv.Atoi(number[:8])
+	check, _ := strconv.Atoi(number[8:])
+	if 97-value%97 != check {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATBulgaria checks 9 digits of companies; 10-digit numbers of people are checked for format only
+func checkVATBulgaria(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	switch len(number) {
+	case 9:
+		check := weightedSum(number, 1, 2, 3, 4, 5, 6, 7, 8) % 11
+		if check == 10 {
+			check = weightedSum(number, 3, 4, 5, 6, 7, 8, 9, 10) % 11 % 10
+		}
+		if check != digit(number, 8) {
+			return ReasonChecksum
+		}
+		return ""
+	case 10:
+		return ""
+	default:
+		return ReasonLength
+	}
+}
+
+// checkVATCyprus checks 8 digits followed by a check letter
+func checkVATCyprus(number string) string {
+	if len(number) != 9 {
+		return ReasonLength
+	}
+	if !isDigits(number[:8]) || number[8] < 'A' || number[8] > 'Z' {
+		return ReasonFormat
+	}
+
+	odd := [10]int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21}
+	sum := 0
+	for i := 0; i < 8; i++ {
+		if i%2 == 0 {
+			sum += odd[digit(number, i)]
+		} else {
+			sum += digit(number, i)
+		}
+	}
+	if byte('A'+sum%26) != number[8] {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATCzechia checks 8 digits of companies; 9 and 10-digit numbers of people are checked for format only
+func checkVATCzechia(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	switch len(number) {
+	case 8:
+		check := (11 - weightedSum(number, 8, 7, 6, 5, 4, 3, 2)%11) % 11
+		if check == 0 {
+			check = 1
+		}
+		if check%10 != digit(number, 7) {
+			return ReasonChecksum
+		}
+		return ""
+	case 9, 10:
+		return ""
+	default:
+		return ReasonLength
+	}
+}
+
+// checkVATGreece checks 9 digits
+func checkVATGreece(number string) bool {
+	return weightedSum(number, 256, 128, 64, 32, 16, 8, 4, 2)%11%10 == digit(number, 8)
+}
+
+// spanishLetters are the check letters of Spanish personal numbers (DNI and NIE), by remainder of 23
+const spanishLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// checkVATSpain checks the NIF of companies (a letter, 7 digits and a check character) and of people
+// (8 digits and a check letter, or X, Y or Z, 7 digits and a check letter)
+func checkVATSpain(number string) string {
+	if len(number) != 9 {
+		return ReasonLength
+	}
+	first, body, last := number[0], number[1:8], number[8]
+	if !isDigits(body) {
+		return ReasonFormat
+	}
+
+	switch {
+	case first >= '0' && first <= '9', first == 'X', first == 'Y', first == 'Z', first == 'K', first == 'L', first == 'M':
+		// People: DNI, NIE, and K, L and M numbers which share the check letter of the DNI
+		digits := number[:8]
+		switch first {
+		case 'X', 'K', 'L', 'M':
+			digits = "0" + body
+		case 'Y':
+			digits = "1" + body
+		case 'Z':
+			digits = "2" + body
+		}
+		if !isDigits(digits) {
+			return ReasonFormat
+		}
+		value, _ := strconv.Atoi(digits)
+		if spanishLetters[value%23] != last {
+			return ReasonChecksum
+		}
+		return ""
+	case strings.IndexByte("ABCDEFGHJNPQRSUVW", first) >= 0:
+		// Companies: the check character is a digit or a letter depending on the type of entity
+		sum := 0
+		for i := 0; i < 7; i++ {
+			d := digit(body, i)
+			if i%2 == 0 {
+				d *= 2
+				d = d/10 + d%10
+			}
+			sum += d
+		}
+		check := (10 - sum%10) % 10
+		if last != byte('0'+check) && last != "JABCDEFGHI"[check] {
+			return ReasonChecksum
+		}
+		return ""
+	default:
+		return ReasonFormat
+	}
+}
+
+// checkVATFinland checks 8 digits
+func checkVATFinland(number string) bool {
+	check := (11 - weightedSum(number, 7, 9, 10, 5, 8, 4, 2)%11) % 11
+	return check != 10 && check == digit(number, 7)
+}
+
+// checkVATFrance checks a 2-character key followed by the 9-digit SIREN of the company. Numeric keys are
+// derived from the SIREN; keys with letters are checked for format only.
+func checkVATFrance(number string) string {
+	if len(number) != 11 {
+		return ReasonLength
+	}
+	key, siren := number[:2], number[2:]
+	if !isDigits(siren) {
+		return ReasonFormat
+	}
+	for i := 0; i < 2; i++ {
+		if c := key[i]; !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z') || c == 'I' || c == 'O' {
+			return ReasonFormat
+		}
+	}
+	if !isDigits(key) {
+		return ""
+	}
+
+	value, _ := strconv.Atoi(siren)
+	check, _ := strconv.Atoi(key)
+	if (12+3*(value%97))%97 != check {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// irishLetters are the check letters of Irish VAT numbers, by remainder of 23
+const irishLetters = "WABCDEFGHIJKLMNOPQRSTUV"
+
+// checkVATIreland checks 7 digits followed by 1 or 2 letters, or the old format of a digit, a letter or
+// "+" or "*", 5 digits and a letter
+func checkVATIreland(number string) string {
+	if len(number) != 8 && len(number) != 9 {
+		return ReasonLength
+	}
+
+	// Old format, e.g. "8Z49289F", converted to the new one
+	if len(number) == 8 && !isDigits(number[1:2]) {
+		if c := number[1]; !(c >= 'A' && c <= 'Z' || c == '+' || c == '*') || !isDigits(number[:1]) {
+			return ReasonFormat
+		}
+		number = "0" + number[2:7] + number[:1] + number[7:]
+	}
+
+	if !isDigits(number[:7]) {
+		return ReasonFormat
+	}
+	for _, c := range number[7:] {
+		if c < 'A' || c > 'Z' {
+			return ReasonFormat
+		}
+	}
+
+	sum := weightedSum(number, 8, 7, 6, 5, 4, 3, 2)
+	if len(number) == 9 && number[8] != 'W' {
+		sum += int(number[8]-'A'+1) * 9
+	}
+	if irishLetters[sum%23] != number[7] {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATLithuania checks 9 digits of companies and 12 digits of temporary taxpayers
+func checkVATLithuania(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	if len(number) != 9 && len(number) != 12 {
+		return ReasonLength
+	}
+	if number[len(number)-2] != '1' {
+		return ReasonFormat
+	}
+
+	weights := func(start int) []int {
+		w := make([]int, len(number)-1)
+		for i := range w {
+			w[i] = 1 + (i+start)%9
+		}
+		return w
+	}
+	check := weightedSum(number, weights(0)...) % 11
+	if check == 10 {
+		check = weightedSum(number, weights(2)...) % 11 % 10
+	}
+	if check != digit(number, len(number)-1) {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATLatvia checks 11 digits. Numbers of companies start with a digit above 3 and have a check digit;
+// numbers of people are checked for format only.
+func checkVATLatvia(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	if len(number) != 11 {
+		return ReasonLength
+	}
+	if number[0] > '3' && weightedSum(number, 9, 1, 4, 8, 3, 10, 2, 5, 7, 6, 1)%11 != 3 {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATNetherlands checks 9 digits, "B" and 2 digits. Numbers issued to sole proprietors since 2020
+// use an ISO 7064 MOD 97-10 checksum on the whole number instead of the mod 11 checksum of the first 9 digits.
+func checkVATNetherlands(number string) string {
+	if len(number) != 12 {
+		return ReasonLength
+	}
+	if !isDigits(number[:9]) || number[9] != 'B' || !isDigits(number[10:]) {
+		return ReasonFormat
+	}
+
+	if weightedSum(number, 9, 8, 7, 6, 5, 4, 3, 2)%11 == digit(number, 8) {
+		return ""
+	}
+
+	// "NL" and "B" are converted to numbers like in IBANs: N=23, L=21, B=11
+	remainder := 0
+	for _, r := range "2321" + number[:9] + "11" + number[10:] {
+		remainder = (remainder*10 + int(r-'0')) % 97
+	}
+	if remainder != 1 {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATPortugal checks 9 digits
+func checkVATPortugal(number string) bool {
+	check := 11 - weightedSum(number, 9, 8, 7, 6, 5, 4, 3, 2)%11
+	if check >= 10 {
+		check = 0
+	}
+	return check == digit(number, 8)
+}
+
+// checkVATRomania checks 2 to 10 digits
+func checkVATRomania(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	if len(number) < 2 || len(number) > 10 {
+		return ReasonLength
+	}
+
+	padded := strings.Repeat("0", 10-len(number)) + number
+	if weightedSum(padded, 7, 5, 3, 2, 1, 7, 5, 3, 2)*10%11%10 != digit(padded, 9) {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATSweden checks the 10-digit organisation number followed by "01"
+func checkVATSweden(number string) string {
+	if !isDigits(number) {
+		return ReasonFormat
+	}
+	if len(number) != 12 {
+		return ReasonLength
+	}
+	if number[10:] != "01" {
+		return ReasonFormat
+	}
+	if !luhn(number[:10]) {
+		return ReasonChecksum
+	}
+	return ""
+}
+
+// checkVATSlovenia checks 8 digits
+func checkVATSlovenia(number string) bool {
+	check := 11 - weightedSum(number, 8, 7, 6, 5, 4, 3, 2)%11
+	if check == 10 {
+		check = 0
+	}
+	return number[0] != '0' && check == digit(number, 7)
+}
+
+// checkVATSlovakia checks 10 digits
+func checkVATSlovakia(number string) bool {
+	value, _ := strconv.ParseInt(number, 10, 64)
+	return number[0] != '0' && value%11 == 0
+}
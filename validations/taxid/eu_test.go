@@ -0,0 +1,63 @@
+package taxid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVAT(t *testing.T) {
+	t.Run("Valid numbers of each member state", func(t *testing.T) {
+		for _, number := range []string{
+			"ATU13585627", "BE 0403.019.261", "BE403019261", "BG175074752", "CY10259033P", "CZ25123891",
+			"DE136695976", "DK 13 58 56 28", "EE100931558", "EL094259216", "ES54362315K", "ESX2482300W",
+			"ESA58818501", "FI20774740", "FR 40 303 265 045", "FRK7399859412", "HR33392005961", "HU12892312",
+			"IE6433435F", "IE6433435OA", "IE8Z49289F", "IT00743110157", "LT119511515", "LU15027442",
+			"LV40003521600", "MT11679112", "NL004495445B01", "PL8567346215", "PT501964843", "RO18547290",
+			"SE123456789701", "SI50223054", "SK2022749619",
+		} {
+			_, err := ParseVAT(number)
+			assert.NoError(t, err, number)
+		}
+
+		vat, err := ParseVAT("de 136 695 976")
+		assert.NoError(t, err)
+		assert.Equal(t, VATNumber{Country: "DE", Number: "136695976"}, vat)
+		assert.Equal(t, "DE136695976", vat.String())
+	})
+
+	t.Run("Invalid numbers", func(t *testing.T) {
+		tests := []struct {
+			number string
+			reason string
+		}{
+			{"", ReasonFormat},
+			{"136695976", ReasonFormat},
+			{"US136695976", ReasonCountry},
+			{"GR094259216", ReasonCountry},
+			{"DE13669597", ReasonLength},
+			{"DE136695977", ReasonChecksum},
+			{"ATU13585626", ReasonChecksum},
+			{"BE2403019261", ReasonPrefix},
+			{"FR41303265045", ReasonChecksum},
+			{"IE6433435E", ReasonChecksum},
+			{"NL004495445A01", ReasonFormat},
+			{"NL004495446B01", ReasonChecksum},
+			{"PL8567346216", ReasonChecksum},
+			{"SE123456789702", ReasonFormat},
+			{"ES54362315J", ReasonChecksum},
+		}
+		for _, tt := range tests {
+			_, err := ParseVAT(tt.number)
+			assertReason(t, tt.reason, err, tt.number)
+		}
+	})
+
+	t.Run("Accepted countries", func(t *testing.T) {
+		_, err := ParseVAT("DE136695976", "de", "FR")
+		assert.NoError(t, err)
+
+		_, err = ParseVAT("IT00743110157", "DE", "FR")
+		assertReason(t, ReasonCountry, err, "IT")
+	})
+}
@@ -0,0 +1,187 @@
+// Package taxid validates government and tax identifiers offline, with their check digit algorithms.
+//
+// Each country or region is registered with one call, which registers its tags on a validator:
+//
+//	taxid.AddCanadaValidations(v) // ca_sin
+//	taxid.AddUSValidations(v)     // us_ssn, us_ein
+//	taxid.AddEUValidations(v)     // eu_vat
+//	taxid.AddBrazilValidations(v) // br_cpf, br_cnpj
+//
+// Identifiers of people (SIN, SSN and CPF) are registered as sensitive with
+// validator.RegisterSensitiveTag, so their values are masked in ValidationError.Actual and messages.
+package taxid
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/juancwu/go-valkit/v2/validator"
+)
+
+// Reasons reported by the validations of this package
+const (
+	ReasonFormat   = "format"   // Invalid characters or structure
+	ReasonLength   = "length"   // Invalid number of digits
+	ReasonChecksum = "checksum" // The check digits do not match, usually a typo
+	ReasonPrefix   = "prefix"   // The first digits are not assigned, e.g. a SIN starting with 0
+	ReasonCountry  = "country"  // Unknown VAT country code, or a country that is not accepted
+	ReasonArea     = "area"     // Invalid SSN area number (first 3 digits)
+	ReasonGroup    = "group"    // Invalid SSN group number (middle 2 digits)
+	ReasonSerial   = "serial"   // Invalid SSN serial number (last 4 digits)
+	ReasonRepeated = "repeated" // All digits are the same, e.g. "111.111.111-11"
+)
+
+// Error is returned by the Parse* functions of identifiers that are not valid. It does not include the
+// value, as identifiers of people are sensitive.
+type Error struct {
+	Constraint string // Tag of the validation, e.g. "ca_sin"
+	Reason     string // One of the Reason* values
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("taxid: invalid %s: %s", e.Constraint, e.Reason)
+}
+
+// messages are the default messages of each reason. Messages do not include the value.
+var messages = map[string]string{
+	ReasonFormat:   "{field} must be a valid {name}",
+	ReasonLength:   "{field} has an invalid number of digits",
+	ReasonChecksum: "{field} is not a valid {name}, check for typos",
+}
+
+// message returns the default message of a reason
+func message(reason string) string {
+	if msg, ok := messages[reason]; ok {
+		return msg
+	}
+	return "{field} is not a valid {name}"
+}
+
+// identifier describes a validation tag of an identifier without params
+type identifier struct {
+	tag    string                              // Validation tag, e.g. "ca_sin"
+	name   string                              // Name of the identifier in messages, e.g. "SIN"
+	check  func(value string) (string, string) // Returns the normalized value, or the reason it is not valid
+	redact validator.Redactor                  // Redactor of the values, nil if the identifier is not sensitive
+}
+
+// parse checks value with id and returns the normalized value, or an *Error
+func (id identifier) parse(value string) (string, error) {
+	normalized, reason := id.check(value)
+	if reason != "" {
+		return "", &Error{Constraint: id.tag, Reason: reason}
+	}
+	return normalized, nil
+}
+
+// register registers the tags of identifiers on v, and their sensitive values
+func register(v *validator.Validator, ids ...identifier) error {
+	for _, id := range ids {
+		id := id
+		err := v.RegisterViolationValidation(id.tag, func(ctx context.Context, fl govalidator.FieldLevel) []validator.Violation {
+			_, reason := id.check(fieldString(fl))
+			if reason == "" {
+				return nil
+			}
+			return []validator.Violation{{
+				Reason:  reason,
+				Message: message(reason),
+				Params:  map[string]interface{}{"name": id.name},
+			}}
+		})
+		if err != nil {
+			return err
+		}
+
+		if id.redact != nil {
+			v.RegisterSensitiveTag(id.redact, id.tag)
+		}
+	}
+	return nil
+}
+
+// fieldString returns the value of a string field, or an empty string
+func fieldString(fl govalidator.FieldLevel) string {
+	if fl.Field().Kind() != reflect.String {
+		return ""
+	}
+	return fl.Field().String()
+}
+
+// compact removes the separators commonly used to format identifiers and converts letters to upper case
+func compact(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '/', '\t':
+			return -1
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}, strings.TrimSpace(value))
+}
+
+// isDigits reports whether s is not empty and only contains ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// digit returns the value of the ASCII digit at index i of s
+func digit(s string, i int) int {
+	return int(s[i] - '0')
+}
+
+// weightedSum returns the sum of the digits of s multiplied by the weights, stopping at the shortest
+func weightedSum(s string, weights ...int) int {
+	sum := 0
+	for i := 0; i < len(s) && i < len(weights); i++ {
+		sum += digit(s, i) * weights[i]
+	}
+	return sum
+}
+
+// luhn reports whether the digits of s pass the Luhn algorithm, the last digit being the check digit
+func luhn(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		d := digit(s, i)
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// mod11x10 reports whether the digits of s pass ISO 7064 MOD 11,10, the last digit being the check digit
+func mod11x10(s string) bool {
+	product := 10
+	for i := 0; i < len(s)-1; i++ {
+		sum := (digit(s, i) + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+	return (11-product)%10 == digit(s, len(s)-1)
+}
+
+// allSame reports whether every character of s is the same
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
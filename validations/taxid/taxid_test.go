@@ -0,0 +1,116 @@
+package taxid
+
+import (
+	"testing"
+
+	"github.com/juancwu/go-valkit/v2/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerivedValidatorsRedact(t *testing.T) {
+	type Applicant struct {
+		SIN string `json:"sin" validate:"ca_sin"`
+	}
+
+	// Validators derived before the registration run the tags, and redact their values
+	v := validator.New()
+	derived := v.UseMessages(validator.NewValidationMessages())
+	assert.NoError(t, AddCanadaValidations(v))
+
+	errs := derived.Validate(Applicant{SIN: "130 692 545"}).(validator.ValidationErrors)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "****545", errs[0].Actual)
+}
+
+func TestValidations(t *testing.T) {
+	type Applicant struct {
+		SIN  string `json:"sin" validate:"omitempty,ca_sin"`
+		SSN  string `json:"ssn" validate:"omitempty,us_ssn"`
+		EIN  string `json:"ein" validate:"omitempty,us_ein"`
+		VAT  string `json:"vat" validate:"omitempty,eu_vat"`
+		SEPA string `json:"sepa" validate:"omitempty,eu_vat=DE FR"`
+		CPF  string `json:"cpf" validate:"omitempty,br_cpf"`
+		CNPJ string `json:"cnpj" validate:"omitempty,br_cnpj"`
+	}
+
+	v := validator.New()
+	v.UseJsonTagName()
+	assert.NoError(t, AddCanadaValidations(v))
+	assert.NoError(t, AddUSValidations(v))
+	assert.NoError(t, AddEUValidations(v))
+	assert.NoError(t, AddBrazilValidations(v))
+
+	t.Run("Valid identifiers", func(t *testing.T) {
+		assert.NoError(t, v.Validate(Applicant{
+			SIN:  "130 692 544",
+			SSN:  "123-45-6789",
+			EIN:  "12-3456789",
+			VAT:  "IT00743110157",
+			SEPA: "DE136695976",
+			CPF:  "390.533.447-05",
+			CNPJ: "11.222.333/0001-81",
+		}))
+	})
+
+	t.Run("Reasons and messages", func(t *testing.T) {
+		errs := v.Validate(Applicant{
+			SIN:  "130 692 545",
+			SSN:  "666-45-6789",
+			SEPA: "IT00743110157",
+			CPF:  "390.533.447-06",
+		}).(validator.ValidationErrors)
+
+		assert.Len(t, errs, 4)
+		assert.Equal(t, "ca_sin", errs[0].Constraint)
+		assert.Equal(t, ReasonChecksum, errs[0].Reason)
+		assert.Equal(t, "sin is not a valid SIN, check for typos", errs[0].Message)
+
+		assert.Equal(t, "us_ssn", errs[1].Constraint)
+		assert.Equal(t, ReasonArea, errs[1].Reason)
+		assert.Equal(t, "ssn is not a valid SSN", errs[1].Message)
+
+		assert.Equal(t, "eu_vat", errs[2].Constraint)
+		assert.Equal(t, ReasonCountry, errs[2].Reason)
+		assert.Equal(t, "IT", errs[2].Params["country"])
+		assert.Equal(t, "sepa must be a VAT number from DE FR", errs[2].Message)
+
+		assert.Equal(t, "br_cpf", errs[3].Constraint)
+		assert.Equal(t, ReasonChecksum, errs[3].Reason)
+	})
+
+	t.Run("Identifiers of people are redacted", func(t *testing.T) {
+		errs := v.Validate(Applicant{
+			SIN:  "130 692 545",
+			SSN:  "666-45-6789",
+			EIN:  "07-3456789",
+			CPF:  "390.533.447-06",
+			CNPJ: "11.222.333/0001-82",
+		}).(validator.ValidationErrors)
+
+		assert.Len(t, errs, 5)
+		assert.Equal(t, "****545", errs[0].Actual)
+		assert.Equal(t, "****6789", errs[1].Actual)
+		assert.Equal(t, "07-3456789", errs[2].Actual)
+		assert.Equal(t, "****06", errs[3].Actual)
+		assert.Equal(t, "11.222.333/0001-82", errs[4].Actual)
+	})
+
+	t.Run("Message per reason", func(t *testing.T) {
+		v := validator.New()
+		assert.NoError(t, AddUSValidations(v))
+		v.SetDefaultTagMessage("us_ssn.area", "{name} cannot start with {value}")
+
+		type Employee struct {
+			SSN string `validate:"us_ssn"`
+		}
+		errs := v.Validate(Employee{SSN: "666-45-6789"}).(validator.ValidationErrors)
+		assert.Equal(t, "SSN cannot start with ****6789", errs[0].Message)
+	})
+
+	t.Run("Unknown VAT country panics", func(t *testing.T) {
+		type Invalid struct {
+			VAT string `validate:"eu_vat=US"`
+		}
+		assert.Panics(t, func() { _ = v.Validate(Invalid{VAT: "DE136695976"}) })
+	})
+}
@@ -0,0 +1,89 @@
+package taxid
+
+import "github.com/juancwu/go-valkit/v2/validator"
+
+// ssn describes the US Social Security Number
+var ssn = identifier{
+	tag:    "us_ssn",
+	name:   "SSN",
+	check:  checkSSN,
+	redact: validator.MaskKeepLast(4),
+}
+
+// ein describes the US Employer Identification Number
+var ein = identifier{
+	tag:   "us_ein",
+	name:  "EIN",
+	check: checkEIN,
+}
+
+// einPrefixes are the prefixes assigned by the IRS to its campuses and to online applications
+var einPrefixes = func() map[string]bool {
+	prefixes := make(map[string]bool)
+	for _, r := range [][2]int{{1, 6}, {10, 16}, {20, 27}, {30, 48}, {50, 68}, {71, 77}, {80, 88}, {90, 95}, {98, 99}} {
+		for p := r[0]; p <= r[1]; p++ {
+			prefixes[string([]byte{byte('0' + p/10), byte('0' + p%10)})] = true
+		}
+	}
+	return prefixes
+}()
+
+// AddUSValidations registers the US identifiers:
+//   - "us_ssn": Social Security Number, "AAA-GG-SSSS", with the area, group and serial rules of the SSA
+//   - "us_ein": Employer Identification Number, "XX-XXXXXXX", with a prefix assigned by the IRS
+//
+// SSNs and EINs have no check digit. SSNs are registered as sensitive and masked except for their last
+// 4 digits.
+func AddUSValidations(v *validator.Validator) error {
+	return register(v, ssn, ein)
+}
+
+// ParseSSN validates a US Social Security Number and returns its 9 digits.
+// Invalid numbers return an *Error with the reason.
+func ParseSSN(number string) (string, error) {
+	return ssn.parse(number)
+}
+
+// ParseEIN validates a US Employer Identification Number and returns its 9 digits.
+// Invalid numbers return an *Error with the reason.
+func ParseEIN(number string) (string, error) {
+	return ein.parse(number)
+}
+
+// checkSSN returns the digits of an SSN, or the reason it is not valid
+func checkSSN(number string) (string, string) {
+	number = compact(number)
+	if !isDigits(number) {
+		return "", ReasonFormat
+	}
+	if len(number) != 9 {
+		return "", ReasonLength
+	}
+
+	// Areas 000 and 666 are never assigned, and 900-999 are used for taxpayer identification numbers
+	if area := number[:3]; area == "000" || area == "666" || area[0] == '9' {
+		return "", ReasonArea
+	}
+	if number[3:5] == "00" {
+		return "", ReasonGroup
+	}
+	if number[5:] == "0000" {
+		return "", ReasonSerial
+	}
+	return number, ""
+}
+
+// checkEIN returns the digits of an EIN, or the reason it is not valid
+func checkEIN(number string) (string, string) {
+	number = compact(number)
+	if !isDigits(number) {
+		return "", ReasonFormat
+	}
+	if len(number) != 9 {
+		return "", ReasonLength
+	}
+	if !einPrefixes[number[:2]] {
+		return "", ReasonPrefix
+	}
+	return number, ""
+}
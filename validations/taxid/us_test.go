@@ -0,0 +1,50 @@
+package taxid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSSN(t *testing.T) {
+	ssn, err := ParseSSN("123-45-6789")
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789", ssn)
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"123-45-678X", ReasonFormat},
+		{"123-45-678", ReasonLength},
+		{"000-45-6789", ReasonArea},
+		{"666-45-6789", ReasonArea},
+		{"912-45-6789", ReasonArea},
+		{"123-00-6789", ReasonGroup},
+		{"123-45-0000", ReasonSerial},
+	}
+	for _, tt := range tests {
+		_, err := ParseSSN(tt.number)
+		assertReason(t, tt.reason, err, tt.number)
+	}
+}
+
+func TestParseEIN(t *testing.T) {
+	ein, err := ParseEIN("12-3456789")
+	assert.NoError(t, err)
+	assert.Equal(t, "123456789", ein)
+
+	tests := []struct {
+		number string
+		reason string
+	}{
+		{"12-345678A", ReasonFormat},
+		{"12-345678", ReasonLength},
+		{"07-3456789", ReasonPrefix},
+		{"89-3456789", ReasonPrefix},
+	}
+	for _, tt := range tests {
+		_, err := ParseEIN(tt.number)
+		assertReason(t, tt.reason, err, tt.number)
+	}
+}
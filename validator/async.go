@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	govalidator "github.com/go-playground/validator/v10"
+)
+
+// DefaultAsyncConcurrency is the default maximum number of async and deferred validations running at once
+// during a ValidateCtx call
+const DefaultAsyncConcurrency = 8
+
+// TimeoutConstraint is the constraint of the errors of fields whose async or deferred validation did not
+// finish before the deadline of the context, or AsyncTimeout. The param of the error is the tag of the
+// validation, so messages can use {param}.
+const TimeoutConstraint = "timeout"
+
+// timeoutMessage is the default message of TimeoutConstraint errors
+const timeoutMessage = "{field} could not be validated in time"
+
+// AsyncFunc validates the value of one field, like a ViolationFunc, but runs concurrently with the
+// validations of the other fields once go-playground is done with the struct. It is meant for slow
+// checks such as DNS lookups. An empty result means the field is valid.
+//
+// The field is only available as a value, since the go-playground FieldLevel is reused once the struct has
+// been traversed. fn should return when ctx is done.
+type AsyncFunc func(ctx context.Context, param string, value interface{}) []Violation
+
+// RegisterAsyncValidation registers a slow validation with the given tag. The fields using it are validated
+// on a pool of at most AsyncConcurrency goroutines, after every other validation of the struct:
+//
+//	v.RegisterAsyncValidation("domain_exists", func(ctx context.Context, param string, value interface{}) []validator.Violation {
+//	    if _, err := net.DefaultResolver.LookupHost(ctx, value.(string)); err != nil {
+//	        return []validator.Violation{{Reason: "not_found", Message: "{field} must be an existing domain"}}
+//	    }
+//	    return nil
+//	})
+//
+//	type Import struct {
+//	    Domains []string `validate:"dive,fqdn,domain_exists"`
+//	}
+//
+// The validations use the context passed to ValidateCtx, limited to AsyncTimeout. Fields that are not
+// validated before it is done are reported with the TimeoutConstraint constraint, and ValidateCtx
+// returns without waiting for them. Errors are in the order of the fields, whatever the order in
+// which the validations finish.
+//
+// Like deferred validations, an async validation only runs for the values that passed the tags before it,
+// and must be the last tag of a field, or of the keys of a map. ValidateCtx returns an error instead of
+// ValidationErrors for fields with tags after it. In an OR group, it only runs when every other tag of the
+// group fails. When the underlying go-playground validator is used directly, fn is called on the field right away.
+func (v *Validator) RegisterAsyncValidation(tag string, fn AsyncFunc, callValidationEvenIfNull ...bool) error {
+	return v.BaseValidator.RegisterValidationCtx(tag, func(ctx context.Context, fl govalidator.FieldLevel) bool {
+		return RunAsync(ctx, fl, fn)
+	}, callValidationEvenIfNull...)
+}
+
+// RunAsync validates the field of fl with fn concurrently, see RegisterAsyncValidation. It is meant for
+// validations registered on the BaseValidator that are only slow for some fields, or that check their
+// params first:
+//
+//	v.BaseValidator.RegisterValidationCtx("mx", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+//	    if fl.Param() == "skip" {
+//	        return true
+//	    }
+//	    return validator.RunAsync(ctx, fl, lookupMX)
+//	})
+func RunAsync(ctx context.Context, fl govalidator.FieldLevel, fn AsyncFunc) bool {
+	record := newViolationRecord(fl.GetTag(), fl)
+
+	if collector := violationCollectorFromContext(ctx); collector != nil {
+		if !isLastTag(fl) {
+			return AbortValidation(ctx, fmt.Errorf("validator: async validation '%s' on field '%s' must be the last tag, the tags after it would not be checked", fl.GetTag(), fl.StructFieldName()))
+		}
+
+		// The field error reported by go-playground is replaced with the result of fn, or dropped
+		record.async = fn
+		collector.add(record)
+		return false
+	}
+
+	return len(fn(ctx, fl.Param(), record.value)) == 0
+}
+
+// SetAsyncConcurrency sets the maximum number of async and deferred validations running at once during a
+// ValidateCtx call. A value <= 0 uses DefaultAsyncConcurrency.
+func (v *Validator) SetAsyncConcurrency(n int) *Validator {
+	v.AsyncConcurrency = n
+	return v
+}
+
+// SetAsyncTimeout sets the maximum duration of the async and deferred validations of a ValidateCtx call,
+// in addition to the deadline of its context. A value <= 0 only uses the deadline of the context.
+func (v *Validator) SetAsyncTimeout(d time.Duration) *Validator {
+	v.AsyncTimeout = d
+	return v
+}
+
+// asyncJob is a call of an async or deferred validation, checking one or several fields
+type asyncJob struct {
+	tag      string
+	fields   []deferredField
+	deferred violationRecord // First record of a batch of deferred validations
+	run      func(ctx context.Context) ([][]Violation, error)
+}
+
+// asyncResult is the result of an asyncJob
+type asyncResult struct {
+	job        int
+	violations [][]Violation
+	err        error
+	panicValue interface{}
+}
+
+// runJobs runs jobs on a pool of goroutines and returns their results, in the order of jobs. The results of
+// the jobs that did not finish before ctx is done, or AsyncTimeout, are nil, and ValidateCtx reports their
+// fields as timeouts. Panics of jobs are raised again in the calling goroutine.
+func (v *Validator) runJobs(ctx context.Context, jobs []asyncJob) []*asyncResult {
+	if v.AsyncTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.AsyncTimeout)
+		defer cancel()
+	}
+
+	workers := v.AsyncConcurrency
+	if workers <= 0 {
+		workers = DefaultAsyncConcurrency
+	}
+	workers = min(workers, len(jobs))
+
+	queue := make(chan int, len(jobs))
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+
+	// Buffered so workers never block on results that are no longer awaited after a timeout
+	done := make(chan asyncResult, len(jobs))
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range queue {
+				if ctx.Err() != nil {
+					done <- asyncResult{job: i, err: ctx.Err()}
+					continue
+				}
+				done <- runJob(ctx, i, jobs[i])
+			}
+		}()
+	}
+
+	results := make([]*asyncResult, len(jobs))
+	for received := 0; received < len(jobs); received++ {
+		select {
+		case result := <-done:
+			if result.panicValue != nil {
+				panic(result.panicValue)
+			}
+			if !isTimeout(ctx, result.err) {
+				results[result.job] = &result
+			}
+		case <-ctx.Done():
+			return results
+		}
+	}
+	return results
+}
+
+// runJob runs a job, recovering its panics
+func runJob(ctx context.Context, i int, job asyncJob) (result asyncResult) {
+	result.job = i
+	defer func() {
+		if r := recover(); r != nil {
+			result.panicValue = r
+		}
+	}()
+	result.violations, result.err = job.run(ctx)
+	return result
+}
+
+// isTimeout reports whether err means that a job did not finish before ctx was done
+func isTimeout(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	return ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// timeoutError returns the error of a field whose validation did not finish in time
+func (v *Validator) timeoutError(ctx context.Context, tag string, d deferredError) ValidationError {
+	e := d.err
+	e.Constraint = TimeoutConstraint
+	e.Param = tag
+	e.Reason = ""
+	e.Params = nil
+	return v.completeError(ctx, e, d.structField, d.found, d.normPath, timeoutMessage)
+}
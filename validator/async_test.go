@@ -0,0 +1,204 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowDomainCheck returns an AsyncFunc used in tests that takes delay to reject domains ending with
+// ".invalid", and records the number of checks running at once
+func slowDomainCheck(delay time.Duration, running, maxRunning *atomic.Int32) AsyncFunc {
+	return func(ctx context.Context, param string, value interface{}) []Violation {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil
+		}
+
+		if strings.HasSuffix(value.(string), ".invalid") {
+			return []Violation{{Reason: "not_found", Message: "{field} must be an existing domain"}}
+		}
+		return nil
+	}
+}
+
+func TestRegisterAsyncValidation(t *testing.T) {
+	type Import struct {
+		Domains []string `json:"domains" validate:"dive,required,domain_exists"`
+	}
+
+	t.Run("Concurrent and bounded", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		v := New().UseJsonTagName().SetAsyncConcurrency(50)
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", slowDomainCheck(10*time.Millisecond, &running, &maxRunning)))
+
+		domains := make([]string, 500)
+		for i := range domains {
+			domains[i] = fmt.Sprintf("example%d.com", i)
+		}
+
+		start := time.Now()
+		assert.NoError(t, v.Validate(Import{Domains: domains}))
+		assert.Less(t, time.Since(start), 2*time.Second) // 5s when run serially
+		assert.LessOrEqual(t, maxRunning.Load(), int32(50))
+		assert.Greater(t, maxRunning.Load(), int32(1))
+	})
+
+	t.Run("Deterministic order", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", func(ctx context.Context, param string, value interface{}) []Violation {
+			// Later fields finish first
+			domain := value.(string)
+			time.Sleep(time.Duration(10-len(domain)) * time.Millisecond)
+			if strings.HasSuffix(domain, ".invalid") {
+				return []Violation{{Reason: "not_found", Message: "{field} must be an existing domain"}}
+			}
+			return nil
+		}))
+
+		for i := 0; i < 5; i++ {
+			errs := v.Validate(Import{Domains: []string{"a.invalid", "", "bb.com", "cc.invalid", "ddd.invalid"}}).(ValidationErrors)
+			assert.Len(t, errs, 4)
+			assert.Equal(t, "domains[0]", errs[0].Path)
+			assert.Equal(t, "not_found", errs[0].Reason)
+			assert.Equal(t, "domains[1]", errs[1].Path)
+			assert.Equal(t, "required", errs[1].Constraint)
+			assert.Equal(t, "domains[3]", errs[2].Path)
+			assert.Equal(t, "domains[4]", errs[3].Path)
+			assert.Equal(t, "domains[4] must be an existing domain", errs[3].Message)
+		}
+	})
+
+	t.Run("Deadline of the context", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", func(ctx context.Context, param string, value interface{}) []Violation {
+			if value == "slow.com" {
+				time.Sleep(time.Second) // Ignores the context
+			}
+			return nil
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		errs := v.ValidateCtx(ctx, Import{Domains: []string{"fast.com", "slow.com"}}).(ValidationErrors)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "domains[1]", errs[0].Path)
+		assert.Equal(t, TimeoutConstraint, errs[0].Constraint)
+		assert.Equal(t, "domain_exists", errs[0].Param)
+		assert.Equal(t, "slow.com", errs[0].Actual)
+		assert.Equal(t, "domains[1] could not be validated in time", errs[0].Message)
+	})
+
+	t.Run("Async timeout", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		v := New().UseJsonTagName().SetAsyncTimeout(20 * time.Millisecond).SetAsyncConcurrency(2)
+		v.SetDefaultTagMessage(TimeoutConstraint, "Could not check {field} with {param}")
+		v.SetDefaultTagCode(TimeoutConstraint, "ERR_TIMEOUT")
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", slowDomainCheck(time.Second, &running, &maxRunning)))
+
+		errs := v.Validate(Import{Domains: []string{"a.com", "b.com", "c.com"}}).(ValidationErrors)
+		assert.Len(t, errs, 3)
+		for i, e := range errs {
+			assert.Equal(t, fmt.Sprintf("domains[%d]", i), e.Path)
+			assert.Equal(t, TimeoutConstraint, e.Constraint)
+			assert.Equal(t, "ERR_TIMEOUT", e.Code)
+			assert.Equal(t, fmt.Sprintf("Could not check domains[%d] with domain_exists", i), e.Message)
+		}
+
+		// The settings are kept by UseMessages
+		scoped := v.UseMessages(NewValidationMessages())
+		assert.Equal(t, 20*time.Millisecond, scoped.AsyncTimeout)
+		assert.Equal(t, 2, scoped.AsyncConcurrency)
+	})
+
+	t.Run("Deferred validations time out too", func(t *testing.T) {
+		v := New().UseJsonTagName().SetAsyncTimeout(20 * time.Millisecond)
+		assert.NoError(t, v.RegisterDeferredValidation("domain_exists", func(ctx context.Context, param string, values []interface{}) ([][]Violation, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+
+		errs := v.Validate(Import{Domains: []string{"a.com", "b.com"}}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, TimeoutConstraint, errs[0].Constraint)
+		assert.Equal(t, TimeoutConstraint, errs[1].Constraint)
+	})
+
+	t.Run("OR groups", func(t *testing.T) {
+		type Host struct {
+			Name string `json:"name" validate:"domain_exists|eq=localhost"`
+		}
+		type Hosts struct {
+			Hosts []Host `json:"hosts" validate:"dive"`
+		}
+
+		var running, maxRunning atomic.Int32
+		v := New().UseJsonTagName()
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", slowDomainCheck(0, &running, &maxRunning)))
+
+		// Valid async results are not reported as failures of the group
+		assert.NoError(t, v.Validate(Hosts{Hosts: []Host{{Name: "localhost"}, {Name: "example.com"}}}))
+
+		errs := v.Validate(Hosts{Hosts: []Host{{Name: "localhost"}, {Name: "a.invalid"}, {Name: "localhost"}, {Name: "b.invalid"}}}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "hosts[1].name", errs[0].Path)
+		assert.Equal(t, "domain_exists", errs[0].Constraint)
+		assert.Equal(t, "not_found", errs[0].Reason)
+		assert.Equal(t, "a.invalid", errs[0].Actual)
+		assert.Equal(t, "hosts[3].name", errs[1].Path)
+		assert.Equal(t, "b.invalid", errs[1].Actual)
+	})
+
+	t.Run("Tags after an async validation", func(t *testing.T) {
+		type Host struct {
+			Name string `json:"name" validate:"domain_exists,max=2"`
+		}
+
+		var running, maxRunning atomic.Int32
+		v := New().UseJsonTagName()
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", slowDomainCheck(0, &running, &maxRunning)))
+
+		// max=2 would never run, so the struct tag is rejected instead of passing "toolong.com"
+		err := v.Validate(Host{Name: "toolong.com"})
+		assert.EqualError(t, err, "validator: async validation 'domain_exists' on field 'Name' must be the last tag, the tags after it would not be checked")
+		_, ok := err.(ValidationErrors)
+		assert.False(t, ok)
+	})
+
+	t.Run("Panics are raised by ValidateCtx", func(t *testing.T) {
+		v := New()
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", func(ctx context.Context, param string, value interface{}) []Violation {
+			panic("resolver is nil")
+		}))
+
+		assert.PanicsWithValue(t, "resolver is nil", func() { _ = v.Validate(Import{Domains: []string{"a.com"}}) })
+	})
+
+	t.Run("Base validator", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		v := New()
+		assert.NoError(t, v.RegisterAsyncValidation("domain_exists", slowDomainCheck(0, &running, &maxRunning)))
+
+		assert.NoError(t, v.BaseValidator.Struct(Import{Domains: []string{"a.com"}}))
+		assert.Error(t, v.BaseValidator.Struct(Import{Domains: []string{"a.invalid"}}))
+	})
+}
@@ -0,0 +1,256 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// BatchOptions configures ValidateBatch
+type BatchOptions struct {
+	Concurrency int // Number of items validated at once, 1 or less validates them one after the other
+	MaxErrors   int // Number of errors after which no more items are validated, 0 for no limit
+}
+
+// BatchResult is the result of ValidateBatch
+type BatchResult struct {
+	Errors    map[int]ValidationErrors // Errors of the invalid items by index, with paths prefixed by "[index]."
+	Failures  map[int]error            // Errors of the items that could not be validated by index, e.g. nil pointers
+	Validated int                      // Number of items validated
+	Stopped   bool                     // Not every item was validated, because of MaxErrors or the context
+}
+
+// Valid reports whether every validated item is valid
+func (r *BatchResult) Valid() bool {
+	return len(r.Errors) == 0 && len(r.Failures) == 0
+}
+
+// Indexes returns the indexes of the invalid items, in ascending order
+func (r *BatchResult) Indexes() []int {
+	indexes := make([]int, 0, len(r.Errors))
+	for i := range r.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// AllErrors returns the errors of every invalid item in the order of the items, or nil if there are none.
+// The paths of the errors start with the index of their item, e.g. "[2].email".
+func (r *BatchResult) AllErrors() ValidationErrors {
+	var all ValidationErrors
+	for _, i := range r.Indexes() {
+		all = append(all, r.Errors[i]...)
+	}
+	return all
+}
+
+// ValidateBatch validates many structs, such as the records of a bulk endpoint, and returns the errors of
+// each invalid item by index. items is a slice or an array of structs or pointers to structs, or an
+// iter.Seq of them, which is read once, as the items are validated.
+//
+// Example:
+//
+//	result, err := v.ValidateBatch(ctx, users, validator.BatchOptions{Concurrency: 8, MaxErrors: 100})
+//	if err != nil {
+//	    return err
+//	}
+//	for _, i := range result.Indexes() {
+//	    log.Printf("user %d is invalid: %v", i, result.Errors[i])
+//	}
+//
+// Each item is validated like with ValidateCtx, and the paths of its errors are prefixed with its index,
+// e.g. "[3].address.city". Messages and codes are resolved from the paths within the item, so messages
+// set for "address.city" also apply to the items.
+//
+// With MaxErrors, no more items are validated once the errors found reach it, and the result only keeps
+// the first MaxErrors errors in the order of the items. When items are validated at once, the items being
+// validated when the limit is reached are still validated, so the result is not always made of the
+// first items.
+//
+// Items that cannot be validated, such as nil pointers, items that are not structs or items whose deferred
+// validation returns an error, are recorded in Failures by index, and the other items are still validated.
+// They do not count towards MaxErrors.
+//
+// When ctx is done, the items not validated yet are skipped, and ValidateBatch returns the result so far
+// with the error of ctx.
+func (v *Validator) ValidateBatch(ctx context.Context, items interface{}, opts ...BatchOptions) (*BatchResult, error) {
+	var options BatchOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	each, err := batchItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu         sync.Mutex
+		result     = &BatchResult{Errors: make(map[int]ValidationErrors), Failures: make(map[int]error)}
+		errorCount int
+		panicValue interface{}
+	)
+
+	// stopped reports whether no more items should be validated
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return panicValue != nil || (options.MaxErrors > 0 && errorCount >= options.MaxErrors)
+	}
+
+	validate := func(i int, item interface{}) {
+		err := v.ValidateCtx(ctx, item)
+
+		mu.Lock()
+		defer mu.Unlock()
+		result.Validated++
+
+		switch e := err.(type) {
+		case nil:
+		case ValidationErrors:
+			result.Errors[i] = prefixErrorPaths(e, i)
+			errorCount += len(e)
+		default:
+			result.Failures[i] = fmt.Errorf("validator: item %d: %w", i, err)
+		}
+	}
+
+	// next reports whether the next item should be validated
+	skipped := false
+	next := func() bool {
+		skipped = ctx.Err() != nil || stopped()
+		return !skipped
+	}
+
+	if options.Concurrency <= 1 {
+		each(func(i int, item interface{}) bool {
+			if !next() {
+				return false
+			}
+			validate(i, item)
+			return true
+		})
+	} else {
+		type batchItem struct {
+			index int
+			item  interface{}
+		}
+
+		queue := make(chan batchItem)
+		var wg sync.WaitGroup
+		for w := 0; w < options.Concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for it := range queue {
+					func() {
+						// Panics are raised again once every worker is done
+						defer func() {
+							if r := recover(); r != nil {
+								mu.Lock()
+								if panicValue == nil {
+									panicValue = r
+								}
+								mu.Unlock()
+							}
+						}()
+						validate(it.index, it.item)
+					}()
+				}
+			}()
+		}
+
+		each(func(i int, item interface{}) bool {
+			if !next() {
+				return false
+			}
+			select {
+			case queue <- batchItem{index: i, item: item}:
+				return true
+			case <-ctx.Done():
+				skipped = true
+				return false
+			}
+		})
+		close(queue)
+		wg.Wait()
+
+		if panicValue != nil {
+			panic(panicValue)
+		}
+	}
+
+	result.Stopped = skipped
+	if options.MaxErrors > 0 && errorCount > options.MaxErrors {
+		result.limitErrors(options.MaxErrors)
+	}
+	if err := ctx.Err(); err != nil && skipped {
+		return result, err
+	}
+	return result, nil
+}
+
+// limitErrors keeps the first limit errors of r, in the order of the items
+func (r *BatchResult) limitErrors(limit int) {
+	count := 0
+	for _, i := range r.Indexes() {
+		errs := r.Errors[i]
+		switch {
+		case count >= limit:
+			delete(r.Errors, i)
+		case count+len(errs) > limit:
+			r.Errors[i] = errs[:limit-count]
+		}
+		count += len(errs)
+	}
+}
+
+// prefixErrorPaths returns errs with their paths prefixed by the index of their item
+func prefixErrorPaths(errs ValidationErrors, index int) ValidationErrors {
+	prefixed := make(ValidationErrors, len(errs))
+	for j, e := range errs {
+		e.Path = fmt.Sprintf("[%d].%s", index, e.Path)
+		prefixed[j] = e
+	}
+	return prefixed
+}
+
+// batchItems returns a function calling yield with each item of a slice, an array or an iter.Seq, until
+// yield returns false
+func batchItems(items interface{}) (func(yield func(int, interface{}) bool), error) {
+	rv := reflect.ValueOf(items)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return func(yield func(int, interface{}) bool) {
+			for i := 0; i < rv.Len(); i++ {
+				if !yield(i, rv.Index(i).Interface()) {
+					return
+				}
+			}
+		}, nil
+
+	case reflect.Func:
+		// iter.Seq[T] is a func(yield func(T) bool)
+		t := rv.Type()
+		if !rv.IsNil() && t.NumIn() == 1 && t.NumOut() == 0 {
+			yieldType := t.In(0)
+			if yieldType.Kind() == reflect.Func && yieldType.NumIn() == 1 && yieldType.NumOut() == 1 && yieldType.Out(0).Kind() == reflect.Bool {
+				return func(yield func(int, interface{}) bool) {
+					i := 0
+					fn := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+						ok := yield(i, args[0].Interface())
+						i++
+						return []reflect.Value{reflect.ValueOf(ok).Convert(yieldType.Out(0))}
+					})
+					rv.Call([]reflect.Value{fn})
+				}, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("validator: ValidateBatch needs a slice, an array or an iter.Seq of structs, got %T", items)
+}
@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBatch(t *testing.T) {
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type Record struct {
+		Name    string  `json:"name" validate:"required"`
+		Email   string  `json:"email" validate:"required,email"`
+		Address Address `json:"address"`
+	}
+
+	records := []Record{
+		{Name: "Jane", Email: "jane@example.com", Address: Address{City: "Toronto"}},
+		{Name: "", Email: "john@example.com", Address: Address{City: "Ottawa"}},
+		{Name: "Ana", Email: "ana@example.com", Address: Address{City: "Lima"}},
+		{Name: "", Email: "not-an-email", Address: Address{}},
+	}
+
+	newValidator := func() *Validator {
+		return New().UseJsonTagName()
+	}
+
+	t.Run("Slices", func(t *testing.T) {
+		v := newValidator()
+		v.SetConstraintMessage("address.city", "required", "City is required")
+
+		result, err := v.ValidateBatch(context.Background(), records)
+		assert.NoError(t, err)
+		assert.False(t, result.Valid())
+		assert.False(t, result.Stopped)
+		assert.Equal(t, 4, result.Validated)
+		assert.Equal(t, []int{1, 3}, result.Indexes())
+
+		assert.Len(t, result.Errors[1], 1)
+		assert.Equal(t, "[1].name", result.Errors[1][0].Path)
+		assert.Equal(t, "name", result.Errors[1][0].Field)
+
+		assert.Len(t, result.Errors[3], 3)
+		assert.Equal(t, "[3].name", result.Errors[3][0].Path)
+		assert.Equal(t, "[3].email", result.Errors[3][1].Path)
+		assert.Equal(t, "[3].address.city", result.Errors[3][2].Path)
+		assert.Equal(t, "City is required", result.Errors[3][2].Message)
+
+		all := result.AllErrors()
+		assert.Len(t, all, 4)
+		assert.Equal(t, "[1].name", all[0].Path)
+		assert.Equal(t, "[3].address.city", all[3].Path)
+	})
+
+	t.Run("Valid items", func(t *testing.T) {
+		result, err := newValidator().ValidateBatch(context.Background(), []*Record{&records[0], &records[2]})
+		assert.NoError(t, err)
+		assert.True(t, result.Valid())
+		assert.Nil(t, result.AllErrors())
+		assert.Equal(t, 2, result.Validated)
+	})
+
+	t.Run("Iterators", func(t *testing.T) {
+		var seq iter.Seq[Record] = func(yield func(Record) bool) {
+			for _, record := range records {
+				if !yield(record) {
+					return
+				}
+			}
+		}
+
+		result, err := newValidator().ValidateBatch(context.Background(), seq)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, result.Indexes())
+		assert.Equal(t, "[3].email", result.Errors[3][1].Path)
+
+		// The iterator stops with the validation
+		read := 0
+		var counted iter.Seq[Record] = func(yield func(Record) bool) {
+			for _, record := range records {
+				read++
+				if !yield(record) {
+					return
+				}
+			}
+		}
+		result, err = newValidator().ValidateBatch(context.Background(), counted, BatchOptions{MaxErrors: 1})
+		assert.NoError(t, err)
+		assert.True(t, result.Stopped)
+		assert.Equal(t, 3, read)
+	})
+
+	t.Run("Parallel", func(t *testing.T) {
+		many := make([]Record, 500)
+		for i := range many {
+			many[i] = Record{Name: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i), Address: Address{City: "Toronto"}}
+			if i%100 == 7 {
+				many[i].Email = "invalid"
+			}
+		}
+
+		result, err := newValidator().ValidateBatch(context.Background(), many, BatchOptions{Concurrency: 8})
+		assert.NoError(t, err)
+		assert.Equal(t, 500, result.Validated)
+		assert.Equal(t, []int{7, 107, 207, 307, 407}, result.Indexes())
+		assert.Equal(t, "[207].email", result.Errors[207][0].Path)
+	})
+
+	t.Run("Maximum errors", func(t *testing.T) {
+		// The errors of the last item are limited, but every item was validated
+		result, err := newValidator().ValidateBatch(context.Background(), records, BatchOptions{MaxErrors: 2})
+		assert.NoError(t, err)
+		assert.False(t, result.Stopped)
+		assert.Equal(t, 4, result.Validated)
+		assert.Len(t, result.AllErrors(), 2)
+		assert.Equal(t, "[1].name", result.AllErrors()[0].Path)
+		assert.Equal(t, "[3].name", result.AllErrors()[1].Path)
+
+		result, err = newValidator().ValidateBatch(context.Background(), records, BatchOptions{MaxErrors: 1})
+		assert.NoError(t, err)
+		assert.True(t, result.Stopped)
+		assert.Equal(t, 2, result.Validated)
+		assert.Equal(t, []int{1}, result.Indexes())
+
+		// The limit is kept when items are validated at once
+		result, err = newValidator().ValidateBatch(context.Background(), records, BatchOptions{MaxErrors: 2, Concurrency: 4})
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(result.AllErrors()), 2)
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		v := newValidator()
+		var validated atomic.Int32
+		assert.NoError(t, v.RegisterAsyncValidation("slow", func(ctx context.Context, param string, value interface{}) []Violation {
+			validated.Add(1)
+			select {
+			case <-time.After(5 * time.Millisecond):
+			case <-ctx.Done():
+			}
+			return nil
+		}))
+
+		type Slow struct {
+			Name string `validate:"slow"`
+		}
+		items := make([]Slow, 1000)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		result, err := v.ValidateBatch(ctx, items, BatchOptions{Concurrency: 4})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, result.Stopped)
+		assert.Less(t, result.Validated, 1000)
+		assert.Less(t, int(validated.Load()), 1000)
+	})
+
+	t.Run("Invalid items", func(t *testing.T) {
+		v := newValidator()
+
+		_, err := v.ValidateBatch(context.Background(), Record{})
+		assert.EqualError(t, err, "validator: ValidateBatch needs a slice, an array or an iter.Seq of structs, got validator.Record")
+
+		result, err := v.ValidateBatch(context.Background(), []interface{}{records[0], "not a struct"})
+		assert.NoError(t, err)
+		assert.False(t, result.Valid())
+		assert.ErrorContains(t, result.Failures[1], "validator: item 1:")
+	})
+
+	t.Run("Nil items", func(t *testing.T) {
+		for _, concurrency := range []int{1, 4} {
+			result, err := newValidator().ValidateBatch(context.Background(), []*Record{&records[0], nil, &records[1], &records[2]}, BatchOptions{Concurrency: concurrency})
+			assert.NoError(t, err)
+			assert.Equal(t, 4, result.Validated)
+			assert.False(t, result.Valid())
+
+			// The nil item is recorded and the items after it are still validated
+			assert.Len(t, result.Failures, 1)
+			assert.ErrorContains(t, result.Failures[1], "validator: item 1:")
+			assert.Equal(t, []int{2}, result.Indexes())
+			assert.Equal(t, "[2].name", result.Errors[2][0].Path)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package validator
+
+import "reflect"
+
+// ErrorCodes maps normalized paths to constraint-specific error codes
+type ErrorCodes map[string]map[string]string
+
+// NewErrorCodes creates a new error code registry
+func NewErrorCodes() ErrorCodes {
+	return make(ErrorCodes)
+}
+
+// SetCode sets the error code for a path and constraint
+func (ec ErrorCodes) SetCode(path, constraint, code string) {
+	codes, exists := ec[path]
+	if !exists {
+		codes = make(map[string]string)
+		ec[path] = codes
+	}
+	codes[constraint] = code
+}
+
+// ResolveCode gets the error code for a path and constraint.
+// This method will return an empty string if no code was set for path and constraint.
+func (ec ErrorCodes) ResolveCode(path, constraint string) string {
+	return ec[path][constraint]
+}
+
+// SetDefaultTagCode sets the error code used for all failures of a specific tag.
+// Codes give API consumers identifiers that stay the same when messages are reworded
+// or tags are renamed.
+// Example: v.SetDefaultTagCode("required", "ERR_REQUIRED")
+func (v *Validator) SetDefaultTagCode(tag, code string) *Validator {
+	v.DefaultTagCodes[tag] = code
+	return v
+}
+
+// SetConstraintCode sets the error code for a field path and constraint combination.
+// Example: v.SetConstraintCode("user.email", "email", "user.email.invalid_format")
+func (v *Validator) SetConstraintCode(path, constraint, code string) *Validator {
+	v.Codes.SetCode(normalizePath(path), constraint, code)
+	return v
+}
+
+// resolveCode finds the error code for a failure. The resolution order is:
+//
+//  1. Field-specific struct tag error code (`errcode-{constraint}` or `errcode`)
+//  2. Path-specific code set with SetConstraintCode(path, constraint, code)
+//  3. Constraint-specific default code set with SetDefaultTagCode(constraint, code)
+//
+// For errors with a reason, "{constraint}.{reason}" is tried before "{constraint}" at every level.
+// An empty string is returned if no code was configured.
+func (v *Validator) resolveCode(e ValidationError, field reflect.StructField, found bool, normPath string) string {
+	keys := constraintKeys(e)
+
+	if found {
+		if code := getRawTagCode(field, keys...); code != "" {
+			return code
+		}
+	}
+
+	for _, key := range keys {
+		if code := v.Codes.ResolveCode(normPath, key); code != "" {
+			return code
+		}
+	}
+
+	for _, key := range keys {
+		if code := v.DefaultTagCodes[key]; code != "" {
+			return code
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	codes := NewErrorCodes()
+
+	assert.Empty(t, codes.ResolveCode("user.email", "email"))
+
+	codes.SetCode("user.email", "email", "user.email.invalid_format")
+	assert.Equal(t, "user.email.invalid_format", codes.ResolveCode("user.email", "email"))
+	assert.Empty(t, codes.ResolveCode("user.email", "required"))
+}
+
+func TestResolveCode(t *testing.T) {
+	type Profile struct {
+		Email    string `json:"email" validate:"required,email" errcode-email:"ERR_EMAIL_FORMAT"`
+		Name     string `json:"name" validate:"required" errcode:"ERR_NAME"`
+		Nickname string `json:"nickname" validate:"required"`
+		Bio      string `json:"bio" validate:"required"`
+	}
+	type User struct {
+		Profile Profile `json:"profile"`
+	}
+
+	v := New().UseJsonTagName()
+	v.SetDefaultTagCode("required", "ERR_REQUIRED")
+	v.SetConstraintCode("profile.nickname", "required", "user.nickname.missing")
+
+	err := v.Validate(User{Profile: Profile{Email: "nope"}})
+	errs := err.(ValidationErrors)
+	assert.Len(t, errs, 4)
+
+	assert.Equal(t, "ERR_EMAIL_FORMAT", errs.ErrorsForPath("profile.email")[0].Code)
+	assert.Equal(t, "ERR_NAME", errs.ErrorsForPath("profile.name")[0].Code)
+	assert.Equal(t, "user.nickname.missing", errs.ErrorsForPath("profile.nickname")[0].Code)
+	assert.Equal(t, "ERR_REQUIRED", errs.ErrorsForPath("profile.bio")[0].Code)
+
+	t.Run("Codes do not depend on messages", func(t *testing.T) {
+		v.SetDefaultTagMessage("required", "Please fill in {field}")
+		errs := v.Validate(User{Profile: Profile{Email: "a@b.co", Name: "n", Nickname: "n"}}).(ValidationErrors)
+		assert.Equal(t, "ERR_REQUIRED", errs[0].Code)
+	})
+
+	t.Run("Code is omitted from JSON when unset", func(t *testing.T) {
+		b, err := json.Marshal(ValidationError{Field: "name", Path: "name", Message: "m"})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(b), "code")
+	})
+
+	t.Run("UseMessages keeps codes", func(t *testing.T) {
+		newV := v.UseMessages(NewValidationMessages())
+		assert.Equal(t, "ERR_REQUIRED", newV.DefaultTagCodes["required"])
+		assert.Equal(t, "user.nickname.missing", newV.Codes.ResolveCode("profile.nickname", "required"))
+	})
+}
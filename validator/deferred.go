@@ -0,0 +1,267 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	govalidator "github.com/go-playground/validator/v10"
+)
+
+// DeferredFunc validates the values of every field using a deferred validation with the same tag and param,
+// in a single call, e.g. one database query for all the product IDs of an order. It returns the violations
+// of each value, in the order of values. A value without violations is valid.
+//
+// An error means the values could not be checked, e.g. the database is not available. ValidateCtx returns it
+// instead of ValidationErrors.
+type DeferredFunc func(ctx context.Context, param string, values []interface{}) ([][]Violation, error)
+
+// RegisterDeferredValidation registers a validation with the given tag that runs after every other
+// validation of the struct, with the values of all the fields using it in one call. It is meant for
+// expensive checks such as database lookups, which can be batched:
+//
+//	v.RegisterDeferredValidation("sku_exists", func(ctx context.Context, param string, values []interface{}) ([][]validator.Violation, error) {
+//	    found, err := catalog.FindSKUs(ctx, values)
+//	    ...
+//	})
+//
+//	type Order struct {
+//	    SKUs []string `validate:"required,dive,max=32,sku_exists"`
+//	}
+//
+// go-playground stops at the first failing tag of a field, so a deferred validation only runs for the
+// values that passed the tags before it, and must be the last tag of a field, or of the keys of a map.
+// The tags after it would not be checked, so ValidateCtx returns an error instead of ValidationErrors
+// for fields with tags after a deferred validation, e.g. `validate:"sku_exists,max=32"`.
+//
+// In an OR group, e.g. `validate:"sku_exists|eq=NONE"`, fn only runs when every other tag of the group fails,
+// and the field is valid if fn finds no violation.
+//
+// Each Violation returned by fn becomes its own ValidationError, like with RegisterViolationValidation.
+// The context passed to fn is the one given to ValidateCtx. Calls run concurrently with the other deferred
+// and async validations, see RegisterAsyncValidation, and fields not validated in time are reported with
+// the TimeoutConstraint constraint. When the underlying go-playground validator is
+// used directly, fn is called for each field, and the field is invalid if fn fails.
+func (v *Validator) RegisterDeferredValidation(tag string, fn DeferredFunc, callValidationEvenIfNull ...bool) error {
+	return v.BaseValidator.RegisterValidationCtx(tag, func(ctx context.Context, fl govalidator.FieldLevel) bool {
+		return DeferValidation(ctx, fl, fn)
+	}, callValidationEvenIfNull...)
+}
+
+// DeferValidation defers the validation of the field of fl to fn, see RegisterDeferredValidation. It is meant
+// for validations registered on the BaseValidator that only defer some fields:
+//
+//	v.BaseValidator.RegisterValidationCtx("exists", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+//	    if fl.Field().Kind() == reflect.Slice {
+//	        return checkNow(fl)
+//	    }
+//	    return validator.DeferValidation(ctx, fl, lookup)
+//	})
+func DeferValidation(ctx context.Context, fl govalidator.FieldLevel, fn DeferredFunc) bool {
+	record := newViolationRecord(fl.GetTag(), fl)
+
+	if collector := violationCollectorFromContext(ctx); collector != nil {
+		if !isLastTag(fl) {
+			return AbortValidation(ctx, fmt.Errorf("validator: deferred validation '%s' on field '%s' must be the last tag, the tags after it would not be checked", fl.GetTag(), fl.StructFieldName()))
+		}
+
+		// The field error reported by go-playground is replaced with the result of fn, or dropped
+		record.deferred = fn
+		collector.add(record)
+		return false
+	}
+
+	results, err := fn(ctx, fl.Param(), []interface{}{record.value})
+	return err == nil && len(results) == 1 && len(results[0]) == 0
+}
+
+// isLastTag reports whether the tag of fl is the last one of the `validate` struct tag of its field, or of
+// the keys of a map, i.e. followed by "endkeys". The tag can be part of an OR group. Fields whose struct
+// tag cannot be found, e.g. tags of aliases, are assumed to be valid.
+func isLastTag(fl govalidator.FieldLevel) bool {
+	parent := fl.Parent()
+	for parent.Kind() == reflect.Ptr {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return true
+	}
+
+	// Elements of slices and maps are named after their field, e.g. "SKUs[0]"
+	name, _, _ := strings.Cut(fl.StructFieldName(), "[")
+	field, ok := parent.Type().FieldByName(name)
+	if !ok {
+		return true
+	}
+
+	rules := strings.Split(field.Tag.Get("validate"), ",")
+	inKeys := false
+	for i, rule := range rules {
+		switch rule {
+		case "keys":
+			inKeys = true
+		case "endkeys":
+			inKeys = false
+		}
+
+		for _, tag := range strings.Split(rule, "|") {
+			if name, _, _ := strings.Cut(tag, "="); name != fl.GetTag() {
+				continue
+			}
+			if i == len(rules)-1 || (inKeys && rules[i+1] == "endkeys") {
+				continue
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// deferredError is a field error of deferred or async validations, waiting for the results of their functions.
+// It has several records when the tags of an OR group all failed.
+type deferredError struct {
+	index       int // Position of the placeholder in the errors of ValidateCtx
+	records     []violationRecord
+	err         ValidationError
+	structField reflect.StructField
+	found       bool
+	normPath    string
+}
+
+// deferredField is a deferred or async record of a deferredError
+type deferredField struct {
+	error  int // Position of the deferredError in the deferred errors of ValidateCtx
+	record int // Position of the record in the records of the deferredError
+	value  interface{}
+}
+
+// deferredOutcome is the result of a record of a deferredError
+type deferredOutcome struct {
+	violations []Violation
+	timeout    bool
+}
+
+// deferredBatchKey identifies the fields validated in a single call of a DeferredFunc
+type deferredBatchKey struct {
+	tag   string
+	param string
+}
+
+// runDeferred runs the deferred validations of errs in batches and the async validations of errs one field
+// at a time, concurrently, and replaces their placeholders with the violations found. Placeholders of valid
+// fields are removed, and those of fields not validated in time become TimeoutConstraint errors.
+func (v *Validator) runDeferred(ctx context.Context, errs ValidationErrors, deferred []deferredError) (ValidationErrors, error) {
+	outcomes := make([][]deferredOutcome, len(deferred))
+
+	var jobs []asyncJob
+	batches := make(map[deferredBatchKey]int)
+	for i, d := range deferred {
+		outcomes[i] = make([]deferredOutcome, len(d.records))
+		for j, record := range d.records {
+			if !record.pending() {
+				// Violations of the other tags of an OR group
+				outcomes[i][j].violations = record.violations
+				continue
+			}
+
+			field := deferredField{error: i, record: j, value: record.value}
+			if record.async != nil {
+				async, param := record.async, record.param
+				jobs = append(jobs, asyncJob{
+					tag:    record.tag,
+					fields: []deferredField{field},
+					run: func(ctx context.Context) ([][]Violation, error) {
+						return [][]Violation{async(ctx, param, field.value)}, nil
+					},
+				})
+				continue
+			}
+
+			key := deferredBatchKey{tag: record.tag, param: record.param}
+			if k, ok := batches[key]; ok {
+				jobs[k].fields = append(jobs[k].fields, field)
+				continue
+			}
+			batches[key] = len(jobs)
+			jobs = append(jobs, asyncJob{tag: record.tag, fields: []deferredField{field}, deferred: record})
+		}
+	}
+
+	// Batches get their values once all their fields are known
+	for i := range jobs {
+		job := &jobs[i]
+		if job.run != nil {
+			continue
+		}
+		fields, fn, param := job.fields, job.deferred.deferred, job.deferred.param
+		job.run = func(ctx context.Context) ([][]Violation, error) {
+			values := make([]interface{}, len(fields))
+			for i, field := range fields {
+				values[i] = field.value
+			}
+			return fn(ctx, param, values)
+		}
+	}
+
+	for i, result := range v.runJobs(ctx, jobs) {
+		job := jobs[i]
+		if result == nil {
+			for _, field := range job.fields {
+				outcomes[field.error][field.record].timeout = true
+			}
+			continue
+		}
+		if result.err != nil {
+			return nil, fmt.Errorf("validator: deferred validation '%s': %w", job.tag, result.err)
+		}
+		if len(result.violations) != len(job.fields) {
+			return nil, fmt.Errorf("validator: deferred validation '%s' returned %d results for %d values", job.tag, len(result.violations), len(job.fields))
+		}
+
+		for j, field := range job.fields {
+			outcomes[field.error][field.record].violations = result.violations[j]
+		}
+	}
+
+	replacements := make(map[int]ValidationErrors, len(deferred))
+	for i, d := range deferred {
+		replacements[d.index] = v.completeDeferred(ctx, d, outcomes[i])
+	}
+
+	result := ValidationErrors{}
+	for i, e := range errs {
+		if completed, ok := replacements[i]; ok {
+			result = append(result, completed...)
+			continue
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// completeDeferred returns the errors of a deferredError from the outcomes of its records. A field whose
+// records come from an OR group is valid if any of them is, and only reports its timeouts if some of
+// them did not finish in time.
+func (v *Validator) completeDeferred(ctx context.Context, d deferredError, outcomes []deferredOutcome) ValidationErrors {
+	timedOut := false
+	for _, outcome := range outcomes {
+		if outcome.timeout {
+			timedOut = true
+		} else if len(outcome.violations) == 0 {
+			return ValidationErrors{}
+		}
+	}
+
+	completed := ValidationErrors{}
+	for j, outcome := range outcomes {
+		record := d.records[j]
+		switch {
+		case outcome.timeout:
+			completed = append(completed, v.timeoutError(ctx, record.tag, d))
+		case !timedOut:
+			completed = append(completed, v.violationErrors(ctx, d.err, record, outcome.violations, d.structField, d.found, d.normPath)...)
+		}
+	}
+	return completed
+}
@@ -0,0 +1,206 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// skuCatalog is a DeferredFunc used in tests that records each call
+type skuCatalog struct {
+	skus  map[string]bool
+	mu    sync.Mutex
+	calls [][]interface{}
+}
+
+func (c *skuCatalog) check(ctx context.Context, param string, values []interface{}) ([][]Violation, error) {
+	c.mu.Lock()
+	c.calls = append(c.calls, values)
+	c.mu.Unlock()
+
+	results := make([][]Violation, len(values))
+	for i, value := range values {
+		if !c.skus[value.(string)] {
+			results[i] = []Violation{{
+				Reason:  "not_found",
+				Message: "{field} must be an existing SKU",
+				Params:  map[string]interface{}{"catalog": param},
+			}}
+		}
+	}
+	return results, nil
+}
+
+func TestRegisterDeferredValidation(t *testing.T) {
+	type Line struct {
+		SKU string `json:"sku" validate:"required,max=8,sku_exists=main"`
+	}
+	type Order struct {
+		Customer string   `json:"customer" validate:"required"`
+		Lines    []Line   `json:"lines" validate:"dive"`
+		Extras   []string `json:"extras" validate:"dive,sku_exists=main"`
+		Gift     string   `json:"gift" validate:"omitempty,sku_exists=gifts"`
+	}
+
+	newValidator := func(catalog *skuCatalog) *Validator {
+		v := New().UseJsonTagName()
+		assert.NoError(t, v.RegisterDeferredValidation("sku_exists", catalog.check))
+		return v
+	}
+
+	t.Run("Valid values", func(t *testing.T) {
+		catalog := &skuCatalog{skus: map[string]bool{"A1": true, "B2": true}}
+		v := newValidator(catalog)
+
+		err := v.Validate(Order{Customer: "jane", Lines: []Line{{SKU: "A1"}, {SKU: "B2"}}, Extras: []string{"A1"}})
+		assert.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{"A1", "B2", "A1"}}, catalog.calls)
+	})
+
+	t.Run("Batched by param with paths", func(t *testing.T) {
+		catalog := &skuCatalog{skus: map[string]bool{"A1": true}}
+		v := newValidator(catalog)
+
+		errs := v.Validate(Order{
+			Lines:  []Line{{SKU: "A1"}, {SKU: "ZZ"}, {SKU: "TOO-LONG-SKU"}},
+			Extras: []string{"YY"},
+			Gift:   "G1",
+		}).(ValidationErrors)
+
+		// One call per param, only with the values that passed the tags before
+		assert.ElementsMatch(t, [][]interface{}{{"A1", "ZZ", "YY"}, {"G1"}}, catalog.calls)
+
+		assert.Len(t, errs, 5)
+		assert.Equal(t, "customer", errs[0].Path)
+		assert.Equal(t, "required", errs[0].Constraint)
+
+		assert.Equal(t, "lines[1].sku", errs[1].Path)
+		assert.Equal(t, "sku_exists", errs[1].Constraint)
+		assert.Equal(t, "main", errs[1].Param)
+		assert.Equal(t, "not_found", errs[1].Reason)
+		assert.Equal(t, "ZZ", errs[1].Actual)
+		assert.Equal(t, "sku must be an existing SKU", errs[1].Message)
+
+		assert.Equal(t, "lines[2].sku", errs[2].Path)
+		assert.Equal(t, "max", errs[2].Constraint)
+
+		assert.Equal(t, "extras[0]", errs[3].Path)
+		assert.Equal(t, "not_found", errs[3].Reason)
+
+		assert.Equal(t, "gift", errs[4].Path)
+		assert.Equal(t, "not_found", errs[4].Reason)
+		assert.Equal(t, "gifts", errs[4].Params["catalog"])
+	})
+
+	t.Run("Messages and codes", func(t *testing.T) {
+		v := newValidator(&skuCatalog{})
+		v.SetDefaultTagMessage("sku_exists.not_found", "Unknown SKU {value} in {catalog}")
+		v.SetDefaultTagCode("sku_exists", "ERR_SKU")
+
+		errs := v.Validate(Order{Customer: "jane", Gift: "G1"}).(ValidationErrors)
+		assert.Equal(t, "Unknown SKU G1 in gifts", errs[0].Message)
+		assert.Equal(t, "ERR_SKU", errs[0].Code)
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		type ctxKey struct{}
+
+		v := New()
+		assert.NoError(t, v.RegisterDeferredValidation("tenant_sku", func(ctx context.Context, param string, values []interface{}) ([][]Violation, error) {
+			assert.Equal(t, "acme", ctx.Value(ctxKey{}))
+			return make([][]Violation, len(values)), nil
+		}))
+
+		type Item struct {
+			SKU string `validate:"tenant_sku"`
+		}
+		ctx := context.WithValue(context.Background(), ctxKey{}, "acme")
+		assert.NoError(t, v.ValidateCtx(ctx, Item{SKU: "A1"}))
+	})
+
+	t.Run("Lookup errors", func(t *testing.T) {
+		lookupErr := errors.New("connection refused")
+
+		v := New()
+		assert.NoError(t, v.RegisterDeferredValidation("sku_exists", func(ctx context.Context, param string, values []interface{}) ([][]Violation, error) {
+			return nil, lookupErr
+		}))
+
+		err := v.Validate(Order{Customer: "jane", Gift: "G1"})
+		assert.ErrorIs(t, err, lookupErr)
+		_, ok := err.(ValidationErrors)
+		assert.False(t, ok)
+
+		v = New()
+		assert.NoError(t, v.RegisterDeferredValidation("sku_exists", func(ctx context.Context, param string, values []interface{}) ([][]Violation, error) {
+			return nil, nil
+		}))
+		assert.EqualError(t, v.Validate(Order{Customer: "jane", Gift: "G1"}), "validator: deferred validation 'sku_exists' returned 0 results for 1 values")
+	})
+
+	t.Run("OR groups", func(t *testing.T) {
+		type SwapLine struct {
+			SKU string `json:"sku" validate:"sku_exists=main|eq=NONE"`
+		}
+		type Swap struct {
+			Lines  []SwapLine `json:"lines" validate:"dive"`
+			Coupon string     `json:"coupon" validate:"sku_exists=main|sku_exists=gifts"`
+		}
+
+		catalog := &skuCatalog{skus: map[string]bool{"A1": true, "G1": true}}
+		v := newValidator(catalog)
+
+		swap := Swap{Lines: []SwapLine{{SKU: "A1"}, {SKU: "NONE"}}, Coupon: "G1"}
+
+		// A value found by the lookup is valid even though the other tags of its group fail
+		assert.NoError(t, v.Validate(swap))
+
+		swap.Lines[0].SKU = "ZZ"
+		swap.Coupon = "YY"
+		errs := v.Validate(swap).(ValidationErrors)
+		assert.Len(t, errs, 3)
+		assert.Equal(t, "lines[0].sku", errs[0].Path)
+		assert.Equal(t, "sku_exists", errs[0].Constraint)
+		assert.Equal(t, "main", errs[0].Param)
+		assert.Equal(t, "not_found", errs[0].Reason)
+
+		// Both tags of the group report their violations
+		assert.Equal(t, "coupon", errs[1].Path)
+		assert.Equal(t, "main", errs[1].Param)
+		assert.Equal(t, "coupon", errs[2].Path)
+		assert.Equal(t, "gifts", errs[2].Param)
+	})
+
+	t.Run("Tags after a deferred validation", func(t *testing.T) {
+		type Item struct {
+			SKU string `json:"sku" validate:"sku_exists=main,max=2"`
+		}
+
+		catalog := &skuCatalog{skus: map[string]bool{"toolong": true}}
+		v := newValidator(catalog)
+
+		// max=2 would never run, so the struct tag is rejected instead of passing "toolong"
+		err := v.Validate(Item{SKU: "toolong"})
+		assert.EqualError(t, err, "validator: deferred validation 'sku_exists' on field 'SKU' must be the last tag, the tags after it would not be checked")
+		assert.Empty(t, catalog.calls)
+
+		// The keys of a map end at endkeys
+		type Stock struct {
+			Quantities map[string]int `json:"quantities" validate:"dive,keys,sku_exists=main,endkeys,min=1"`
+		}
+		errs := v.Validate(Stock{Quantities: map[string]int{"toolong": 0, "ZZ": 1}}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.ElementsMatch(t, []string{"min", "sku_exists"}, []string{errs[0].Constraint, errs[1].Constraint})
+	})
+
+	t.Run("Base validator", func(t *testing.T) {
+		catalog := &skuCatalog{skus: map[string]bool{"A1": true}}
+		v := newValidator(catalog)
+
+		assert.NoError(t, v.BaseValidator.Struct(Order{Customer: "jane", Gift: "A1"}))
+		assert.Error(t, v.BaseValidator.Struct(Order{Customer: "jane", Gift: "ZZ"}))
+	})
+}
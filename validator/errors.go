@@ -8,12 +8,15 @@ import (
 // ValidationError represents a single validation error for a specific field.
 // It includes the field path, error message, and metadata about the validation rule.
 type ValidationError struct {
-	Field      string      `json:"field"`                // Field name of the leaf in path
-	Path       string      `json:"path"`                 // JSON path to the field with the error
-	Message    string      `json:"message"`              // Human-readable error message
-	Constraint string      `json:"constraint,omitempty"` // Validation tag that failed (e.g., "required", "min")
-	Param      string      `json:"param,omitempty"`      // Parameter for the validation tag (e.g., "5" for min=5)
-	Actual     interface{} `json:"actual,omitempty"`     // Actual value that failed validation
+	Field      string                 `json:"field"`                // Field name of the leaf in path
+	Path       string                 `json:"path"`                 // JSON path to the field with the error
+	Message    string                 `json:"message"`              // Human-readable error message
+	Constraint string                 `json:"constraint,omitempty"` // Validation tag that failed (e.g., "required", "min")
+	Param      string                 `json:"param,omitempty"`      // Parameter for the validation tag (e.g., "5" for min=5)
+	Actual     interface{}            `json:"actual,omitempty"`     // Actual value that failed validation
+	Code       string                 `json:"code,omitempty"`       // Stable machine-readable error code (e.g., "ERR_REQUIRED")
+	Reason     string                 `json:"reason,omitempty"`     // Specific reason within the constraint (e.g., "digit" for "password")
+	Params     map[string]interface{} `json:"params,omitempty"`     // Extra parameters describing the failure, available in messages
 }
 
 // Error implements the error interface to allow ValidationError to be used as an error.
@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EscapeMode controls how interpolated values are escaped before being inserted into
+// validation messages. Only the values substituted for placeholders are escaped; the literal
+// text written by the message author is left untouched.
+type EscapeMode int
+
+const (
+	// EscapePlain inserts values as they are, formatted with %v.
+	EscapePlain EscapeMode = iota
+	// EscapeHTML escapes values so they can be safely embedded in HTML text and attribute values.
+	EscapeHTML
+	// EscapeJSON escapes values so they can be safely embedded inside a JSON string literal.
+	EscapeJSON
+	// EscapeMarkdown backslash-escapes characters that have a meaning in Markdown.
+	EscapeMarkdown
+)
+
+// truncationSuffix is appended to interpolated values that were truncated.
+const truncationSuffix = "…"
+
+// String returns the name of the escape mode.
+func (m EscapeMode) String() string {
+	switch m {
+	case EscapePlain:
+		return "plain"
+	case EscapeHTML:
+		return "html"
+	case EscapeJSON:
+		return "json"
+	case EscapeMarkdown:
+		return "markdown"
+	default:
+		return fmt.Sprintf("EscapeMode(%d)", int(m))
+	}
+}
+
+type escapeModeKey struct{}
+
+// WithEscapeMode returns a copy of ctx that makes ValidateCtx render messages with the given
+// escape mode, overriding the mode configured on the Validator for that call only.
+//
+// Example:
+//
+//	// Render messages for an HTML page
+//	err := v.ValidateCtx(validator.WithEscapeMode(ctx, validator.EscapeHTML), form)
+func WithEscapeMode(ctx context.Context, mode EscapeMode) context.Context {
+	return context.WithValue(ctx, escapeModeKey{}, mode)
+}
+
+// escapeModeFromContext returns the escape mode stored in ctx by WithEscapeMode, if any.
+func escapeModeFromContext(ctx context.Context) (EscapeMode, bool) {
+	if ctx == nil {
+		return EscapePlain, false
+	}
+	mode, ok := ctx.Value(escapeModeKey{}).(EscapeMode)
+	return mode, ok
+}
+
+// formatValue converts an interpolated value into the text inserted into a message.
+// The value is sanitized unless mode is EscapePlain, truncated to maxLength runes (no limit if
+// maxLength <= 0) and finally escaped according to mode.
+func formatValue(value interface{}, mode EscapeMode, maxLength int) string {
+	s := fmt.Sprintf("%v", value)
+	if mode != EscapePlain {
+		s = sanitizeValue(s)
+	}
+	s = truncateValue(s, maxLength)
+	return escapeValue(s, mode)
+}
+
+// sanitizeValue replaces invalid UTF-8 sequences, control characters and bidirectional
+// formatting characters with the Unicode replacement character, so that binary data or
+// crafted input cannot break or visually reorder the surrounding message.
+func sanitizeValue(s string) string {
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || isBidiControl(r) {
+			return utf8.RuneError
+		}
+		return r
+	}, s)
+}
+
+// isBidiControl reports whether r is a Unicode bidirectional embedding, override or isolate character.
+func isBidiControl(r rune) bool {
+	return (r >= '\u202A' && r <= '\u202E') || (r >= '\u2066' && r <= '\u2069') || r == '\u200E' || r == '\u200F'
+}
+
+// truncateValue shortens s to at most maxLength runes, appending an ellipsis when it was cut.
+func truncateValue(s string, maxLength int) string {
+	if maxLength <= 0 || utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:maxLength]) + truncationSuffix
+}
+
+// escapeValue escapes s for the given mode.
+func escapeValue(s string, mode EscapeMode) string {
+	switch mode {
+	case EscapeHTML:
+		return html.EscapeString(s)
+	case EscapeJSON:
+		b, err := json.Marshal(s)
+		if err != nil {
+			return ""
+		}
+		// Strip the surrounding quotes, the value is embedded in an existing string literal
+		return string(b[1 : len(b)-1])
+	case EscapeMarkdown:
+		return markdownEscaper.Replace(s)
+	default:
+		return s
+	}
+}
+
+// markdownEscaper backslash-escapes all ASCII punctuation that has a meaning in CommonMark
+// and replaces angle brackets with entities so inline HTML cannot be injected.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`#`, `\#`,
+	`+`, `\+`,
+	`-`, `\-`,
+	`.`, `\.`,
+	`!`, `\!`,
+	`|`, `\|`,
+	`~`, `\~`,
+	`<`, `&lt;`,
+	`>`, `&gt;`,
+	`&`, `&amp;`,
+)
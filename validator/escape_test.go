@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		mode      EscapeMode
+		maxLength int
+		expected  string
+	}{
+		{
+			name:     "Plain leaves markup untouched",
+			value:    "<b>bold</b>",
+			mode:     EscapePlain,
+			expected: "<b>bold</b>",
+		},
+		{
+			name:     "HTML escapes markup",
+			value:    `<script>alert("x")</script>`,
+			mode:     EscapeHTML,
+			expected: "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;",
+		},
+		{
+			name:     "JSON escapes quotes and backslashes",
+			value:    `say "hi" \ bye`,
+			mode:     EscapeJSON,
+			expected: `say \"hi\" \\ bye`,
+		},
+		{
+			name:     "Markdown escapes formatting characters",
+			value:    "*bold* [link](http://x)",
+			mode:     EscapeMarkdown,
+			expected: `\*bold\* \[link\]\(http://x\)`,
+		},
+		{
+			name:     "Plain leaves control characters untouched",
+			value:    "a\x00b\nc\u202E",
+			mode:     EscapePlain,
+			expected: "a\x00b\nc\u202E",
+		},
+		{
+			name:     "Control characters are replaced",
+			value:    "a\x00b\nc",
+			mode:     EscapeHTML,
+			expected: "a\uFFFDb\uFFFDc",
+		},
+		{
+			name:     "Invalid UTF-8 is replaced",
+			value:    string([]byte{'a', 0xff, 'b'}),
+			mode:     EscapeJSON,
+			expected: "a\uFFFDb",
+		},
+		{
+			name:     "Bidi overrides are replaced",
+			value:    "abc\u202Edef",
+			mode:     EscapeMarkdown,
+			expected: "abc\uFFFDdef",
+		},
+		{
+			name:      "Long values are truncated by runes",
+			value:     "ééééé",
+			mode:      EscapePlain,
+			maxLength: 3,
+			expected:  "ééé…",
+		},
+		{
+			name:      "Truncation happens before escaping",
+			value:     "<<<<<",
+			mode:      EscapeHTML,
+			maxLength: 2,
+			expected:  "&lt;&lt;…",
+		},
+		{
+			name:     "Non string values",
+			value:    42,
+			mode:     EscapeHTML,
+			expected: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatValue(tt.value, tt.mode, tt.maxLength))
+		})
+	}
+}
+
+func TestEscapeMode(t *testing.T) {
+	type Comment struct {
+		Body string `json:"body" validate:"alpha" errmsg:"<em>{value}</em> is not allowed"`
+	}
+
+	comment := Comment{Body: "<script>"}
+
+	t.Run("Default mode does not escape", func(t *testing.T) {
+		v := New()
+		errs := v.Validate(comment).(ValidationErrors)
+		assert.Equal(t, "<em><script></em> is not allowed", errs[0].Message)
+		assert.Equal(t, "<script>", errs[0].Actual, "Actual value should stay raw")
+	})
+
+	t.Run("HTML mode escapes only the value", func(t *testing.T) {
+		v := New().SetEscapeMode(EscapeHTML)
+		errs := v.Validate(comment).(ValidationErrors)
+		assert.Equal(t, "<em>&lt;script&gt;</em> is not allowed", errs[0].Message)
+	})
+
+	t.Run("Context overrides validator mode", func(t *testing.T) {
+		v := New().SetEscapeMode(EscapeHTML)
+		err := v.ValidateCtx(WithEscapeMode(context.Background(), EscapePlain), comment)
+		errs := err.(ValidationErrors)
+		assert.Equal(t, "<em><script></em> is not allowed", errs[0].Message)
+	})
+
+	t.Run("Custom params are escaped", func(t *testing.T) {
+		v := New().SetEscapeMode(EscapeHTML)
+		v.AddCustomParam("appName", "A&B")
+		v.SetDefaultTagMessage("required", "{field} is required by {appName} & friends")
+
+		type Item struct {
+			Name string `validate:"required"`
+		}
+		errs := v.Validate(Item{}).(ValidationErrors)
+		assert.Equal(t, "Name is required by A&amp;B & friends", errs[0].Message)
+	})
+
+	t.Run("Interpolated values are not interpolated again", func(t *testing.T) {
+		v := New()
+		v.SetDefaultTagMessage("alpha", "{value} ({field})")
+
+		type Item struct {
+			Name string `validate:"alpha"`
+		}
+		errs := v.Validate(Item{Name: "{field}{0}"}).(ValidationErrors)
+		assert.Equal(t, "{field}{0} (Name)", errs[0].Message)
+	})
+
+	t.Run("Max value length", func(t *testing.T) {
+		v := New().SetMaxValueLength(5)
+		v.SetDefaultTagMessage("alpha", "{value}")
+
+		type Item struct {
+			Name string `validate:"alpha"`
+		}
+		errs := v.Validate(Item{Name: strings.Repeat("1", 100)}).(ValidationErrors)
+		assert.Equal(t, "11111…", errs[0].Message)
+	})
+
+	t.Run("Values are kept as is by default", func(t *testing.T) {
+		v := New()
+		v.SetDefaultTagMessage("alpha", "{value}")
+
+		type Item struct {
+			Name string `validate:"alpha"`
+		}
+		name := strings.Repeat("1", 300) + "\n\t"
+		errs := v.Validate(Item{Name: name}).(ValidationErrors)
+		assert.Equal(t, name, errs[0].Message)
+	})
+
+	t.Run("UseMessages keeps escaping settings", func(t *testing.T) {
+		v := New().SetEscapeMode(EscapeJSON).SetMaxValueLength(10)
+		newV := v.UseMessages(NewValidationMessages())
+		assert.Equal(t, EscapeJSON, newV.EscapeMode)
+		assert.Equal(t, 10, newV.MaxValueLength)
+	})
+}
@@ -1,16 +1,24 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
+// MessageFunc builds the message for a validation error. It can be used instead of a message
+// template when the message needs logic, e.g. listing the allowed values of a oneof constraint.
+// The returned message is used as is, without parameter interpolation.
+type MessageFunc func(ctx context.Context, e ValidationError) string
+
 // ValidationMessageConfig defines messages for a specific path and constraint
 type ValidationMessageConfig struct {
-	Default     string            // Default message for path
-	Constraints map[string]string // Constraint-specific messages
+	Default         string                 // Default message for path
+	Constraints     map[string]string      // Constraint-specific messages
+	DefaultFunc     MessageFunc            // Default message function for path
+	ConstraintFuncs map[string]MessageFunc // Constraint-specific message functions
 }
 
 // ValidationMessages maps normalized paths to their validation configurations
@@ -21,46 +29,123 @@ func NewValidationMessages() ValidationMessages {
 	return make(ValidationMessages)
 }
 
+// config returns the configuration for a path with all of its maps initialized
+func (vm ValidationMessages) config(path string) ValidationMessageConfig {
+	config := vm[path]
+	if config.Constraints == nil {
+		config.Constraints = make(map[string]string)
+	}
+	if config.ConstraintFuncs == nil {
+		config.ConstraintFuncs = make(map[string]MessageFunc)
+	}
+	return config
+}
+
 // SetMessage sets a constraint-specific message for a path
 func (vm ValidationMessages) SetMessage(path, constraint, message string) {
-	config, exists := vm[path]
-	if !exists {
-		config = ValidationMessageConfig{
-			Constraints: make(map[string]string),
-		}
-	}
+	config := vm.config(path)
 	config.Constraints[constraint] = message
+	delete(config.ConstraintFuncs, constraint)
 	vm[path] = config
 }
 
 // SetDefaultMessage sets the default message for a path
 func (vm ValidationMessages) SetDefaultMessage(path, message string) {
-	config, exists := vm[path]
-	if !exists {
-		config = ValidationMessageConfig{
-			Constraints: make(map[string]string),
-		}
-	}
+	config := vm.config(path)
 	config.Default = message
+	config.DefaultFunc = nil
+	vm[path] = config
+}
+
+// SetMessageFunc sets a constraint-specific message function for a path.
+// It replaces any message previously set with SetMessage for the same path and constraint.
+func (vm ValidationMessages) SetMessageFunc(path, constraint string, fn MessageFunc) {
+	config := vm.config(path)
+	config.ConstraintFuncs[constraint] = fn
+	delete(config.Constraints, constraint)
+	vm[path] = config
+}
+
+// SetDefaultMessageFunc sets the default message function for a path.
+// It replaces any message previously set with SetDefaultMessage for the same path.
+func (vm ValidationMessages) SetDefaultMessageFunc(path string, fn MessageFunc) {
+	config := vm.config(path)
+	config.DefaultFunc = fn
+	config.Default = ""
 	vm[path] = config
 }
 
 // ResolveMessage gets the appropriate message for a path and constraint.
 // This method will return an emtpy string if no message was set for path and constraint.
 func (vm ValidationMessages) ResolveMessage(path, constraint string, params []interface{}, customParams ...CustomParams) string {
+	e := ValidationError{Path: path, Constraint: constraint}
+	return vm.resolveMessage(context.Background(), e, path, []string{constraint}, params, formatRaw, customParams...)
+}
+
+// resolveMessage implements ResolveMessage using format to render interpolated values.
+// The constraints are tried in order before the path default message.
+// Message functions are called with ctx and e.
+func (vm ValidationMessages) resolveMessage(ctx context.Context, e ValidationError, path string, constraints []string, params []interface{}, format valueFormatter, customParams ...CustomParams) string {
 	if config, exists := vm[path]; exists {
-		if msg, ok := config.Constraints[constraint]; ok {
-			return interpolateParams(msg, params, customParams...)
+		for _, constraint := range constraints {
+			if fn, ok := config.ConstraintFuncs[constraint]; ok && fn != nil {
+				return fn(ctx, e)
+			}
+
+			if msg, ok := config.Constraints[constraint]; ok {
+				return interpolate(msg, params, format, customParams...)
+			}
+		}
+
+		if config.DefaultFunc != nil {
+			return config.DefaultFunc(ctx, e)
 		}
 
 		if config.Default != "" {
-			return interpolateParams(config.Default, params, customParams...)
+			return interpolate(config.Default, params, format, customParams...)
 		}
 	}
 
 	return ""
 }
 
+// valueFormatter converts a value substituted for a placeholder into message text.
+type valueFormatter func(value interface{}) string
+
+// formatRaw formats values with their default representation and no escaping.
+// Dynamic parameters are evaluated with a background context.
+func formatRaw(value interface{}) string {
+	value, dynamic := resolveParamValue(context.Background(), ValidationError{}, value)
+	if dynamic && value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// resolveParamValue evaluates dynamic parameter values (ParamFunc and ErrorParamFunc) and reports
+// whether value was dynamic. Other values are returned unchanged.
+func resolveParamValue(ctx context.Context, e ValidationError, value interface{}) (interface{}, bool) {
+	switch fn := value.(type) {
+	case ParamFunc:
+		return fn(ctx), true
+	case func(context.Context) interface{}:
+		return fn(ctx), true
+	case ErrorParamFunc:
+		return fn(ctx, e), true
+	case func(context.Context, ValidationError) interface{}:
+		return fn(ctx, e), true
+	default:
+		return value, false
+	}
+}
+
+// placeholderPattern matches escaped double braces ({{name}}) and placeholders ({name})
+// in a single pass, so text inserted for one placeholder is never interpreted again.
+var placeholderPattern = regexp.MustCompile(`{{([^{}]*?)}}|{([^{}]+)}`)
+
+// positionalPattern matches positional placeholder names without leading zeros: 0-9, 10, 11, ...
+var positionalPattern = regexp.MustCompile(`^(?:[0-9]|[1-9][0-9]+)$`)
+
 // interpolateParams replaces placeholders in a message with values from an array
 // Supports three types of placeholders:
 // 1. Positional placeholders: {0}, {1}, {2}, etc.
@@ -73,40 +158,17 @@ func (vm ValidationMessages) ResolveMessage(path, constraint string, params []in
 // - Parameter names starting with digits (e.g. {0name}) are treated as literals
 // - Parameter names can contain digits if they don't start with one (e.g. {name123})
 func interpolateParams(message string, params []interface{}, customParams ...CustomParams) string {
+	return interpolate(message, params, formatRaw, customParams...)
+}
+
+// interpolate implements interpolateParams, using format to convert every substituted value
+// into text. Only substituted values go through format, the literal text of the message is
+// copied as is.
+func interpolate(message string, params []interface{}, format valueFormatter, customParams ...CustomParams) string {
 	if (params == nil || len(params) == 0) && len(customParams) == 0 {
 		return message
 	}
 
-	// Regular expression to find escaped braces (double braces)
-	escapedPattern := regexp.MustCompile(`{{([^{}]*?)}}`)
-
-	// Store all escaped sequences for later restoration
-	var replacements []struct {
-		placeholder string
-		replacement string
-	}
-
-	// Find all escaped sequences and generate unique placeholders
-	result := escapedPattern.ReplaceAllStringFunc(message, func(match string) string {
-		// Generate a unique placeholder that's unlikely to occur in the message
-		placeholder := fmt.Sprintf("__ESCAPED_BRACE_%d__", len(replacements))
-
-		// Extract content between double braces and create the single-brace version
-		inner := match[2 : len(match)-2] // Remove {{ and }}
-		replacement := "{" + inner + "}"
-
-		// Store for later restoration
-		replacements = append(replacements, struct {
-			placeholder string
-			replacement string
-		}{
-			placeholder: placeholder,
-			replacement: replacement,
-		})
-
-		return placeholder
-	})
-
 	// Define named parameter mapping
 	namedParams := map[string]interface{}{}
 	if len(params) > 0 {
@@ -120,33 +182,41 @@ func interpolateParams(message string, params []interface{}, customParams ...Cus
 	}
 
 	// Add custom parameters if provided
-	if len(customParams) > 0 {
-		for _, cp := range customParams {
-			for name, value := range cp {
-				// Custom parameters override default ones
-				namedParams[name] = value
-			}
+	for _, cp := range customParams {
+		for name, value := range cp {
+			// Custom parameters override default ones
+			namedParams[name] = value
 		}
 	}
 
-	// Replace named placeholders with parameter values
-	// Use a regular expression to match exact placeholder patterns
-	placeholderRegex := regexp.MustCompile(`{([^{}]+)}`)
-	result = placeholderRegex.ReplaceAllStringFunc(result, func(placeholder string) string {
+	return placeholderPattern.ReplaceAllStringFunc(message, func(placeholder string) string {
+		// Escaped braces: {{name}} -> {name}
+		if strings.HasPrefix(placeholder, "{{") && strings.HasSuffix(placeholder, "}}") {
+			return placeholder[1 : len(placeholder)-1]
+		}
+
 		// Extract the parameter name without the braces
 		paramName := placeholder[1 : len(placeholder)-1]
 
-		// Special case: Skip replacing parameter names that begin with a digit
-		// This is to avoid confusion with positional parameters and ensure
-		// placeholders like {0name} remain as they are
-		if len(paramName) > 0 && paramName[0] >= '0' && paramName[0] <= '9' {
+		// Parameter names that begin with a digit are either positional placeholders
+		// or literals like {0name} and {000} which are left as they are
+		if paramName[0] >= '0' && paramName[0] <= '9' {
+			if !positionalPattern.MatchString(paramName) {
+				return placeholder
+			}
+
+			index, err := strconv.Atoi(paramName)
+			if err == nil && index < len(params) {
+				return format(params[index])
+			}
+
 			return placeholder
 		}
 
 		// Check if this parameter exists in the named parameters
 		if value, exists := namedParams[paramName]; exists {
 			if value != nil {
-				return fmt.Sprintf("%v", value)
+				return format(value)
 			}
 			return "" // Replace nil values with empty string
 		}
@@ -154,40 +224,6 @@ func interpolateParams(message string, params []interface{}, customParams ...Cus
 		// If not found, return the original placeholder
 		return placeholder
 	})
-
-	// Replace positional placeholders with parameter values
-	if params != nil {
-		// Define a strict pattern for positional parameters
-		// Only match {0}, {1}, {2}, etc. as positional parameters
-		// The pattern specifically excludes leading zeros to avoid {000000000} being treated as positional
-		positionalRegex := regexp.MustCompile(`{([0-9])}` + "|" + `{([1-9][0-9]+)}`)
-		result = positionalRegex.ReplaceAllStringFunc(result, func(placeholder string) string {
-			// Extract the index without the braces
-			indexStr := placeholder[1 : len(placeholder)-1]
-
-			// Skip if the string starts with a leading zero and has more than one digit
-			if len(indexStr) > 1 && indexStr[0] == '0' {
-				return placeholder
-			}
-
-			index, err := strconv.Atoi(indexStr)
-
-			// Check if this is a valid index in our params array
-			if err == nil && index >= 0 && index < len(params) {
-				return fmt.Sprintf("%v", params[index])
-			}
-
-			// If not found or invalid, return the original placeholder
-			return placeholder
-		})
-	}
-
-	// Restore all escaped placeholders with their single-brace versions
-	for _, item := range replacements {
-		result = strings.Replace(result, item.placeholder, item.replacement, 1)
-	}
-
-	return result
 }
 
 // CreateValidationParams creates a slice of parameters from validation error data
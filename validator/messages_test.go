@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,6 +44,37 @@ func TestValidationMessages(t *testing.T) {
 		messages.ResolveMessage("user", "login", []interface{}{"john"}, customParams))
 }
 
+func TestValidationMessageFuncs(t *testing.T) {
+	messages := NewValidationMessages()
+
+	messages.SetDefaultMessageFunc("user.name", func(ctx context.Context, e ValidationError) string {
+		return "default func for " + e.Constraint
+	})
+	assert.Equal(t, "default func for min", messages.ResolveMessage("user.name", "min", nil))
+
+	messages.SetMessageFunc("user.name", "required", func(ctx context.Context, e ValidationError) string {
+		return e.Path + " is required"
+	})
+	assert.Equal(t, "user.name is required", messages.ResolveMessage("user.name", "required", nil))
+
+	// String messages replace functions for the same path and constraint
+	messages.SetMessage("user.name", "required", "{0} is required")
+	assert.Equal(t, "name is required", messages.ResolveMessage("user.name", "required", []interface{}{"name"}))
+
+	messages.SetDefaultMessage("user.name", "default message")
+	assert.Equal(t, "default message", messages.ResolveMessage("user.name", "min", nil))
+
+	// Configs created without functions are still resolved
+	literal := ValidationMessages{
+		"email": ValidationMessageConfig{Default: "literal default"},
+	}
+	assert.Equal(t, "literal default", literal.ResolveMessage("email", "email", nil))
+	literal.SetMessageFunc("email", "email", func(ctx context.Context, e ValidationError) string {
+		return "literal func"
+	})
+	assert.Equal(t, "literal func", literal.ResolveMessage("email", "email", nil))
+}
+
 func TestInterpolateParams(t *testing.T) {
 	tests := []struct {
 		name     string
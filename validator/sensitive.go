@@ -0,0 +1,184 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactedValue replaces the actual value of sensitive fields that are fully redacted.
+const RedactedValue = "[REDACTED]"
+
+// maskPrefix replaces the hidden part of values masked with MaskKeepLast.
+// It has a fixed length so the mask does not reveal the length of the value.
+const maskPrefix = "****"
+
+// Redactor replaces a sensitive value with a representation that is safe to include in
+// validation errors, logs and API responses.
+type Redactor func(value interface{}) interface{}
+
+// RedactFull is a Redactor that replaces any value with RedactedValue.
+func RedactFull(value interface{}) interface{} {
+	return RedactedValue
+}
+
+// MaskKeepLast returns a Redactor that masks a value except for its last n characters,
+// e.g. "****1234" for a card number with n = 4. Values with n characters or less are fully masked.
+func MaskKeepLast(n int) Redactor {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return maskPrefix
+		}
+
+		s := fmt.Sprintf("%v", value)
+		count := utf8.RuneCountInString(s)
+		if n <= 0 || count <= n {
+			return maskPrefix
+		}
+
+		runes := []rune(s)
+		return maskPrefix + string(runes[count-n:])
+	}
+}
+
+// RegisterSensitivePath marks the field at path as sensitive. The actual value of validation errors
+// for that path is replaced using redact, both in ValidationError.Actual and in interpolated messages.
+// Validation itself still sees the real value. A nil redact uses RedactFull.
+//
+// Example:
+//
+//	v.RegisterSensitivePath("user.password", validator.RedactFull)
+//	v.RegisterSensitivePath("payment.card_number", validator.MaskKeepLast(4))
+func (v *Validator) RegisterSensitivePath(path string, redact Redactor) *Validator {
+	if redact == nil {
+		redact = RedactFull
+	}
+	v.SensitivePaths[normalizePath(path)] = redact
+	return v
+}
+
+// RegisterSensitiveType marks all fields of the given types as sensitive, see RegisterSensitivePath.
+// A nil redact uses RedactFull.
+//
+// Example:
+//
+//	type Secret string
+//	v.RegisterSensitiveType(validator.RedactFull, Secret(""))
+func (v *Validator) RegisterSensitiveType(redact Redactor, types ...interface{}) *Validator {
+	if redact == nil {
+		redact = RedactFull
+	}
+	for _, t := range types {
+		v.SensitiveTypes[reflect.TypeOf(t)] = redact
+	}
+	return v
+}
+
+// RegisterSensitiveTag marks all fields validated with one of the given tags as sensitive, see
+// RegisterSensitivePath. The value is redacted in the errors of every constraint of these fields, not
+// only the errors of the tags themselves, so a failing "max" does not reveal a tax identifier validated
+// by "ca_sin". Fields are matched by the tags of their `validate` struct tag. A nil redact uses RedactFull.
+//
+// Like the validations of the BaseValidator, sensitive tags are shared with the validators created by
+// UseMessages, before or after this call.
+//
+// Example:
+//
+//	v.RegisterSensitiveTag(validator.MaskKeepLast(3), "ca_sin", "us_ssn")
+func (v *Validator) RegisterSensitiveTag(redact Redactor, tags ...string) *Validator {
+	if redact == nil {
+		redact = RedactFull
+	}
+	for _, tag := range tags {
+		v.SensitiveTags[tag] = redact
+	}
+	return v
+}
+
+// getRedactor returns the Redactor for a failing field, or nil if the field is not sensitive.
+// The `sensitive` struct tag takes precedence over registered paths, which take precedence over
+// registered types, which take precedence over registered validation tags.
+func (v *Validator) getRedactor(field reflect.StructField, found bool, normPath string, fieldType reflect.Type, constraint string) Redactor {
+	if found {
+		if value, ok := field.Tag.Lookup("sensitive"); ok {
+			return parseSensitiveTag(value)
+		}
+	}
+
+	if redact, ok := v.SensitivePaths[normPath]; ok {
+		return redact
+	}
+
+	if fieldType != nil {
+		if redact, ok := v.SensitiveTypes[fieldType]; ok {
+			return redact
+		}
+		if fieldType.Kind() == reflect.Ptr {
+			if redact, ok := v.SensitiveTypes[fieldType.Elem()]; ok {
+				return redact
+			}
+		}
+	}
+
+	if len(v.SensitiveTags) > 0 {
+		if redact, ok := v.SensitiveTags[constraint]; ok {
+			return redact
+		}
+		if found {
+			for _, tag := range validateTagNames(field.Tag.Get("validate")) {
+				if redact, ok := v.SensitiveTags[tag]; ok {
+					return redact
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTagNames returns the names of the validation tags of a `validate` struct tag, without their params,
+// e.g. ["required", "min", "ca_sin"] for "required,min=9|ca_sin"
+func validateTagNames(value string) []string {
+	var names []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '|' }) {
+		name, _, _ := strings.Cut(tag, "=")
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+// parseSensitiveTag converts the value of a `sensitive` struct tag into a Redactor.
+//
+// Supported values:
+//   - "", "true" or "redact": replace the value with RedactedValue
+//   - "last{n}": keep only the last n characters, e.g. "last4" -> "****1234"
+//   - "false" or "-": the field is not sensitive
+//
+// Unknown values are treated as "redact" to avoid leaking values due to a typo.
+//
+// Example:
+//
+//	type Payment struct {
+//	    CardNumber string `validate:"required,credit_card" sensitive:"last4"`
+//	    CVV        string `validate:"required,len=3" sensitive:"true"`
+//	}
+func parseSensitiveTag(value string) Redactor {
+	value = strings.TrimSpace(value)
+
+	switch value {
+	case "false", "-":
+		return nil
+	case "", "true", "redact":
+		return RedactFull
+	}
+
+	if strings.HasPrefix(value, "last") {
+		if n, err := strconv.Atoi(strings.TrimPrefix(value, "last")); err == nil {
+			return MaskKeepLast(n)
+		}
+	}
+
+	return RedactFull
+}
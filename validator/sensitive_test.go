@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskKeepLast(t *testing.T) {
+	mask := MaskKeepLast(4)
+
+	assert.Equal(t, "****1234", mask("4111111111111234"))
+	assert.Equal(t, "****", mask("1234"), "Short values should be fully masked")
+	assert.Equal(t, "****", mask(nil))
+	assert.Equal(t, "****5678", mask(12345678))
+	assert.Equal(t, "****", MaskKeepLast(0)("secret"))
+}
+
+func TestParseSensitiveTag(t *testing.T) {
+	assert.Equal(t, RedactedValue, parseSensitiveTag("")("secret"))
+	assert.Equal(t, RedactedValue, parseSensitiveTag("true")("secret"))
+	assert.Equal(t, RedactedValue, parseSensitiveTag("unknown")("secret"))
+	assert.Equal(t, "****cret", parseSensitiveTag("last4")("secret"))
+	assert.Nil(t, parseSensitiveTag("false"))
+	assert.Nil(t, parseSensitiveTag("-"))
+}
+
+func TestSensitiveRedaction(t *testing.T) {
+	t.Run("Struct tag", func(t *testing.T) {
+		type Signup struct {
+			Password   string `json:"password" validate:"min=12" sensitive:"true"`
+			CardNumber string `json:"card_number" validate:"numeric" sensitive:"last4"`
+			Nickname   string `json:"nickname" validate:"alpha"`
+		}
+
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessage("min", "{value} is too short")
+		v.SetDefaultTagMessage("numeric", "{value} is not a number")
+		v.SetDefaultTagMessage("alpha", "{value} is not alphabetic")
+
+		err := v.Validate(Signup{Password: "hunter2", CardNumber: "4111-1111-1111-1234", Nickname: "b0b"})
+		errs := err.(ValidationErrors)
+		assert.Len(t, errs, 3)
+
+		password := errs.ErrorsForPath("password")[0]
+		assert.Equal(t, RedactedValue, password.Actual)
+		assert.Equal(t, "[REDACTED] is too short", password.Message)
+		assert.NotContains(t, err.Error(), "hunter2")
+
+		card := errs.ErrorsForPath("card_number")[0]
+		assert.Equal(t, "****1234", card.Actual)
+		assert.Equal(t, "****1234 is not a number", card.Message)
+
+		nickname := errs.ErrorsForPath("nickname")[0]
+		assert.Equal(t, "b0b", nickname.Actual, "Non sensitive values should not be redacted")
+
+		b, jsonErr := json.Marshal(errs)
+		assert.NoError(t, jsonErr)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.NotContains(t, string(b), "4111-1111")
+	})
+
+	t.Run("Registered path", func(t *testing.T) {
+		type Account struct {
+			Token string `json:"token" validate:"len=32"`
+		}
+		type Request struct {
+			Accounts []Account `json:"accounts" validate:"dive"`
+		}
+
+		v := New().UseJsonTagName()
+		v.RegisterSensitivePath("accounts[].token", nil)
+		v.SetDefaultTagMessage("len", "{value} has the wrong length")
+
+		errs := v.Validate(Request{Accounts: []Account{{Token: "abc"}}}).(ValidationErrors)
+		assert.Equal(t, RedactedValue, errs[0].Actual)
+		assert.Equal(t, "[REDACTED] has the wrong length", errs[0].Message)
+	})
+
+	t.Run("Registered type", func(t *testing.T) {
+		type Secret string
+		type Config struct {
+			APIKey Secret `validate:"min=10"`
+		}
+
+		v := New()
+		v.RegisterSensitiveType(MaskKeepLast(2), Secret(""))
+
+		errs := v.Validate(Config{APIKey: "abc123"}).(ValidationErrors)
+		assert.Equal(t, "****23", errs[0].Actual)
+	})
+
+	t.Run("Struct tag opts out of registered type", func(t *testing.T) {
+		type Secret string
+		type Config struct {
+			Public Secret `validate:"min=10" sensitive:"false"`
+		}
+
+		v := New()
+		v.RegisterSensitiveType(RedactFull, Secret(""))
+
+		errs := v.Validate(Config{Public: "abc"}).(ValidationErrors)
+		assert.Equal(t, Secret("abc"), errs[0].Actual)
+	})
+
+	t.Run("Validation sees the real value", func(t *testing.T) {
+		type Login struct {
+			Password string `validate:"eq=hunter2" sensitive:"true"`
+		}
+
+		v := New()
+		assert.NoError(t, v.Validate(Login{Password: "hunter2"}))
+	})
+
+	t.Run("Registered tag", func(t *testing.T) {
+		type Applicant struct {
+			TaxID string `validate:"max=9,taxid"`
+			Name  string `validate:"max=3"`
+		}
+
+		v := New()
+		assert.NoError(t, v.RegisterValidation("taxid", func(fl govalidator.FieldLevel) bool {
+			return fl.Field().String() == "046454286"
+		}))
+		v.RegisterSensitiveTag(MaskKeepLast(3), "taxid")
+
+		// Every constraint of the field is redacted, not only the registered tag
+		errs := v.Validate(Applicant{TaxID: "046 454 286", Name: "Alice"}).(ValidationErrors)
+		assert.Equal(t, "max", errs[0].Constraint)
+		assert.Equal(t, "****286", errs[0].Actual)
+		assert.Equal(t, "Alice", errs[1].Actual)
+
+		errs = v.Validate(Applicant{TaxID: "123456789"}).(ValidationErrors)
+		assert.Equal(t, "taxid", errs[0].Constraint)
+		assert.Equal(t, "****789", errs[0].Actual)
+	})
+
+	t.Run("UseMessages keeps registrations", func(t *testing.T) {
+		v := New()
+		v.RegisterSensitivePath("password", nil)
+		v.RegisterSensitiveTag(nil, "taxid")
+		newV := v.UseMessages(NewValidationMessages())
+		assert.Contains(t, newV.SensitivePaths, "password")
+		assert.Contains(t, newV.SensitiveTags, "taxid")
+
+		// Sensitive tags are shared like the validations of the BaseValidator
+		v.RegisterSensitiveTag(nil, "card")
+		assert.Contains(t, newV.SensitiveTags, "card")
+	})
+}
@@ -81,16 +81,39 @@ func normalizePath(path string) string {
 //	    Username string `validate:"required,min=3" errmsg-required:"Username is mandatory" errmsg-min:"Username must have at least {param} characters"`
 //	    Email    string `validate:"required,email" errmsg:"Email address has an issue"`
 //	}
-func getRawTagMessage(field reflect.StructField, constraint string) string {
-	// Try to get constraint specific error message tag
-	message := field.Tag.Get(fmt.Sprintf("errmsg-%s", constraint))
+//
+// When several constraints are given, their errmsg-{constraint} tags are tried in order before
+// falling back to errmsg.
+func getRawTagMessage(field reflect.StructField, constraints ...string) string {
+	return getRawTagValue(field, "errmsg", constraints)
+}
+
+// getRawTagCode extracts the error code for a constraint from struct field tags.
+// It first looks for a constraint-specific error code tag (errcode-{constraint}),
+// then falls back to the general error code tag (errcode).
+//
+// Example:
+//
+//	type User struct {
+//	    Email string `validate:"required,email" errcode-required:"ERR_EMAIL_REQUIRED" errcode-email:"ERR_EMAIL_FORMAT"`
+//	    Name  string `validate:"required,min=2" errcode:"ERR_NAME_INVALID"`
+//	}
+func getRawTagCode(field reflect.StructField, constraints ...string) string {
+	return getRawTagValue(field, "errcode", constraints)
+}
 
-	// Try to get default error message tag
-	if message == "" {
-		message = field.Tag.Get("errmsg")
+// getRawTagValue looks up the first non-empty {name}-{constraint} tag for the given constraints,
+// falling back to the {name} tag.
+func getRawTagValue(field reflect.StructField, name string, constraints []string) string {
+	for _, constraint := range constraints {
+		// Try to get constraint specific tag
+		if value := field.Tag.Get(fmt.Sprintf("%s-%s", name, constraint)); value != "" {
+			return value
+		}
 	}
 
-	return message
+	// Try to get default tag
+	return field.Tag.Get(name)
 }
 
 // getStructFieldFromNamespace traverses the struct hierarchy to find the field specified by the namespace.
@@ -203,3 +226,90 @@ func getStructFieldFromNamespace(structType reflect.Type, namespace string, leaf
 
 	return reflect.StructField{}, false
 }
+
+// fieldParent returns the struct holding the field of fe, found by following the struct namespace of fe
+// from top, the value given to ValidateCtx. It returns false if the struct cannot be found.
+func fieldParent(top reflect.Value, fe govalidator.FieldError) (reflect.Value, bool) {
+	current := indirectValue(top)
+	if current.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	// "User.Addresses[1].Street" is held by "User.Addresses[1]", and "User.Tags[0]" by "User"
+	path, ok := strings.CutSuffix(fe.StructNamespace(), fe.StructField())
+	if !ok {
+		return reflect.Value{}, false
+	}
+	path, ok = strings.CutPrefix(strings.TrimSuffix(path, "."), current.Type().Name())
+	if !ok {
+		return reflect.Value{}, false
+	}
+	if current.Type().Name() == "" && path != "" {
+		// Namespaces of anonymous structs have no type name
+		path = "." + path
+	}
+
+	for path != "" {
+		current = indirectValue(current)
+
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[") + 1
+			if end == 0 {
+				end = len(path)
+			}
+			if current.Kind() != reflect.Struct {
+				return reflect.Value{}, false
+			}
+			current = current.FieldByName(path[1:end])
+			path = path[end:]
+
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return reflect.Value{}, false
+			}
+			key := path[1:end]
+			path = path[end+1:]
+
+			switch current.Kind() {
+			case reflect.Slice, reflect.Array:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= current.Len() {
+					return reflect.Value{}, false
+				}
+				current = current.Index(i)
+			case reflect.Map:
+				// go-playground formats map keys with %v
+				var value reflect.Value
+				for iter := current.MapRange(); iter.Next(); {
+					if fmt.Sprintf("%v", iter.Key().Interface()) == key {
+						value = iter.Value()
+						break
+					}
+				}
+				current = value
+			default:
+				return reflect.Value{}, false
+			}
+
+		default:
+			return reflect.Value{}, false
+		}
+
+		if !current.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+
+	current = indirectValue(current)
+	return current, current.Kind() == reflect.Struct
+}
+
+// indirectValue returns the value v points to or holds, through pointers and interfaces
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
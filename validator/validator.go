@@ -6,20 +6,51 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	govalidator "github.com/go-playground/validator/v10"
 )
 
 // CustomParams is a map of custom parameter names to their values
-// for use in validation error messages
+// for use in validation error messages.
+// Values can also be a ParamFunc or an ErrorParamFunc, which are evaluated when a message is rendered.
 type CustomParams map[string]interface{}
 
+// ParamFunc computes the value of a custom parameter from the context given to ValidateCtx.
+// It is evaluated lazily, only when a message actually uses the parameter.
+type ParamFunc func(ctx context.Context) interface{}
+
+// ErrorParamFunc computes the value of a custom parameter from the context given to ValidateCtx
+// and the validation error the message is rendered for.
+// It is evaluated lazily, only when a message actually uses the parameter.
+type ErrorParamFunc func(ctx context.Context, e ValidationError) interface{}
+
 type Validator struct {
-	BaseValidator      *govalidator.Validate
-	DefaultMessage     string
-	DefaultTagMessages map[string]string
-	Messages           ValidationMessages
-	CustomParams       CustomParams
+	BaseValidator          *govalidator.Validate
+	DefaultMessage         string
+	DefaultMessageFunc     MessageFunc
+	DefaultTagMessages     map[string]string
+	DefaultTagMessageFuncs map[string]MessageFunc
+	Messages               ValidationMessages
+	CustomParams           CustomParams
+	EscapeMode             EscapeMode                // Escaping applied to values interpolated into messages
+	MaxValueLength         int                       // Maximum number of runes of an interpolated value, 0 for no limit
+	SensitivePaths         map[string]Redactor       // Redactors for sensitive values by normalized path
+	SensitiveTypes         map[reflect.Type]Redactor // Redactors for sensitive values by field type
+	SensitiveTags          map[string]Redactor       // Redactors for sensitive values by validation tag, shared like the tags of BaseValidator
+	DefaultTagCodes        map[string]string         // Error codes by constraint
+	Codes                  ErrorCodes                // Error codes by normalized path and constraint
+	AsyncConcurrency       int                       // Maximum number of async and deferred validations running at once
+	AsyncTimeout           time.Duration             // Maximum duration of the async and deferred validations of a call, 0 for no limit
+
+	extensions *extensions // State of packages extending the validator, shared like the tags of BaseValidator
+}
+
+// extensions holds the values stored with Validator.Extension
+type extensions struct {
+	mu     sync.Mutex
+	values map[interface{}]interface{}
 }
 
 // New creates a new Validator instance with default configuration.
@@ -27,24 +58,49 @@ func New() *Validator {
 	v := govalidator.New()
 
 	return &Validator{
-		BaseValidator:      v,
-		DefaultMessage:     "Invalid value",
-		DefaultTagMessages: make(map[string]string),
-		Messages:           NewValidationMessages(),
-		CustomParams:       make(CustomParams),
+		BaseValidator:          v,
+		DefaultMessage:         "Invalid value",
+		DefaultTagMessages:     make(map[string]string),
+		DefaultTagMessageFuncs: make(map[string]MessageFunc),
+		Messages:               NewValidationMessages(),
+		CustomParams:           make(CustomParams),
+		EscapeMode:             EscapePlain,
+		SensitivePaths:         make(map[string]Redactor),
+		SensitiveTypes:         make(map[reflect.Type]Redactor),
+		SensitiveTags:          make(map[string]Redactor),
+		DefaultTagCodes:        make(map[string]string),
+		Codes:                  NewErrorCodes(),
+		AsyncConcurrency:       DefaultAsyncConcurrency,
+		extensions:             &extensions{values: make(map[interface{}]interface{})},
 	}
 }
 
 // UseMessages creates a new Validator instance with the same base configuration
 // but with different validation messages for the specific context.
 // This allows different handlers or methods to have custom error messages.
+//
+// The new Validator shares the BaseValidator, and with it the registered validations. It also shares
+// the SensitiveTags, so tags registered as sensitive later, e.g. along with the validations of a
+// package, are redacted by both validators.
 func (v *Validator) UseMessages(messages ValidationMessages) *Validator {
 	newV := &Validator{
-		BaseValidator:      v.BaseValidator,
-		DefaultMessage:     v.DefaultMessage,
-		DefaultTagMessages: make(map[string]string),
-		Messages:           messages,
-		CustomParams:       make(CustomParams),
+		BaseValidator:          v.BaseValidator,
+		DefaultMessage:         v.DefaultMessage,
+		DefaultMessageFunc:     v.DefaultMessageFunc,
+		DefaultTagMessages:     make(map[string]string),
+		DefaultTagMessageFuncs: make(map[string]MessageFunc),
+		Messages:               messages,
+		CustomParams:           make(CustomParams),
+		EscapeMode:             v.EscapeMode,
+		MaxValueLength:         v.MaxValueLength,
+		SensitivePaths:         make(map[string]Redactor),
+		SensitiveTypes:         make(map[reflect.Type]Redactor),
+		SensitiveTags:          v.SensitiveTags,
+		DefaultTagCodes:        make(map[string]string),
+		Codes:                  NewErrorCodes(),
+		AsyncConcurrency:       v.AsyncConcurrency,
+		AsyncTimeout:           v.AsyncTimeout,
+		extensions:             v.extensions,
 	}
 
 	newV.DefaultMessage = v.DefaultMessage
@@ -52,21 +108,76 @@ func (v *Validator) UseMessages(messages ValidationMessages) *Validator {
 	for tag, msg := range v.DefaultTagMessages {
 		newV.DefaultTagMessages[tag] = msg
 	}
+	for tag, fn := range v.DefaultTagMessageFuncs {
+		newV.DefaultTagMessageFuncs[tag] = fn
+	}
 
 	// Copy custom parameters
 	for name, value := range v.CustomParams {
 		newV.CustomParams[name] = value
 	}
 
+	// Copy sensitive value registrations
+	for path, redact := range v.SensitivePaths {
+		newV.SensitivePaths[path] = redact
+	}
+	for typ, redact := range v.SensitiveTypes {
+		newV.SensitiveTypes[typ] = redact
+	}
+
+	// Copy error codes
+	for tag, code := range v.DefaultTagCodes {
+		newV.DefaultTagCodes[tag] = code
+	}
+	for path, codes := range v.Codes {
+		for constraint, code := range codes {
+			newV.Codes.SetCode(path, constraint, code)
+		}
+	}
+
 	return newV
 }
 
+// Extension returns the value stored under key, storing the result of create first if there is none.
+// Packages extending the validator use it to keep their state, such as the registries behind their tags,
+// with an unexported key type like context keys:
+//
+//	type registryKey struct{}
+//
+//	registry := v.Extension(registryKey{}, func() interface{} { return newRegistry() }).(*registry)
+//
+// A nil create only looks the value up, and returns nil if there is none. Validators created with
+// UseMessages share the values, like they share the tags of the BaseValidator.
+func (v *Validator) Extension(key interface{}, create func() interface{}) interface{} {
+	if v.extensions == nil {
+		v.extensions = &extensions{values: make(map[interface{}]interface{})}
+	}
+
+	v.extensions.mu.Lock()
+	defer v.extensions.mu.Unlock()
+
+	value, ok := v.extensions.values[key]
+	if !ok && create != nil {
+		value = create()
+		v.extensions.values[key] = value
+	}
+	return value
+}
+
 // ValidateCtx performs validation on the provided struct based on its validation tags using the given context.
 // Returns nil if validation passes, or ValidationErrors containing details about
 // validation failures.
 func (v *Validator) ValidateCtx(ctx context.Context, i interface{}) error {
-	if err := v.BaseValidator.StructCtx(ctx, i); err != nil {
+	ctx, collector := withViolationCollector(ctx)
+
+	err := v.BaseValidator.StructCtx(ctx, i)
+	if abortErr := collector.aborted(); abortErr != nil {
+		return abortErr
+	}
+
+	if err != nil {
 		validationErrors := ValidationErrors{}
+		top := reflect.ValueOf(i)
 		structType := reflect.TypeOf(i)
 
 		// Handle if input is a pointer
@@ -74,6 +185,8 @@ func (v *Validator) ValidateCtx(ctx context.Context, i interface{}) error {
 			structType = structType.Elem()
 		}
 
+		var deferred []deferredError
+
 		switch e := err.(type) {
 		case govalidator.ValidationErrors:
 			for _, ve := range e {
@@ -83,6 +196,15 @@ func (v *Validator) ValidateCtx(ctx context.Context, i interface{}) error {
 				param := ve.Param()
 				actual := ve.Value()
 
+				// For nested structs, we need to traverse the struct hierarchy to find the field
+				// with the validation and message tags
+				structField, found := getStructFieldFromNamespace(structType, ve.StructNamespace(), ve.StructField())
+
+				// Redact sensitive values before they reach the error or any message
+				if redact := v.getRedactor(structField, found, normPath, ve.Type(), constraint); redact != nil {
+					actual = redact(actual)
+				}
+
 				// Create validation error with basic information
 				valError := ValidationError{
 					Field:      ve.Field(),
@@ -92,40 +214,30 @@ func (v *Validator) ValidateCtx(ctx context.Context, i interface{}) error {
 					Actual:     actual,
 				}
 
-				// Create params for interpolation
-				params := CreateValidationParams(valError)
-
-				var message string
-
-				// Try to get error message from tag
-				// For nested structs, we need to traverse the struct hierarchy to find the field
-				// with the validation tag
-				structField, found := getStructFieldFromNamespace(structType, ve.StructNamespace(), ve.StructField())
-				if found {
-					tagMessage := getRawTagMessage(structField, constraint)
-					if tagMessage != "" {
-						// Apply parameter interpolation to the struct tag message
-						message = interpolateParams(tagMessage, params, v.CustomParams)
-					}
+				records := collector.take(ve, top)
+				if len(records) == 0 {
+					validationErrors = append(validationErrors, v.completeError(ctx, valError, structField, found, normPath, ""))
+					continue
 				}
 
-				// Fallback to messages table if no struct tag message found
-				if message == "" {
-					// Try to get message from ValidationMessages first
-					message = v.Messages.ResolveMessage(normPath, constraint, params, v.CustomParams)
+				// Deferred and async validations run once every field has been checked, the error is a placeholder until then
+				if hasPendingRecord(records) {
+					deferred = append(deferred, deferredError{
+						index:       len(validationErrors),
+						records:     records,
+						err:         valError,
+						structField: structField,
+						found:       found,
+						normPath:    normPath,
+					})
+					validationErrors = append(validationErrors, valError)
+					continue
 				}
 
-				// Fall back to default message lookup
-				if message == "" {
-					if msg, ok := v.DefaultTagMessages[constraint]; ok {
-						message = interpolateParams(msg, params, v.CustomParams)
-					} else {
-						message = interpolateParams(v.DefaultMessage, params, v.CustomParams)
-					}
+				// Validations registered with RegisterViolationValidation report each violation as its own error
+				for _, record := range records {
+					validationErrors = append(validationErrors, v.violationErrors(ctx, valError, record, record.violations, structField, found, normPath)...)
 				}
-
-				valError.Message = message
-				validationErrors = append(validationErrors, valError)
 			}
 		case *govalidator.InvalidValidationError:
 			// This indicates a problem with the validator itself, such as a bad struct tag.
@@ -135,6 +247,16 @@ func (v *Validator) ValidateCtx(ctx context.Context, i interface{}) error {
 			return err
 		}
 
+		if len(deferred) > 0 {
+			var err error
+			if validationErrors, err = v.runDeferred(ctx, validationErrors, deferred); err != nil {
+				return err
+			}
+			if len(validationErrors) == 0 {
+				return nil
+			}
+		}
+
 		return validationErrors
 	}
 
@@ -148,6 +270,90 @@ func (v *Validator) Validate(i interface{}) error {
 	return v.ValidateCtx(context.Background(), i)
 }
 
+// completeError resolves the error code and the message of e
+func (v *Validator) completeError(ctx context.Context, e ValidationError, field reflect.StructField, found bool, normPath string, fallbackMessage string) ValidationError {
+	e.Code = v.resolveCode(e, field, found, normPath)
+	e.Message = v.resolveMessage(ctx, e, field, found, normPath, fallbackMessage)
+	return e
+}
+
+// CompleteError resolves the Code and Message of a ValidationError built outside of ValidateCtx, such as
+// the result of a check run on a single value. They are resolved from the path, constraint and reason of e
+// like ValidateCtx does, except that there are no struct tags. fallbackMessage is used when no message
+// is set for the constraint, like the default message of a Violation.
+func (v *Validator) CompleteError(ctx context.Context, e ValidationError, fallbackMessage string) ValidationError {
+	return v.completeError(ctx, e, reflect.StructField{}, false, normalizePath(e.Path), fallbackMessage)
+}
+
+// resolveMessage finds and renders the message for a validation error. The resolution order is:
+//
+//  1. Field-specific struct tag error message (`errmsg-{constraint}` or `errmsg`)
+//  2. Path-specific message or message function for the constraint
+//  3. Path default message or message function
+//  4. Constraint-specific default message or message function
+//  5. The fallback message, if not empty (e.g., the default message of a Violation)
+//  6. Global default message or message function
+//
+// For errors with a reason, "{constraint}.{reason}" is tried before "{constraint}" at every level.
+func (v *Validator) resolveMessage(ctx context.Context, e ValidationError, field reflect.StructField, found bool, normPath string, fallbackMessage string) string {
+	keys := constraintKeys(e)
+
+	// Create params for interpolation
+	params := CreateValidationParams(e)
+	format := v.valueFormatter(ctx, e)
+	customParams := []CustomParams{v.CustomParams, CustomParams(e.Params)}
+
+	// Try to get error message from tag
+	if found {
+		if tagMessage := getRawTagMessage(field, keys...); tagMessage != "" {
+			// Apply parameter interpolation to the struct tag message
+			return interpolate(tagMessage, params, format, customParams...)
+		}
+	}
+
+	// Fallback to messages table if no struct tag message found
+	if message := v.Messages.resolveMessage(ctx, e, normPath, keys, params, format, customParams...); message != "" {
+		return message
+	}
+
+	// Fall back to default message lookup
+	for _, key := range keys {
+		if fn, ok := v.DefaultTagMessageFuncs[key]; ok && fn != nil {
+			return fn(ctx, e)
+		}
+		if msg, ok := v.DefaultTagMessages[key]; ok {
+			return interpolate(msg, params, format, customParams...)
+		}
+	}
+	if fallbackMessage != "" {
+		return interpolate(fallbackMessage, params, format, customParams...)
+	}
+	if v.DefaultMessageFunc != nil {
+		return v.DefaultMessageFunc(ctx, e)
+	}
+
+	return interpolate(v.DefaultMessage, params, format, customParams...)
+}
+
+// valueFormatter returns the formatter used to render interpolated values in the message of e.
+// Dynamic custom parameters are evaluated with ctx and e, and the escape mode set on ctx with
+// WithEscapeMode takes precedence over the Validator's EscapeMode.
+func (v *Validator) valueFormatter(ctx context.Context, e ValidationError) valueFormatter {
+	mode := v.EscapeMode
+	if m, ok := escapeModeFromContext(ctx); ok {
+		mode = m
+	}
+	maxLength := v.MaxValueLength
+
+	return func(value interface{}) string {
+		value, dynamic := resolveParamValue(ctx, e, value)
+		if dynamic && value == nil {
+			return ""
+		}
+		return formatValue(value, mode, maxLength)
+	}
+}
+
 // extractArrayIndex tries to extract an array index from a path like "users[2].name"
 // Returns the index and a boolean indicating success
 func extractArrayIndex(path string) (int, bool) {
@@ -164,6 +370,29 @@ func extractArrayIndex(path string) (int, bool) {
 // SetDefaultMessage sets the default message that should be used if not path/tag matches
 func (v *Validator) SetDefaultMessage(s string) *Validator {
 	v.DefaultMessage = s
+	v.DefaultMessageFunc = nil
+	return v
+}
+
+// SetDefaultMessageFunc sets a function that builds the message when no path/tag matches.
+// It replaces the message set with SetDefaultMessage.
+func (v *Validator) SetDefaultMessageFunc(fn MessageFunc) *Validator {
+	v.DefaultMessageFunc = fn
+	return v
+}
+
+// SetEscapeMode sets how values interpolated into messages are escaped, e.g. EscapeHTML when
+// messages are rendered into HTML pages. Only interpolated values are escaped, never the text
+// of the message itself. Use WithEscapeMode to override the mode for a single ValidateCtx call.
+func (v *Validator) SetEscapeMode(mode EscapeMode) *Validator {
+	v.EscapeMode = mode
+	return v
+}
+
+// SetMaxValueLength sets the maximum number of runes of an interpolated value. Longer values
+// are truncated and suffixed with an ellipsis. Values are not truncated by default, or when n <= 0.
+func (v *Validator) SetMaxValueLength(n int) *Validator {
+	v.MaxValueLength = n
 	return v
 }
 
@@ -171,6 +400,21 @@ func (v *Validator) SetDefaultMessage(s string) *Validator {
 // Example: "required", "email"
 func (v *Validator) SetDefaultTagMessage(tag string, s string) *Validator {
 	v.DefaultTagMessages[tag] = s
+	delete(v.DefaultTagMessageFuncs, tag)
+	return v
+}
+
+// SetDefaultTagMessageFunc sets a function that builds the default message for a specific tag error.
+// It replaces the message set with SetDefaultTagMessage for the same tag.
+//
+// Example:
+//
+//	v.SetDefaultTagMessageFunc("oneof", func(ctx context.Context, e validator.ValidationError) string {
+//	    return fmt.Sprintf("%s must be one of: %s", e.Field, strings.Join(strings.Fields(e.Param), ", "))
+//	})
+func (v *Validator) SetDefaultTagMessageFunc(tag string, fn MessageFunc) *Validator {
+	v.DefaultTagMessageFuncs[tag] = fn
+	delete(v.DefaultTagMessages, tag)
 	return v
 }
 
@@ -191,6 +435,23 @@ func (v *Validator) SetPathDefaultMessage(path, message string) *Validator {
 	return v
 }
 
+// SetConstraintMessageFunc sets a function that builds the message for a field path and constraint combination.
+// It replaces the message set with SetConstraintMessage for the same path and constraint.
+func (v *Validator) SetConstraintMessageFunc(path, constraint string, fn MessageFunc) *Validator {
+	path = normalizePath(path)
+	v.Messages.SetMessageFunc(path, constraint, fn)
+	return v
+}
+
+// SetPathDefaultMessageFunc sets a function that builds the default message for a field path, used when
+// no constraint-specific message is found.
+// It replaces the message set with SetPathDefaultMessage for the same path.
+func (v *Validator) SetPathDefaultMessageFunc(path string, fn MessageFunc) *Validator {
+	path = normalizePath(path)
+	v.Messages.SetDefaultMessageFunc(path, fn)
+	return v
+}
+
 // RegisterTagNameFunc registers a function to extract the tag name from the field's struct tag.
 // This allows custom tag name customization similar to UseJsonTagName but with any custom logic.
 func (v *Validator) RegisterTagNameFunc(fn func(field reflect.StructField) string) *Validator {
@@ -222,6 +483,14 @@ func (v *Validator) RegisterTagNameFunc(fn func(field reflect.StructField) strin
 //	v.SetConstraintMessage("password", "min", "{field} must have at least {param} characters (minimum: {minLength})")
 //	// Results in: "password must have at least 6 characters (minimum: 8)"
 //
+//	// Dynamic parameters, evaluated per ValidateCtx call only when a message uses them
+//	v.AddCustomParam("tenantName", validator.ParamFunc(func(ctx context.Context) interface{} {
+//	    return tenantFromContext(ctx).Name
+//	}))
+//	v.AddCustomParam("maxUploadMB", validator.ErrorParamFunc(func(ctx context.Context, e validator.ValidationError) interface{} {
+//	    return tenantFromContext(ctx).UploadLimitMB(e.Path)
+//	}))
+//
 // Note: Custom parameter names cannot start with digits (e.g. "0name") as these would be
 // treated as literals in the message interpolation system.
 func (v *Validator) AddCustomParam(name string, value interface{}) *Validator {
@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -923,3 +925,202 @@ func TestNestedArrayCustomParams(t *testing.T) {
 	assert.Equal(t, "Contact type must be one of: home work mobile", errorMap["profile.contacts[0].type:oneof"])
 	assert.Equal(t, "Contact value is required for TestingApp", errorMap["profile.contacts[0].value:required"])
 }
+
+func TestDynamicCustomParams(t *testing.T) {
+	type tenantKey struct{}
+
+	type Upload struct {
+		Name string `json:"name" validate:"required"`
+		Size int    `json:"size" validate:"max=10"`
+	}
+
+	v := New()
+	v.UseJsonTagName()
+
+	unusedCalls := 0
+	v.AddCustomParam("tenantName", ParamFunc(func(ctx context.Context) interface{} {
+		return ctx.Value(tenantKey{})
+	}))
+	v.AddCustomParam("limitPath", ErrorParamFunc(func(ctx context.Context, e ValidationError) interface{} {
+		return e.Path + "@" + e.Param
+	}))
+	v.AddCustomParam("unused", func(ctx context.Context) interface{} {
+		unusedCalls++
+		return "unused"
+	})
+	v.AddCustomParam("missing", func(ctx context.Context) interface{} {
+		return nil
+	})
+
+	v.SetDefaultTagMessage("required", "{field} is required by {tenantName}{missing}")
+	v.SetDefaultTagMessage("max", "{limitPath} exceeded for {tenantName}")
+
+	upload := Upload{Size: 20}
+
+	err := v.ValidateCtx(context.WithValue(context.Background(), tenantKey{}, "Acme"), upload)
+	errs, ok := err.(ValidationErrors)
+	assert.True(t, ok, "Should be of type ValidationErrors")
+	assert.Equal(t, "name is required by Acme", errs.ErrorsForPath("name")[0].Message)
+	assert.Equal(t, "size@10 exceeded for Acme", errs.ErrorsForPath("size")[0].Message)
+
+	err = v.ValidateCtx(context.WithValue(context.Background(), tenantKey{}, "Globex"), upload)
+	errs = err.(ValidationErrors)
+	assert.Equal(t, "name is required by Globex", errs.ErrorsForPath("name")[0].Message)
+
+	assert.Equal(t, 0, unusedCalls, "Unused dynamic params should not be evaluated")
+
+	// Dynamic params are copied with the other custom params
+	newV := v.UseMessages(NewValidationMessages())
+	err = newV.ValidateCtx(context.WithValue(context.Background(), tenantKey{}, "Initech"), upload)
+	errs = err.(ValidationErrors)
+	assert.Equal(t, "name is required by Initech", errs.ErrorsForPath("name")[0].Message)
+}
+
+func TestMessageFuncs(t *testing.T) {
+	type Shirt struct {
+		Size  string `json:"size" validate:"oneof=S M L"`
+		Color string `json:"color" validate:"required"`
+		Price int    `json:"price" validate:"min=1"`
+		Notes string `json:"notes" validate:"max=3"`
+	}
+
+	shirt := Shirt{Size: "XXL", Notes: "too long"}
+
+	oneof := func(ctx context.Context, e ValidationError) string {
+		return fmt.Sprintf("%s must be one of: %s", e.Field, strings.Join(strings.Fields(e.Param), ", "))
+	}
+
+	t.Run("Tag message func", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessageFunc("oneof", oneof)
+
+		errs := v.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "size must be one of: S, M, L", errs.ErrorsForPath("size")[0].Message)
+	})
+
+	t.Run("Path message funcs take precedence over tag messages", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessage("required", "{field} is required")
+		v.SetConstraintMessageFunc("color", "required", func(ctx context.Context, e ValidationError) string {
+			return "Pick a color"
+		})
+		v.SetPathDefaultMessageFunc("price", func(ctx context.Context, e ValidationError) string {
+			return fmt.Sprintf("Price %v is not valid (%s)", e.Actual, e.Constraint)
+		})
+
+		errs := v.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "Pick a color", errs.ErrorsForPath("color")[0].Message)
+		assert.Equal(t, "Price 0 is not valid (min)", errs.ErrorsForPath("price")[0].Message)
+	})
+
+	t.Run("Global message func", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		v.SetDefaultMessageFunc(func(ctx context.Context, e ValidationError) string {
+			return e.Path + " failed " + e.Constraint
+		})
+
+		errs := v.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "notes failed max", errs.ErrorsForPath("notes")[0].Message)
+
+		// Setting a string message replaces the function
+		v.SetDefaultMessage("Invalid")
+		errs = v.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "Invalid", errs.ErrorsForPath("notes")[0].Message)
+	})
+
+	t.Run("Latest registration wins at the same level", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessageFunc("oneof", oneof)
+		v.SetDefaultTagMessage("oneof", "Invalid size")
+
+		errs := v.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "Invalid size", errs.ErrorsForPath("size")[0].Message)
+
+		v.SetConstraintMessage("size", "oneof", "Bad size")
+		v.SetConstraintMessageFunc("size", "oneof", oneof)
+		errs = v.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "size must be one of: S, M, L", errs.ErrorsForPath("size")[0].Message)
+	})
+
+	t.Run("Struct tag messages take precedence", func(t *testing.T) {
+		type Item struct {
+			Name string `json:"name" validate:"required" errmsg:"Name please"`
+		}
+
+		v := New().UseJsonTagName()
+		v.SetConstraintMessageFunc("name", "required", func(ctx context.Context, e ValidationError) string {
+			return "from func"
+		})
+
+		errs := v.Validate(Item{}).(ValidationErrors)
+		assert.Equal(t, "Name please", errs[0].Message)
+	})
+
+	t.Run("Message funcs receive the context", func(t *testing.T) {
+		type langKey struct{}
+
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessageFunc("required", func(ctx context.Context, e ValidationError) string {
+			if ctx.Value(langKey{}) == "fr" {
+				return e.Field + " est obligatoire"
+			}
+			return e.Field + " is required"
+		})
+
+		err := v.ValidateCtx(context.WithValue(context.Background(), langKey{}, "fr"), shirt)
+		errs := err.(ValidationErrors)
+		assert.Equal(t, "color est obligatoire", errs.ErrorsForPath("color")[0].Message)
+	})
+
+	t.Run("UseMessages keeps tag message funcs", func(t *testing.T) {
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessageFunc("oneof", oneof)
+
+		newV := v.UseMessages(NewValidationMessages())
+		errs := newV.Validate(shirt).(ValidationErrors)
+		assert.Equal(t, "size must be one of: S, M, L", errs.ErrorsForPath("size")[0].Message)
+	})
+}
+
+func TestCompleteError(t *testing.T) {
+	v := New()
+	v.SetDefaultTagMessage("password.digit", "{field} needs a number")
+	v.SetConstraintMessage("login.password", "password", "Bad login password")
+	v.SetDefaultTagCode("password", "ERR_PASSWORD")
+
+	e := v.CompleteError(context.Background(), ValidationError{
+		Field:      "password",
+		Path:       "password",
+		Constraint: "password",
+		Reason:     "digit",
+	}, "fallback")
+	assert.Equal(t, "password needs a number", e.Message)
+	assert.Equal(t, "ERR_PASSWORD", e.Code)
+
+	e = v.CompleteError(context.Background(), ValidationError{Field: "password", Path: "login.password", Constraint: "password", Reason: "digit"}, "fallback")
+	assert.Equal(t, "Bad login password", e.Message)
+
+	e = v.CompleteError(context.Background(), ValidationError{
+		Field:      "password",
+		Path:       "password",
+		Constraint: "password",
+		Reason:     "min_length",
+		Params:     map[string]interface{}{"min": 8},
+	}, "At least {min} characters")
+	assert.Equal(t, "At least 8 characters", e.Message)
+}
+
+func TestExtension(t *testing.T) {
+	type counterKey struct{}
+
+	v := New()
+	assert.Nil(t, v.Extension(counterKey{}, nil))
+
+	counter := v.Extension(counterKey{}, func() interface{} { return new(int) }).(*int)
+	*counter = 3
+	assert.Equal(t, 3, *v.Extension(counterKey{}, func() interface{} { return new(int) }).(*int))
+
+	// Values are shared with UseMessages, but not with other validators
+	assert.Same(t, counter, v.UseMessages(NewValidationMessages()).Extension(counterKey{}, nil))
+	assert.Nil(t, New().Extension(counterKey{}, nil))
+}
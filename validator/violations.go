@@ -0,0 +1,262 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	govalidator "github.com/go-playground/validator/v10"
+)
+
+// Violation describes one reason why a field failed a validation registered with
+// RegisterViolationValidation. Each violation is reported as its own ValidationError.
+type Violation struct {
+	Reason     string                 // Machine-readable reason within the constraint (e.g., "digit")
+	Message    string                 // Default message template used when no other message is configured
+	Params     map[string]interface{} // Extra parameters available in messages as {name}
+	Constraint string                 // Constraint of the error instead of the tag, when set (e.g., "phone_length")
+}
+
+// ViolationFunc validates a field and returns every violation found.
+// An empty result means the field is valid.
+type ViolationFunc func(ctx context.Context, fl govalidator.FieldLevel) []Violation
+
+// RegisterViolationValidation registers a custom validation with the given tag that can report
+// several failure reasons at once, instead of a single bool.
+//
+// Each Violation returned by fn becomes its own ValidationError with the tag as Constraint and the
+// violation's Reason and Params. Messages and codes for a specific reason can be set using the
+// "{tag}.{reason}" key, which takes precedence over the plain tag at every level of the message
+// resolution order:
+//
+//	v.RegisterViolationValidation("username", checkUsername)
+//	v.SetDefaultTagMessage("username.too_short", "{field} must have at least {min} characters")
+//	v.SetDefaultTagMessage("username", "{field} is not a valid username")
+//
+// A violation with a Constraint is reported with that constraint instead of the tag, for validations whose
+// failures are distinct constraints, such as "phone_country" and "phone_length". Its keys are then
+// "{constraint}.{reason}" and "{constraint}".
+//
+// When the tag is part of an OR group, e.g. `validate:"username|email"`, and every tag of the group fails,
+// the violations of the tag are reported instead of a single error for the group.
+//
+// Reasons are only collected by Validate and ValidateCtx. When the underlying go-playground validator
+// is used directly, the tag behaves like a regular validation that fails if any violation is found.
+func (v *Validator) RegisterViolationValidation(tag string, fn ViolationFunc, callValidationEvenIfNull ...bool) error {
+	return v.BaseValidator.RegisterValidationCtx(tag, func(ctx context.Context, fl govalidator.FieldLevel) bool {
+		violations := fn(ctx, fl)
+		if len(violations) == 0 {
+			return true
+		}
+
+		if collector := violationCollectorFromContext(ctx); collector != nil {
+			record := newViolationRecord(tag, fl)
+			record.violations = violations
+			collector.add(record)
+		}
+
+		return false
+	}, callValidationEvenIfNull...)
+}
+
+// violationRecord holds the violations reported for one failing field, or the deferred validation of a field
+type violationRecord struct {
+	tag        string
+	param      string
+	field      string
+	parent     reflect.Value // Struct holding the field, used to tell apart fields with the same name
+	violations []Violation
+	deferred   DeferredFunc // Validates the field after StructCtx, see DeferValidation
+	async      AsyncFunc    // Validates the field concurrently after StructCtx, see RunAsync
+	value      interface{}
+}
+
+// pending reports whether the result of the record is only known once StructCtx returns
+func (r violationRecord) pending() bool {
+	return r.deferred != nil || r.async != nil
+}
+
+// violationCollector gathers the violations reported during a single ValidateCtx call.
+// go-playground's field errors do not carry custom data, so records are matched back to
+// the field errors by tag, param, field name and the struct holding the field, in the order
+// they were reported.
+type violationCollector struct {
+	mu      sync.Mutex
+	records []violationRecord
+	next    int   // Records before next were matched or can no longer be matched
+	err     error // First error reported with AbortValidation
+}
+
+type violationCollectorKey struct{}
+
+// withViolationCollector returns a copy of ctx carrying a new violation collector
+func withViolationCollector(ctx context.Context) (context.Context, *violationCollector) {
+	collector := &violationCollector{}
+	return context.WithValue(ctx, violationCollectorKey{}, collector), collector
+}
+
+// violationCollectorFromContext returns the collector stored in ctx, or nil if there is none
+func violationCollectorFromContext(ctx context.Context) *violationCollector {
+	if ctx == nil {
+		return nil
+	}
+	collector, _ := ctx.Value(violationCollectorKey{}).(*violationCollector)
+	return collector
+}
+
+// AbortValidation makes ValidateCtx return err instead of ValidationErrors. It is meant for validations
+// that find a problem with the validator itself while validating a field, such as a tag referencing
+// something that was never registered, which is a bug in the code rather than invalid input:
+//
+//	v.BaseValidator.RegisterValidationCtx("currency_of", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+//	    rates, ok := tables[fl.Param()]
+//	    if !ok {
+//	        return validator.AbortValidation(ctx, fmt.Errorf("unknown rate table %q", fl.Param()))
+//	    }
+//	    ...
+//	})
+//
+// Only the first error of a call is returned. It always returns false, so the field is invalid when the
+// underlying go-playground validator is used directly.
+func AbortValidation(ctx context.Context, err error) bool {
+	if collector := violationCollectorFromContext(ctx); collector != nil {
+		collector.abort(err)
+	}
+	return false
+}
+
+// abort records the error returned by ValidateCtx, keeping the first one
+func (c *violationCollector) abort(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// aborted returns the error reported with AbortValidation, if any
+func (c *violationCollector) aborted() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// newViolationRecord returns a record for the field of fl
+func newViolationRecord(tag string, fl govalidator.FieldLevel) violationRecord {
+	var value interface{}
+	if fl.Field().IsValid() && fl.Field().CanInterface() {
+		value = fl.Field().Interface()
+	}
+	return violationRecord{tag: tag, param: fl.Param(), field: fl.FieldName(), parent: fl.Parent(), value: value}
+}
+
+// add records the violations, deferred validation or async validation of a failing field
+func (c *violationCollector) add(record violationRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, record)
+}
+
+// take removes and returns the records of the field error fe. top is the value given to ValidateCtx.
+//
+// go-playground reports the error of a field right after running its tags, so the records of fe are
+// the first ones matching it. Records reported before them were left by tags of OR groups that
+// eventually passed, e.g. "password|eq=legacy", and are dropped. When the tags of an OR group all
+// fail, fe has the tags of the whole group and gets the records of every tag of the group.
+func (c *violationCollector) take(fe govalidator.FieldError, top reflect.Value) []violationRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parent, parentFound := fieldParent(top, fe)
+	matches := func(r violationRecord) bool {
+		if r.field != fe.Field() || !r.reportedBy(fe.ActualTag(), fe.Param()) {
+			return false
+		}
+		if parentFound && parent.CanAddr() && r.parent.IsValid() && r.parent.CanAddr() {
+			return parent.Type() == r.parent.Type() && parent.UnsafeAddr() == r.parent.UnsafeAddr()
+		}
+		// Without addresses, e.g. for structs in maps, the values of the fields tell them apart
+		return reflect.DeepEqual(r.value, fe.Value())
+	}
+
+	for i := c.next; i < len(c.records); i++ {
+		if !matches(c.records[i]) {
+			continue
+		}
+
+		taken := []violationRecord{c.records[i]}
+		end := i + 1
+		if strings.Contains(fe.ActualTag(), "|") {
+			for end < len(c.records) && matches(c.records[end]) && !hasRecordTag(taken, c.records[end]) {
+				taken = append(taken, c.records[end])
+				end++
+			}
+		}
+		c.next = end
+		return taken
+	}
+
+	return nil
+}
+
+// reportedBy reports whether the record comes from the tag of a field error, or from one of the tags
+// of its OR group
+func (r violationRecord) reportedBy(tag, param string) bool {
+	if !strings.Contains(tag, "|") {
+		return r.tag == tag && r.param == param
+	}
+
+	name := r.tag
+	if r.param != "" {
+		name += "=" + r.param
+	}
+	return strings.Contains("|"+tag+"|", "|"+name+"|")
+}
+
+// hasPendingRecord reports whether one of records is a deferred or async validation
+func hasPendingRecord(records []violationRecord) bool {
+	for _, record := range records {
+		if record.pending() {
+			return true
+		}
+	}
+	return false
+}
+
+// violationErrors returns an error for each violation of a record. e is the error reported by go-playground
+// for the field of the record, which has the tags of the whole group for tags of an OR group.
+func (v *Validator) violationErrors(ctx context.Context, e ValidationError, record violationRecord, violations []Violation, field reflect.StructField, found bool, normPath string) ValidationErrors {
+	errs := make(ValidationErrors, 0, len(violations))
+	for _, violation := range violations {
+		violationError := e
+		violationError.Constraint = record.tag
+		violationError.Param = record.param
+		violationError.Reason = violation.Reason
+		violationError.Params = violation.Params
+		if violation.Constraint != "" {
+			violationError.Constraint = violation.Constraint
+		}
+		errs = append(errs, v.completeError(ctx, violationError, field, found, normPath, violation.Message))
+	}
+	return errs
+}
+
+// hasRecordTag reports whether records already holds a record of the tag and param of r
+func hasRecordTag(records []violationRecord, r violationRecord) bool {
+	for _, record := range records {
+		if record.tag == r.tag && record.param == r.param {
+			return true
+		}
+	}
+	return false
+}
+
+// constraintKeys returns the keys used to look up messages and codes for e, from the most to the
+// least specific: "{constraint}.{reason}" when the error has a reason, then "{constraint}".
+func constraintKeys(e ValidationError) []string {
+	if e.Reason == "" {
+		return []string{e.Constraint}
+	}
+	return []string{e.Constraint + "." + e.Reason, e.Constraint}
+}
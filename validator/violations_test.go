@@ -0,0 +1,214 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	govalidator "github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+// checkUsername is a ViolationFunc used in tests that reports every problem of a username
+func checkUsername(ctx context.Context, fl govalidator.FieldLevel) []Violation {
+	username := fl.Field().String()
+
+	var violations []Violation
+	if len(username) < 5 {
+		violations = append(violations, Violation{
+			Reason:  "too_short",
+			Message: "{field} must have at least {min} characters",
+			Params:  map[string]interface{}{"min": 5},
+		})
+	}
+	if strings.Contains(username, " ") {
+		violations = append(violations, Violation{
+			Reason:  "spaces",
+			Message: "{field} cannot contain spaces",
+		})
+	}
+	return violations
+}
+
+func TestRegisterViolationValidation(t *testing.T) {
+	type Account struct {
+		Username string `json:"username" validate:"required,username"`
+	}
+
+	newValidator := func() *Validator {
+		v := New().UseJsonTagName()
+		err := v.RegisterViolationValidation("username", checkUsername)
+		assert.NoError(t, err)
+		return v
+	}
+
+	t.Run("Valid value", func(t *testing.T) {
+		v := newValidator()
+		assert.NoError(t, v.Validate(Account{Username: "johndoe"}))
+	})
+
+	t.Run("Each violation is its own error", func(t *testing.T) {
+		v := newValidator()
+
+		errs := v.Validate(Account{Username: "j d"}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+
+		assert.Equal(t, "username", errs[0].Path)
+		assert.Equal(t, "username", errs[0].Constraint)
+		assert.Equal(t, "too_short", errs[0].Reason)
+		assert.Equal(t, map[string]interface{}{"min": 5}, errs[0].Params)
+		assert.Equal(t, "username must have at least 5 characters", errs[0].Message)
+
+		assert.Equal(t, "spaces", errs[1].Reason)
+		assert.Equal(t, "username cannot contain spaces", errs[1].Message)
+	})
+
+	t.Run("Reason specific messages and codes", func(t *testing.T) {
+		v := newValidator()
+		v.SetDefaultTagMessage("username.spaces", "No spaces in {field}")
+		v.SetDefaultTagMessage("username", "Bad {field} (min {min})")
+		v.SetDefaultTagCode("username.spaces", "ERR_USERNAME_SPACES")
+		v.SetDefaultTagCode("username", "ERR_USERNAME")
+
+		errs := v.Validate(Account{Username: "j d"}).(ValidationErrors)
+		assert.Equal(t, "Bad username (min 5)", errs[0].Message)
+		assert.Equal(t, "ERR_USERNAME", errs[0].Code)
+		assert.Equal(t, "No spaces in username", errs[1].Message)
+		assert.Equal(t, "ERR_USERNAME_SPACES", errs[1].Code)
+	})
+
+	t.Run("Path messages take precedence over tag messages", func(t *testing.T) {
+		v := newValidator()
+		v.SetDefaultTagMessage("username.too_short", "tag message")
+		v.SetConstraintMessage("username", "username", "path message")
+
+		errs := v.Validate(Account{Username: "jd"}).(ValidationErrors)
+		assert.Equal(t, "path message", errs[0].Message)
+
+		v.SetConstraintMessage("username", "username.too_short", "path reason message")
+		errs = v.Validate(Account{Username: "jd"}).(ValidationErrors)
+		assert.Equal(t, "path reason message", errs[0].Message)
+	})
+
+	t.Run("Struct tag messages for reasons", func(t *testing.T) {
+		type Profile struct {
+			Handle string `json:"handle" validate:"username" errmsg-username.spaces:"Handle cannot have spaces" errmsg:"Handle is invalid"`
+		}
+
+		v := newValidator()
+		errs := v.Validate(Profile{Handle: "j d"}).(ValidationErrors)
+		assert.Equal(t, "Handle is invalid", errs[0].Message)
+		assert.Equal(t, "Handle cannot have spaces", errs[1].Message)
+	})
+
+	t.Run("Violations inside slices", func(t *testing.T) {
+		type Team struct {
+			Members []string `json:"members" validate:"dive,username"`
+		}
+
+		v := newValidator()
+		errs := v.Validate(Team{Members: []string{"johndoe", "a b", "jane"}}).(ValidationErrors)
+		assert.Len(t, errs, 3)
+		assert.Equal(t, "members[1]", errs[0].Path)
+		assert.Equal(t, "too_short", errs[0].Reason)
+		assert.Equal(t, "members[1]", errs[1].Path)
+		assert.Equal(t, "spaces", errs[1].Reason)
+		assert.Equal(t, "members[2]", errs[2].Path)
+		assert.Equal(t, "too_short", errs[2].Reason)
+	})
+
+	t.Run("OR groups", func(t *testing.T) {
+		type Member struct {
+			Username string `json:"username" validate:"username|eq=legacy"`
+		}
+		type Team struct {
+			Members []Member `json:"members" validate:"dive"`
+		}
+
+		v := newValidator()
+		assert.NoError(t, v.Validate(Team{Members: []Member{{Username: "legacy"}, {Username: "johndoe"}}}))
+
+		// Every tag of the group fails, the violations of username are reported
+		errs := v.Validate(Team{Members: []Member{{Username: "legacy"}, {Username: "a b"}}}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "members[1].username", errs[0].Path)
+		assert.Equal(t, "username", errs[0].Constraint)
+		assert.Equal(t, "", errs[0].Param)
+		assert.Equal(t, "too_short", errs[0].Reason)
+		assert.Equal(t, "username must have at least 5 characters", errs[0].Message)
+		assert.Equal(t, "spaces", errs[1].Reason)
+	})
+
+	t.Run("Slices passing then failing", func(t *testing.T) {
+		type Member struct {
+			Username string `json:"username" validate:"username|eq=legacy"`
+		}
+		type Team struct {
+			Members []Member          `json:"members" validate:"dive"`
+			ByRole  map[string]Member `json:"by_role" validate:"dive"`
+		}
+
+		// The records of members[0] and members[2], which pass with eq, are not used for the other members
+		v := newValidator()
+		errs := v.Validate(&Team{Members: []Member{{Username: "legacy"}, {Username: "abcdefg h"}, {Username: "legacy"}, {Username: "ab"}}}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "members[1].username", errs[0].Path)
+		assert.Equal(t, "spaces", errs[0].Reason)
+		assert.Equal(t, "abcdefg h", errs[0].Actual)
+		assert.Equal(t, "members[3].username", errs[1].Path)
+		assert.Equal(t, "too_short", errs[1].Reason)
+
+		// Structs in maps are not addressable and are told apart by their values
+		errs = v.Validate(Team{ByRole: map[string]Member{"owner": {Username: "legacy"}, "admin": {Username: "a b"}}}).(ValidationErrors)
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "by_role[admin].username", errs[0].Path)
+		assert.Equal(t, "too_short", errs[0].Reason)
+		assert.Equal(t, "spaces", errs[1].Reason)
+	})
+
+	t.Run("Constraint per violation", func(t *testing.T) {
+		type Order struct {
+			Quantity int `json:"quantity" validate:"quantity"`
+		}
+
+		v := New().UseJsonTagName()
+		v.SetDefaultTagMessage("quantity_max", "{field} is above {max}")
+		err := v.RegisterViolationValidation("quantity", func(ctx context.Context, fl govalidator.FieldLevel) []Violation {
+			return []Violation{{Reason: "max", Constraint: "quantity_max", Params: map[string]interface{}{"max": 10}}}
+		})
+		assert.NoError(t, err)
+
+		errs := v.Validate(Order{Quantity: 11}).(ValidationErrors)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "quantity_max", errs[0].Constraint)
+		assert.Equal(t, "max", errs[0].Reason)
+		assert.Equal(t, "quantity is above 10", errs[0].Message)
+	})
+
+	t.Run("Base validator without reasons", func(t *testing.T) {
+		v := newValidator()
+		err := v.BaseValidator.Struct(Account{Username: "jd"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAbortValidation(t *testing.T) {
+	type Payment struct {
+		Amount   int    `validate:"min=1"`
+		Currency string `validate:"currency_of=unknown"`
+	}
+
+	v := New()
+	assert.NoError(t, v.BaseValidator.RegisterValidationCtx("currency_of", func(ctx context.Context, fl govalidator.FieldLevel) bool {
+		return AbortValidation(ctx, fmt.Errorf("unknown rate table %q", fl.Param()))
+	}))
+
+	// The error is returned instead of the errors of the other fields
+	err := v.Validate(Payment{Amount: 0, Currency: "CAD"})
+	assert.EqualError(t, err, `unknown rate table "unknown"`)
+	_, isValidationErrors := err.(ValidationErrors)
+	assert.False(t, isValidationErrors)
+
+	assert.Error(t, v.BaseValidator.Struct(Payment{Amount: 1, Currency: "CAD"}))
+}